@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"github.com/jessevdk/go-flags"
+	"github.com/koestler/go-mqtt-to-influx/v2/config"
+	LocalDb "github.com/koestler/go-mqtt-to-influx/v2/localDb"
+	"os"
+	"time"
+)
+
+type CheckCommand struct {
+	Topic      string         `long:"topic" description:"Topic of a sample message to run through all converters with a matching topic"`
+	Payload    flags.Filename `long:"payload" description:"File containing the payload of the sample message"`
+	MqttClient string         `long:"mqtt-client" description:"Name of the mqtt client the sample message is received by, default all"`
+}
+
+// runCheck validates the configuration, lists the subscribe topics of all converters and mqtt clients and,
+// when a sample message is given, prints the line protocol each matching converter would write.
+// Everything is written to stdout; lines not being line protocol start with #.
+func runCheck(cmdOptions CmdOptions, cmdName string) int {
+	cmd := cmdOptions.Check
+	if (cmd.Topic == "") != (cmd.Payload == "") {
+		fmt.Println("# --topic and --payload must be given together")
+		return ExitDueToCmdOptions
+	}
+
+	cfg, errs := config.ReadConfigFile(cmdName, string(cmdOptions.Config))
+	if len(errs) > 0 {
+		for _, e := range errs {
+			fmt.Printf("# config: error: %v\n", e)
+		}
+		fmt.Printf("# config: %d errors found in '%s'\n", len(errs), cmdOptions.Config)
+		return ExitDueToConfig
+	}
+	fmt.Printf("# config: '%s' is valid\n", cmdOptions.Config)
+
+	var payload []byte
+	if cmd.Topic != "" {
+		var err error
+		if payload, err = os.ReadFile(string(cmd.Payload)); err != nil {
+			fmt.Printf("# cannot read payload: %s\n", err)
+			return ExitDueToCmdOptions
+		}
+	}
+
+	output := newReplayOutput(&cfg, true, os.Stdout, &LocalDb.DisabledLocalDb{})
+	exitCode := ExitSuccess
+	matched := false
+	for _, cc := range cfg.Converters() {
+		r, err := newReplayer(&cfg, cc.Name(), nil, output)
+		if err != nil {
+			fmt.Printf("# converter[%s]: error: %s\n", cc.Name(), err)
+			exitCode = ExitDueToConfig
+			continue
+		}
+
+		fmt.Printf("# converter[%s]: Implementation='%s'\n", cc.Name(), cc.Implementation())
+		topicMatches := false
+		for _, tm := range r.topicMatchers {
+			fmt.Printf("#   mqttClient[%s]: SubscribeTopic='%s'\n", tm.mqttClient, tm.matcher.GetSubscribeTopic())
+			if cmd.MqttClient != "" && tm.mqttClient != cmd.MqttClient {
+				continue
+			}
+			if _, err := tm.matcher.MatchDevice(cmd.Topic); err == nil {
+				topicMatches = true
+			}
+		}
+
+		if cmd.Topic == "" || !topicMatches {
+			continue
+		}
+
+		influxClients := cc.InfluxClients()
+		if len(influxClients) < 1 {
+			for _, icc := range cfg.InfluxClients() {
+				influxClients = append(influxClients, icc.Name())
+			}
+		}
+
+		matched = true
+		fmt.Printf("#   topic='%s' matches; InfluxClients=%v; output:\n", cmd.Topic, influxClients)
+		if err := r.handle(cmd.MqttClient, replayInput{topic: cmd.Topic, payload: payload, receivedAt: time.Now()}); err != nil {
+			fmt.Printf("#   error: %s\n", err)
+			exitCode = ExitDueToCheck
+		}
+	}
+
+	if cmd.Topic != "" && !matched {
+		fmt.Printf("# topic='%s' does not match any converter\n", cmd.Topic)
+		exitCode = ExitDueToCheck
+	}
+
+	return exitCode
+}
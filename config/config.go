@@ -1,6 +1,8 @@
 package config
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"github.com/google/uuid"
 	"golang.org/x/exp/maps"
@@ -18,6 +20,11 @@ const NameRegexp = "^[a-zA-Z0-9\\-]{1,32}$"
 
 var nameMatcher = regexp.MustCompile(NameRegexp)
 
+// JsonPathRegexp matches dotted paths like a.b.0.c as well as simple JSONPath expressions like $.a.b[0].c
+const JsonPathRegexp = `^(\$\.?)?[^.\[\]$]+(\.[^.\[\]]+|\[[0-9]+\])*$`
+
+var jsonPathMatcher = regexp.MustCompile(JsonPathRegexp)
+
 func ReadConfigFile(exe, source string) (config Config, err []error) {
 	yamlStr, e := os.ReadFile(source)
 	if e != nil {
@@ -155,6 +162,8 @@ func (c *localDbConfigRead) TransformAndValidate() (ret LocalDbConfig, err []err
 	// default values
 	ret.enabled = false
 	ret.path = "./go-mqtt-to-influx.db"
+	ret.deadLetterMaxCount = 10000
+	ret.deadLetterMaxAge = 7 * 24 * time.Hour
 
 	if c == nil {
 		return
@@ -166,6 +175,28 @@ func (c *localDbConfigRead) TransformAndValidate() (ret LocalDbConfig, err []err
 		ret.path = *c.Path
 	}
 
+	if c.DeadLetterMaxCount != nil {
+		if *c.DeadLetterMaxCount < 0 {
+			err = append(err, fmt.Errorf("LocalDb->DeadLetterMaxCount=%d must not be negative", *c.DeadLetterMaxCount))
+		} else {
+			ret.deadLetterMaxCount = *c.DeadLetterMaxCount
+		}
+	}
+
+	if len(c.DeadLetterMaxAge) < 1 {
+		// use default 168h
+	} else if deadLetterMaxAge, e := time.ParseDuration(c.DeadLetterMaxAge); e != nil {
+		err = append(err, fmt.Errorf("LocalDb->DeadLetterMaxAge='%s' parse error: %s",
+			c.DeadLetterMaxAge, e,
+		))
+	} else if deadLetterMaxAge <= 0 {
+		err = append(err, fmt.Errorf("LocalDb->DeadLetterMaxAge='%s' must be >0",
+			c.DeadLetterMaxAge,
+		))
+	} else {
+		ret.deadLetterMaxAge = deadLetterMaxAge
+	}
+
 	return
 }
 
@@ -246,6 +277,7 @@ func (c mqttClientConfigRead) TransformAndValidate(name string) (ret MqttClientC
 
 	if c.ClientId == nil {
 		ret.clientId = "go-mqtt-to-influx-" + uuid.New().String()
+		ret.clientIdGenerated = true
 	} else {
 		ret.clientId = *c.ClientId
 	}
@@ -307,6 +339,31 @@ func (c mqttClientConfigRead) TransformAndValidate(name string) (ret MqttClientC
 		ret.connectTimeout = connectTimeout
 	}
 
+	if c.Tls != nil {
+		var e []error
+		ret.tls, e = c.Tls.TransformAndValidate(name)
+		err = append(err, e...)
+
+		if ret.broker != nil && !isTlsScheme(ret.broker.Scheme) {
+			err = append(err, fmt.Errorf(
+				"MqttClientConfig->%s->Tls is configured but Broker scheme '%s' does not use tls, use ssl, tls, mqtts or wss",
+				name, ret.broker.Scheme,
+			))
+		}
+	}
+
+	recorder, e := c.Recorder.TransformAndValidate(name)
+	ret.recorder = recorder
+	err = append(err, e...)
+
+	ret.periodicPublishes, e = TransformAndValidateList(
+		c.PeriodicPublishes,
+		func(inp periodicPublishConfigRead) (PeriodicPublishConfig, []error) {
+			return inp.TransformAndValidate(name)
+		},
+	)
+	err = append(err, e...)
+
 	if c.AvailabilityTopic == nil {
 		// use default
 		ret.availabilityTopic = "%Prefix%tele/%ClientId%/status"
@@ -325,33 +382,222 @@ func (c mqttClientConfigRead) TransformAndValidate(name string) (ret MqttClientC
 	return
 }
 
+func (c mqttTlsConfigRead) TransformAndValidate(name string) (ret MqttTlsConfig, err []error) {
+	ret = MqttTlsConfig{
+		enabled:    true,
+		caFile:     c.CaFile,
+		certFile:   c.CertFile,
+		keyFile:    c.KeyFile,
+		serverName: c.ServerName,
+	}
+
+	if c.InsecureSkipVerify != nil && *c.InsecureSkipVerify {
+		ret.insecureSkipVerify = true
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         ret.serverName,
+		InsecureSkipVerify: ret.insecureSkipVerify,
+	}
+
+	if len(ret.caFile) > 0 {
+		if pem, e := os.ReadFile(ret.caFile); e != nil {
+			err = append(err, fmt.Errorf("MqttClientConfig->%s->Tls->CaFile='%s' cannot read: %s", name, ret.caFile, e))
+		} else {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				err = append(err, fmt.Errorf("MqttClientConfig->%s->Tls->CaFile='%s' does not contain any PEM encoded certificate", name, ret.caFile))
+			}
+			tlsConfig.RootCAs = pool
+		}
+	}
+
+	if len(ret.certFile) > 0 && len(ret.keyFile) < 1 {
+		err = append(err, fmt.Errorf("MqttClientConfig->%s->Tls->KeyFile must be set when CertFile is set", name))
+	} else if len(ret.certFile) < 1 && len(ret.keyFile) > 0 {
+		err = append(err, fmt.Errorf("MqttClientConfig->%s->Tls->CertFile must be set when KeyFile is set", name))
+	} else if len(ret.certFile) > 0 {
+		if cert, e := tls.LoadX509KeyPair(ret.certFile, ret.keyFile); e != nil {
+			err = append(err, fmt.Errorf("MqttClientConfig->%s->Tls->CertFile='%s' / KeyFile='%s' cannot load key pair: %s",
+				name, ret.certFile, ret.keyFile, e,
+			))
+		} else {
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+	}
+
+	ret.tlsConfig = tlsConfig
+
+	return
+}
+
+func (c periodicPublishConfigRead) TransformAndValidate(name string) (ret PeriodicPublishConfig, err []error) {
+	ret = PeriodicPublishConfig{
+		topic:   c.Topic,
+		payload: c.Payload,
+	}
+
+	if len(c.Topic) < 1 {
+		err = append(err, fmt.Errorf("MqttClientConfig->%s->PeriodicPublishes->Topic must not be empty", name))
+	} else if strings.ContainsAny(c.Topic, "+#") {
+		err = append(err, fmt.Errorf("MqttClientConfig->%s->PeriodicPublishes->Topic='%s' must not contain wildcards",
+			name, c.Topic,
+		))
+	}
+
+	if len(c.Interval) < 1 {
+		// use default 30s
+		ret.interval = 30 * time.Second
+	} else if interval, e := time.ParseDuration(c.Interval); e != nil {
+		err = append(err, fmt.Errorf("MqttClientConfig->%s->PeriodicPublishes->Interval='%s' parse error: %s",
+			name, c.Interval, e,
+		))
+	} else if interval < time.Second {
+		err = append(err, fmt.Errorf("MqttClientConfig->%s->PeriodicPublishes->Interval='%s' must be >=1s",
+			name, c.Interval,
+		))
+	} else {
+		ret.interval = interval
+	}
+
+	return
+}
+
+func (c *recorderConfigRead) TransformAndValidate(name string) (ret RecorderConfig, err []error) {
+	// default values
+	ret = RecorderConfig{
+		enabled:     false,
+		topics:      []string{"#"},
+		directory:   "./records",
+		maxFileSize: 100000000,
+		maxFileAge:  time.Hour,
+		maxAge:      7 * 24 * time.Hour,
+	}
+
+	if c == nil {
+		return
+	}
+
+	ret.enabled = true
+
+	if len(c.Topics) > 0 {
+		ret.topics = c.Topics
+	}
+
+	if len(c.Directory) > 0 {
+		ret.directory = c.Directory
+	}
+
+	if c.MaxFileSize != nil {
+		if *c.MaxFileSize <= 0 {
+			err = append(err, fmt.Errorf("MqttClientConfig->%s->Recorder->MaxFileSize=%d must be >0", name, *c.MaxFileSize))
+		} else {
+			ret.maxFileSize = *c.MaxFileSize
+		}
+	}
+
+	if len(c.MaxFileAge) < 1 {
+		// use default 1h
+	} else if maxFileAge, e := time.ParseDuration(c.MaxFileAge); e != nil {
+		err = append(err, fmt.Errorf("MqttClientConfig->%s->Recorder->MaxFileAge='%s' parse error: %s",
+			name, c.MaxFileAge, e,
+		))
+	} else if maxFileAge < time.Second {
+		err = append(err, fmt.Errorf("MqttClientConfig->%s->Recorder->MaxFileAge='%s' must be >=1s",
+			name, c.MaxFileAge,
+		))
+	} else {
+		ret.maxFileAge = maxFileAge
+	}
+
+	if len(c.MaxAge) < 1 {
+		// use default 168h
+	} else if maxAge, e := time.ParseDuration(c.MaxAge); e != nil {
+		err = append(err, fmt.Errorf("MqttClientConfig->%s->Recorder->MaxAge='%s' parse error: %s",
+			name, c.MaxAge, e,
+		))
+	} else if maxAge <= 0 {
+		err = append(err, fmt.Errorf("MqttClientConfig->%s->Recorder->MaxAge='%s' must be >0",
+			name, c.MaxAge,
+		))
+	} else {
+		ret.maxAge = maxAge
+	}
+
+	return
+}
+
+// isTlsScheme returns true for all broker url schemes for which the mqtt libraries establish a tls connection
+func isTlsScheme(scheme string) bool {
+	switch scheme {
+	case "ssl", "tls", "mqtts", "mqtt+ssl", "tcps", "wss":
+		return true
+	}
+	return false
+}
+
 func (c influxClientConfigRead) TransformAndValidate(name string) (ret InfluxClientConfig, err []error) {
 	ret = InfluxClientConfig{
-		name:   name,
-		url:    c.Url,
-		token:  c.Token,
-		org:    c.Org,
-		bucket: c.Bucket,
+		name:            name,
+		backend:         c.Backend,
+		url:             c.Url,
+		token:           c.Token,
+		org:             c.Org,
+		bucket:          c.Bucket,
+		user:            c.User,
+		password:        c.Password,
+		database:        c.Database,
+		retentionPolicy: c.RetentionPolicy,
+		path:            c.Path,
 	}
 
 	if !nameMatcher.MatchString(ret.name) {
 		err = append(err, fmt.Errorf("InfluxClientConfig->Name='%s' does not match %s", ret.name, NameRegexp))
 	}
 
-	if len(ret.url) < 1 {
-		err = append(err, fmt.Errorf("InfluxClientConfig->%s->Url must not be empty", name))
+	if len(ret.backend) < 1 {
+		// use default influxdb2
+		ret.backend = "influxdb2"
 	}
 
-	if len(ret.token) < 1 {
-		err = append(err, fmt.Errorf("InfluxClientConfig->%s->Token must not be empty", name))
-	}
+	switch ret.backend {
+	case "influxdb2":
+		if len(ret.url) < 1 {
+			err = append(err, fmt.Errorf("InfluxClientConfig->%s->Url must not be empty", name))
+		}
 
-	if len(ret.org) < 1 {
-		err = append(err, fmt.Errorf("InfluxClientConfig->%s->Org must not be empty", name))
-	}
+		if len(ret.token) < 1 {
+			err = append(err, fmt.Errorf("InfluxClientConfig->%s->Token must not be empty", name))
+		}
+
+		if len(ret.org) < 1 {
+			err = append(err, fmt.Errorf("InfluxClientConfig->%s->Org must not be empty", name))
+		}
+
+		if len(ret.bucket) < 1 {
+			err = append(err, fmt.Errorf("InfluxClientConfig->%s->Bucket must not be empty", name))
+		}
+	case "influxdb1":
+		if len(ret.url) < 1 {
+			err = append(err, fmt.Errorf("InfluxClientConfig->%s->Url must not be empty", name))
+		}
 
-	if len(ret.bucket) < 1 {
-		err = append(err, fmt.Errorf("InfluxClientConfig->%s->Bucket must not be empty", name))
+		if len(ret.database) < 1 {
+			err = append(err, fmt.Errorf("InfluxClientConfig->%s->Database must not be empty for Backend=influxdb1", name))
+		}
+	case "prometheus-remote-write":
+		if len(ret.url) < 1 {
+			err = append(err, fmt.Errorf("InfluxClientConfig->%s->Url must not be empty", name))
+		} else if u, e := url.ParseRequestURI(ret.url); e != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			err = append(err, fmt.Errorf("InfluxClientConfig->%s->Url='%s' must be a http or https url", name, ret.url))
+		}
+	case "line-protocol":
+		// no mandatory fields; an empty Path means stdout
+	default:
+		err = append(err, fmt.Errorf(
+			"InfluxClientConfig->%s->Backend='%s' is unknown, must be influxdb2, influxdb1, prometheus-remote-write or line-protocol",
+			name, ret.backend,
+		))
 	}
 
 	if len(c.WriteInterval) < 1 {
@@ -484,6 +730,25 @@ func (c converterConfigRead) TransformAndValidate(
 		err = append(err, fmt.Errorf("MqttTopics section must not be empty"))
 	}
 
+	ret.jsonMappings, e = TransformAndValidateList(
+		c.JsonMappings,
+		func(inp jsonMappingConfigRead) (JsonMappingConfig, []error) {
+			return inp.TransformAndValidate(name)
+		},
+	)
+	err = append(err, e...)
+	if ret.implementation == "json-mapping" && len(ret.jsonMappings) < 1 {
+		err = append(err, fmt.Errorf("Converters->%s->JsonMappings must not be empty for Implementation=json-mapping", name))
+	}
+
+	if c.ErrorHistorySize == nil {
+		ret.errorHistorySize = 10
+	} else if *c.ErrorHistorySize >= 0 {
+		ret.errorHistorySize = *c.ErrorHistorySize
+	} else {
+		err = append(err, fmt.Errorf("Converters->%s->ErrorHistorySize=%d must not be negative", name, *c.ErrorHistorySize))
+	}
+
 	// validate that all listed mqttClients exist
 	for _, clientName := range ret.mqttClients {
 		found := false
@@ -514,6 +779,22 @@ func (c converterConfigRead) TransformAndValidate(
 		}
 	}
 
+	if c.GenericFallback != nil && *c.GenericFallback {
+		ret.genericFallback = true
+	}
+
+	if len(c.Precision) < 1 {
+		ret.precision = "ns"
+	} else if _, ok := lineProtocolPrecisions[c.Precision]; ok {
+		ret.precision = c.Precision
+	} else {
+		err = append(err, fmt.Errorf("Converters->%s->Precision='%s' must be ns, us, ms or s", name, c.Precision))
+	}
+
+	if c.AddDeviceTag != nil && *c.AddDeviceTag {
+		ret.addDeviceTag = true
+	}
+
 	if c.LogHandleOnce != nil && *c.LogHandleOnce {
 		ret.logHandleOnce = true
 	}
@@ -542,6 +823,15 @@ func (c mqttTopicConfigRead) TransformAndValidate() (ret MqttTopicConfig, err []
 		if !strings.Contains(ret.topic, "%Device%") {
 			err = append(err, fmt.Errorf("topic '%s' must contain '%%Device%%'", ret.topic))
 		}
+
+		// a multi level wildcard is only allowed at the very end of the subscribe topic
+		if strings.Contains(ret.device, "#") {
+			if ret.device != "#" && (strings.Count(ret.device, "#") > 1 || !strings.HasSuffix(ret.device, "/#")) {
+				err = append(err, fmt.Errorf("invalid device=%s, '#' is only allowed as last level", ret.device))
+			} else if !strings.HasSuffix(ret.topic, "%Device%") {
+				err = append(err, fmt.Errorf("topic '%s' must end with '%%Device%%' when device contains '#'", ret.topic))
+			}
+		}
 	} else {
 		// we have a static device name, name must not contain +
 		if strings.Contains(ret.device, "+") {
@@ -552,6 +842,53 @@ func (c mqttTopicConfigRead) TransformAndValidate() (ret MqttTopicConfig, err []
 	return
 }
 
+func (c jsonMappingConfigRead) TransformAndValidate(converterName string) (ret JsonMappingConfig, err []error) {
+	ret = JsonMappingConfig{
+		path:      c.Path,
+		field:     c.Field,
+		unit:      c.Unit,
+		valueType: c.Type,
+		sensor:    c.Sensor,
+		timePath:  c.TimePath,
+	}
+
+	if len(c.Path) < 1 {
+		err = append(err, fmt.Errorf("Converters->%s->JsonMappings->Path must not be empty", converterName))
+	} else if !jsonPathMatcher.MatchString(c.Path) {
+		err = append(err, fmt.Errorf("Converters->%s->JsonMappings->Path='%s' does not match %s",
+			converterName, c.Path, JsonPathRegexp,
+		))
+	} else if len(ret.field) < 1 {
+		// use last element of the path as default
+		parts := strings.FieldsFunc(c.Path, func(r rune) bool {
+			return r == '.' || r == '[' || r == ']' || r == '$'
+		})
+		ret.field = parts[len(parts)-1]
+	}
+
+	switch ret.valueType {
+	case "":
+		ret.valueType = "float"
+	case "float", "int", "bool", "string":
+	default:
+		err = append(err, fmt.Errorf("Converters->%s->JsonMappings->Type='%s' must be float, int, bool or string",
+			converterName, c.Type,
+		))
+	}
+
+	if len(ret.sensor) < 1 {
+		ret.sensor = converterName
+	}
+
+	if len(c.TimePath) > 0 && !jsonPathMatcher.MatchString(c.TimePath) {
+		err = append(err, fmt.Errorf("Converters->%s->JsonMappings->TimePath='%s' does not match %s",
+			converterName, c.TimePath, JsonPathRegexp,
+		))
+	}
+
+	return
+}
+
 type ApplyTopicReplaceFunc func(string) string
 
 func (c MqttTopicConfig) ApplyTopicReplace(f ApplyTopicReplaceFunc) MqttTopicConfig {
@@ -2,10 +2,21 @@ package config
 
 import (
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
 	"log"
+	"math/big"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 const (
@@ -49,6 +60,10 @@ Converters:
 	InvalidValuesConfig = `
 Version: 0
 
+LocalDb:
+  DeadLetterMaxCount: -1
+  DeadLetterMaxAge: 0s
+
 Statistics:
   Enabled: True
   HistoryResolution: 0s
@@ -74,10 +89,16 @@ Converters:
     Implementation: go-iotdevice
     MqttTopics:
       - Topic: x/%Device%
+      - Topic: y/%Device%/z
+        Device: "+/#"
+      - Topic: z/%Device%
+        Device: "#/+"
     MqttClients:
       - inexistant-mqtt-client
     InfluxClients:
       - inexistant-influx-db-client
+    ErrorHistorySize: -1
+    Precision: m
 `
 
 	ValidDefaultConfig = `
@@ -100,6 +121,119 @@ Converters:
       - Topic: piegn/tele/%Device%/SENSOR
 `
 
+	ValidJsonMappingConfig = `
+Version: 0
+MqttClients:
+  piegn-mosquitto:
+    Broker: "tcp://example.com:1883"
+
+InfluxClients:
+  piegn:
+    Url: http://172.17.0.2:8086
+    Token: "foobar-token"
+    Org: Piegn
+    Bucket: iot
+
+Converters:
+  zigbee:
+    Implementation: json-mapping
+    MqttTopics:
+      - Topic: zigbee2mqtt/%Device%
+    JsonMappings:
+      - Path: temperature
+        Unit: °C
+      - Path: $.update.state
+        Field: UpdateState
+        Type: string
+        Sensor: z2m
+        TimePath: $.time
+`
+
+	InvalidJsonMappingConfig = `
+Version: 0
+MqttClients:
+  piegn-mosquitto:
+    Broker: "tcp://example.com:1883"
+
+InfluxClients:
+  piegn:
+    Url: http://172.17.0.2:8086
+    Token: "foobar-token"
+    Org: Piegn
+    Bucket: iot
+
+Converters:
+  zigbee:
+    Implementation: json-mapping
+    MqttTopics:
+      - Topic: zigbee2mqtt/%Device%
+    JsonMappings:
+      - Path: a..b
+      - Path: temperature
+        Type: double
+      - Field: no-path
+  empty:
+    Implementation: json-mapping
+    MqttTopics:
+      - Topic: esp/%Device%
+`
+
+	ValidBackendsConfig = `
+Version: 0
+MqttClients:
+  piegn-mosquitto:
+    Broker: "tcp://example.com:1883"
+
+InfluxClients:
+  v2:
+    Url: http://172.17.0.2:8086
+    Token: "foobar-token"
+    Org: Piegn
+    Bucket: iot
+  v1:
+    Backend: influxdb1
+    Url: http://172.17.0.3:8086
+    User: bob
+    Password: secret
+    Database: iot
+    RetentionPolicy: autogen
+  prometheus:
+    Backend: prometheus-remote-write
+    Url: http://172.17.0.4:9090/api/v1/write
+  file:
+    Backend: line-protocol
+    Path: /tmp/points.lp
+
+Converters:
+  tasmota-state:
+    Implementation: tasmota-state
+    MqttTopics:
+      - Topic: piegn/tele/%Device%/STATE
+`
+
+	InvalidBackendsConfig = `
+Version: 0
+MqttClients:
+  piegn-mosquitto:
+    Broker: "tcp://example.com:1883"
+
+InfluxClients:
+  unknown:
+    Backend: graphite
+  v1:
+    Backend: influxdb1
+    Url: http://172.17.0.3:8086
+  prometheus:
+    Backend: prometheus-remote-write
+    Url: udp://172.17.0.4:9090
+
+Converters:
+  tasmota-state:
+    Implementation: tasmota-state
+    MqttTopics:
+      - Topic: piegn/tele/%Device%/STATE
+`
+
 	ValidComplexConfig = `
 Version: 0
 HttpServer:
@@ -110,6 +244,8 @@ HttpServer:
 LocalDb:
   Enabled: True
   Path: /tmp/foobar.db
+  DeadLetterMaxCount: 500
+  DeadLetterMaxAge: 24h
 
 Statistics:
   Enabled: True
@@ -175,6 +311,10 @@ Converters:
     InfluxClients:
       - 0-piegn
       - 1-local
+    ErrorHistorySize: 42
+    GenericFallback: True
+    Precision: ms
+    AddDeviceTag: True
     LogHandleOnce: True
 
   1-piegn-tasmota-availability:
@@ -316,6 +456,14 @@ func TestReadConfig_InvalidValues(t *testing.T) {
 		t.Error("expect invalid writeInterval='-1s' to be returned as error")
 	}
 
+	if !containsError("DeadLetterMaxCount", err) {
+		t.Error("expect invalid DeadLetterMaxCount=-1 to be returned as error")
+	}
+
+	if !containsError("DeadLetterMaxAge", err) {
+		t.Error("expect invalid DeadLetterMaxAge='0s' to be returned as error")
+	}
+
 	if !containsError("HistoryResolution", err) {
 		t.Error("expect invalid HistoryResolution='0s' to be returned as error")
 	}
@@ -324,6 +472,21 @@ func TestReadConfig_InvalidValues(t *testing.T) {
 		t.Error("expect invalid HistoryMaxAge='-1s' to be returned as error")
 	}
 
+	if !containsError("ErrorHistorySize", err) {
+		t.Error("expect invalid ErrorHistorySize=-1 to be returned as error")
+	}
+
+	if !containsError("Precision='m'", err) {
+		t.Error("expect invalid Precision='m' to be returned as error")
+	}
+
+	if !containsError("topic 'y/%Device%/z' must end with '%Device%'", err) {
+		t.Error("expect Device='+/#' not at the end of the topic to be returned as error")
+	}
+
+	if !containsError("invalid device=#/+", err) {
+		t.Error("expect Device='#/+' to be returned as error")
+	}
 }
 
 // check that a complex example setting all available options is correctly read
@@ -375,6 +538,16 @@ func TestReadConfig_Complex(t *testing.T) {
 			config.LocalDb().Path())
 	}
 
+	if config.LocalDb().DeadLetterMaxCount() != 500 {
+		t.Errorf("expect LocalDb->DeadLetterMaxCount to be 500, got %d",
+			config.LocalDb().DeadLetterMaxCount())
+	}
+
+	if config.LocalDb().DeadLetterMaxAge() != 24*time.Hour {
+		t.Errorf("expect LocalDb->DeadLetterMaxAge to be 24h, got %s",
+			config.LocalDb().DeadLetterMaxAge())
+	}
+
 	// Statistics
 	if !config.Statistics().Enabled() {
 		t.Error("expect Statistics->Enabled to be True")
@@ -592,6 +765,22 @@ func TestReadConfig_Complex(t *testing.T) {
 		)
 	}
 
+	if config.Converters()[0].ErrorHistorySize() != 42 {
+		t.Errorf("expect ErrorHistorySize of first Converter to be 42, got %d", config.Converters()[0].ErrorHistorySize())
+	}
+
+	if !config.Converters()[0].GenericFallback() {
+		t.Error("expect GenericFallback of first Converter to be True")
+	}
+
+	if config.Converters()[0].Precision() != time.Millisecond {
+		t.Errorf("expect Precision of first Converter to be 1ms, got %s", config.Converters()[0].Precision())
+	}
+
+	if !config.Converters()[0].AddDeviceTag() {
+		t.Error("expect AddDeviceTag of first Converter to be True")
+	}
+
 	if !config.Converters()[0].LogHandleOnce() {
 		t.Error("expect LogHandleOnce of first Converter to be True")
 	}
@@ -714,6 +903,16 @@ func TestReadConfig_Default(t *testing.T) {
 			config.LocalDb().Path())
 	}
 
+	if config.LocalDb().DeadLetterMaxCount() != 10000 {
+		t.Errorf("expect LocalDb->DeadLetterMaxCount to be 10000, got %d",
+			config.LocalDb().DeadLetterMaxCount())
+	}
+
+	if config.LocalDb().DeadLetterMaxAge() != 168*time.Hour {
+		t.Errorf("expect LocalDb->DeadLetterMaxAge to be 168h, got %s",
+			config.LocalDb().DeadLetterMaxAge())
+	}
+
 	// Statistics
 	if config.Statistics().Enabled() {
 		t.Error("expect default Statistics->Enabled to be False")
@@ -821,6 +1020,22 @@ func TestReadConfig_Default(t *testing.T) {
 		t.Error("expect default Converter->InfluxClients to be empty")
 	}
 
+	if config.Converters()[0].ErrorHistorySize() != 10 {
+		t.Error("expect default Converter->ErrorHistorySize to be 10")
+	}
+
+	if config.Converters()[0].GenericFallback() {
+		t.Error("expect default Converter->GenericFallback to be False")
+	}
+
+	if config.Converters()[0].Precision() != time.Nanosecond {
+		t.Error("expect default Converter->Precision to be 1ns")
+	}
+
+	if config.Converters()[0].AddDeviceTag() {
+		t.Error("expect default Converter->AddDeviceTag to be False")
+	}
+
 	if config.Converters()[0].LogHandleOnce() {
 		t.Error("expect default Converter->LogHandleOnce to be False")
 	}
@@ -835,6 +1050,468 @@ func TestReadConfig_Default(t *testing.T) {
 	}
 }
 
+func TestReadConfig_Backends(t *testing.T) {
+	config, err := ReadConfig([]byte(ValidBackendsConfig))
+	if len(err) > 0 {
+		t.Fatalf("did not expect any errors, got %v", err)
+	}
+
+	clients := make(map[string]InfluxClientConfig)
+	for _, c := range config.InfluxClients() {
+		clients[c.Name()] = c
+	}
+
+	if v := clients["v2"].Backend(); v != "influxdb2" {
+		t.Errorf("expect default Backend to be 'influxdb2', got '%s'", v)
+	}
+
+	v1 := clients["v1"]
+	if v := v1.Backend(); v != "influxdb1" {
+		t.Errorf("expect Backend to be 'influxdb1', got '%s'", v)
+	}
+	if v := v1.User(); v != "bob" {
+		t.Errorf("expect User to be 'bob', got '%s'", v)
+	}
+	if v := v1.Password(); v != "secret" {
+		t.Errorf("expect Password to be 'secret', got '%s'", v)
+	}
+	if v := v1.Database(); v != "iot" {
+		t.Errorf("expect Database to be 'iot', got '%s'", v)
+	}
+	if v := v1.RetentionPolicy(); v != "autogen" {
+		t.Errorf("expect RetentionPolicy to be 'autogen', got '%s'", v)
+	}
+
+	if v := clients["prometheus"].Backend(); v != "prometheus-remote-write" {
+		t.Errorf("expect Backend to be 'prometheus-remote-write', got '%s'", v)
+	}
+
+	if v := clients["file"].Path(); v != "/tmp/points.lp" {
+		t.Errorf("expect Path to be '/tmp/points.lp', got '%s'", v)
+	}
+}
+
+func TestReadConfig_InvalidBackends(t *testing.T) {
+	_, err := ReadConfig([]byte(InvalidBackendsConfig))
+
+	t.Logf("InvalidBackendsConfig returned err=%v", err)
+
+	if !containsError("Backend='graphite' is unknown", err) {
+		t.Error("expect unknown Backend to be returned as error")
+	}
+
+	if !containsError("v1->Database must not be empty", err) {
+		t.Error("expect missing Database to be returned as error")
+	}
+
+	if !containsError("must be a http or https url", err) {
+		t.Error("expect invalid remote write Url to be returned as error")
+	}
+
+	if len(err) != 3 {
+		t.Errorf("expect 3 errors, got %d", len(err))
+	}
+}
+
+func TestReadConfig_JsonMapping(t *testing.T) {
+	config, err := ReadConfig([]byte(ValidJsonMappingConfig))
+	if len(err) > 0 {
+		t.Errorf("did not expect any errors, got %v", err)
+	}
+
+	mappings := config.Converters()[0].JsonMappings()
+	if len(mappings) != 2 {
+		t.Fatalf("expect len(JsonMappings) == 2, got %d", len(mappings))
+	}
+
+	if v := mappings[0].Field(); v != "temperature" {
+		t.Errorf("expect default Field to be the last path element 'temperature', got '%s'", v)
+	}
+
+	if v := mappings[0].ValueType(); v != "float" {
+		t.Errorf("expect default Type to be 'float', got '%s'", v)
+	}
+
+	if v := mappings[0].Sensor(); v != "zigbee" {
+		t.Errorf("expect default Sensor to be the converter name 'zigbee', got '%s'", v)
+	}
+
+	if v := mappings[0].Unit(); v != "°C" {
+		t.Errorf("expect Unit to be '°C', got '%s'", v)
+	}
+
+	if v := mappings[1].Field(); v != "UpdateState" {
+		t.Errorf("expect Field to be 'UpdateState', got '%s'", v)
+	}
+
+	if v := mappings[1].ValueType(); v != "string" {
+		t.Errorf("expect Type to be 'string', got '%s'", v)
+	}
+
+	if v := mappings[1].Sensor(); v != "z2m" {
+		t.Errorf("expect Sensor to be 'z2m', got '%s'", v)
+	}
+
+	if v := mappings[1].TimePath(); v != "$.time" {
+		t.Errorf("expect TimePath to be '$.time', got '%s'", v)
+	}
+}
+
+func TestReadConfig_InvalidJsonMapping(t *testing.T) {
+	_, err := ReadConfig([]byte(InvalidJsonMappingConfig))
+
+	t.Logf("InvalidJsonMappingConfig returned err=%v", err)
+
+	if !containsError("Path='a..b'", err) {
+		t.Error("expect invalid Path='a..b' to be returned as error")
+	}
+
+	if !containsError("Type='double'", err) {
+		t.Error("expect invalid Type='double' to be returned as error")
+	}
+
+	if !containsError("Path must not be empty", err) {
+		t.Error("expect missing Path to be returned as error")
+	}
+
+	if !containsError("empty->JsonMappings must not be empty", err) {
+		t.Error("expect missing JsonMappings to be returned as error")
+	}
+
+	if len(err) != 4 {
+		t.Errorf("expect 4 errors, got %d", len(err))
+	}
+}
+
+// writeTestCertificate creates a self-signed certificate and its private key in dir
+func writeTestCertificate(t *testing.T, dir string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "go-mqtt-to-influx-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+const tlsConfigTemplate = `
+Version: 0
+MqttClients:
+  secure:
+    Broker: "%s"
+    Tls:
+      CaFile: "%s"
+      CertFile: "%s"
+      KeyFile: "%s"
+      ServerName: broker.example.com
+      InsecureSkipVerify: %t
+
+InfluxClients:
+  piegn:
+    Url: http://172.17.0.2:8086
+    Token: "foobar-token"
+    Org: Piegn
+    Bucket: iot
+
+Converters:
+  tasmota-state:
+    Implementation: tasmota-state
+    MqttTopics:
+      - Topic: piegn/tele/%%Device%%/STATE
+`
+
+func TestReadConfig_Tls(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t, t.TempDir())
+
+	config, err := ReadConfig([]byte(fmt.Sprintf(tlsConfigTemplate,
+		"ssl://broker.example.com:8883", certFile, certFile, keyFile, true,
+	)))
+	if len(err) > 0 {
+		t.Fatalf("did not expect any errors, got %v", err)
+	}
+
+	mc := config.MqttClients()[0]
+	if !mc.Tls().Enabled() {
+		t.Error("expect Tls to be enabled")
+	}
+
+	if v := mc.Tls().CaFile(); v != certFile {
+		t.Errorf("expect CaFile to be '%s', got '%s'", certFile, v)
+	}
+
+	tlsConfig := mc.TlsConfig()
+	if tlsConfig == nil {
+		t.Fatal("expect TlsConfig to be set")
+	}
+
+	if v := tlsConfig.ServerName; v != "broker.example.com" {
+		t.Errorf("expect ServerName to be 'broker.example.com', got '%s'", v)
+	}
+
+	if !tlsConfig.InsecureSkipVerify {
+		t.Error("expect InsecureSkipVerify to be true")
+	}
+
+	if tlsConfig.RootCAs == nil {
+		t.Error("expect RootCAs to be set")
+	}
+
+	if len(tlsConfig.Certificates) != 1 {
+		t.Errorf("expect exactly 1 client certificate, got %d", len(tlsConfig.Certificates))
+	}
+}
+
+func TestReadConfig_TlsDisabled(t *testing.T) {
+	config, err := ReadConfig([]byte(ValidDefaultConfig))
+	if len(err) > 0 {
+		t.Fatalf("did not expect any errors, got %v", err)
+	}
+
+	mc := config.MqttClients()[0]
+	if mc.Tls().Enabled() {
+		t.Error("expect Tls to be disabled")
+	}
+
+	if mc.TlsConfig() != nil {
+		t.Error("expect TlsConfig to be nil")
+	}
+}
+
+func TestReadConfig_InvalidTls(t *testing.T) {
+	dir := t.TempDir()
+	certFile, _ := writeTestCertificate(t, dir)
+	invalidFile := filepath.Join(dir, "invalid.pem")
+	if err := os.WriteFile(invalidFile, []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := ReadConfig([]byte(fmt.Sprintf(tlsConfigTemplate,
+		"tcp://broker.example.com:1883", invalidFile, certFile, filepath.Join(dir, "missing.pem"), false,
+	)))
+
+	t.Logf("InvalidTls returned err=%v", err)
+
+	if !containsError("does not contain any PEM encoded certificate", err) {
+		t.Error("expect unparsable CaFile to be returned as error")
+	}
+
+	if !containsError("cannot load key pair", err) {
+		t.Error("expect missing KeyFile to be returned as error")
+	}
+
+	if !containsError("does not use tls", err) {
+		t.Error("expect tcp broker scheme to be returned as error")
+	}
+
+	_, err = ReadConfig([]byte(fmt.Sprintf(tlsConfigTemplate,
+		"mqtts://broker.example.com:8883", filepath.Join(dir, "missing.pem"), certFile, "", false,
+	)))
+
+	if !containsError("CaFile='"+filepath.Join(dir, "missing.pem")+"' cannot read", err) {
+		t.Error("expect missing CaFile to be returned as error")
+	}
+
+	if !containsError("KeyFile must be set when CertFile is set", err) {
+		t.Error("expect missing KeyFile to be returned as error")
+	}
+}
+
+const recorderConfigTemplate = `
+Version: 0
+MqttClients:
+  local:
+    Broker: "tcp://localhost:1883"
+    Recorder:
+%s
+  other:
+    Broker: "tcp://localhost:1883"
+
+InfluxClients:
+  piegn:
+    Url: http://172.17.0.2:8086
+    Token: "foobar-token"
+    Org: Piegn
+    Bucket: iot
+
+Converters:
+  tasmota-state:
+    Implementation: tasmota-state
+    MqttTopics:
+      - Topic: piegn/tele/%%Device%%/STATE
+`
+
+func TestReadConfig_Recorder(t *testing.T) {
+	config, err := ReadConfig([]byte(fmt.Sprintf(recorderConfigTemplate, `
+      Topics:
+        - tele/#
+        - stat/#
+      Directory: /app/db/records
+      MaxFileSize: 1000
+      MaxFileAge: 10m
+      MaxAge: 24h`,
+	)))
+	if len(err) > 0 {
+		t.Fatalf("did not expect any errors, got %v", err)
+	}
+
+	rc := config.MqttClients()[0].Recorder()
+	if !rc.Enabled() {
+		t.Error("expect Recorder to be enabled")
+	}
+	if expected := []string{"tele/#", "stat/#"}; !reflect.DeepEqual(expected, rc.Topics()) {
+		t.Errorf("expect Topics to be %v, got %v", expected, rc.Topics())
+	}
+	if v := rc.Directory(); v != "/app/db/records" {
+		t.Errorf("expect Directory to be '/app/db/records', got '%s'", v)
+	}
+	if v := rc.MaxFileSize(); v != 1000 {
+		t.Errorf("expect MaxFileSize to be 1000, got %d", v)
+	}
+	if v := rc.MaxFileAge(); v != 10*time.Minute {
+		t.Errorf("expect MaxFileAge to be 10m, got %s", v)
+	}
+	if v := rc.MaxAge(); v != 24*time.Hour {
+		t.Errorf("expect MaxAge to be 24h, got %s", v)
+	}
+
+	if config.MqttClients()[1].Recorder().Enabled() {
+		t.Error("expect Recorder of the other client to be disabled")
+	}
+
+	// defaults
+	config, err = ReadConfig([]byte(fmt.Sprintf(recorderConfigTemplate, "      Directory: ./records")))
+	if len(err) > 0 {
+		t.Fatalf("did not expect any errors, got %v", err)
+	}
+	rc = config.MqttClients()[0].Recorder()
+	if expected := []string{"#"}; !reflect.DeepEqual(expected, rc.Topics()) {
+		t.Errorf("expect Topics to be %v, got %v", expected, rc.Topics())
+	}
+	if v := rc.MaxFileSize(); v != 100000000 {
+		t.Errorf("expect MaxFileSize to be 100000000, got %d", v)
+	}
+	if v := rc.MaxFileAge(); v != time.Hour {
+		t.Errorf("expect MaxFileAge to be 1h, got %s", v)
+	}
+	if v := rc.MaxAge(); v != 168*time.Hour {
+		t.Errorf("expect MaxAge to be 168h, got %s", v)
+	}
+
+	// invalid
+	_, err = ReadConfig([]byte(fmt.Sprintf(recorderConfigTemplate, `
+      MaxFileSize: 0
+      MaxFileAge: 10ms
+      MaxAge: foo`,
+	)))
+	for _, field := range []string{"MaxFileSize", "MaxFileAge", "MaxAge='foo'"} {
+		if !containsError("Recorder->"+field, err) {
+			t.Errorf("expect invalid %s to be returned as error", field)
+		}
+	}
+}
+
+const periodicPublishesConfigTemplate = `
+Version: 0
+MqttClients:
+  venus:
+    Broker: "tcp://localhost:1883"
+    TopicPrefix: "piegn/"
+    PeriodicPublishes:
+%s
+  other:
+    Broker: "tcp://localhost:1883"
+
+InfluxClients:
+  piegn:
+    Url: http://172.17.0.2:8086
+    Token: "foobar-token"
+    Org: Piegn
+    Bucket: iot
+
+Converters:
+  venus-os:
+    Implementation: venus-os
+    MqttTopics:
+      - Topic: N/%%Device%%
+        Device: "+/+/+"
+`
+
+func TestReadConfig_PeriodicPublishes(t *testing.T) {
+	config, err := ReadConfig([]byte(fmt.Sprintf(periodicPublishesConfigTemplate, `
+      - Topic: R/c0619ab12345/keepalive
+        Payload: '{"keepalive-options": ["suppress-republish"]}'
+        Interval: 50s
+      - Topic: "%Prefix%R/c0619ab67890/keepalive"`,
+	)))
+	if len(err) > 0 {
+		t.Fatalf("did not expect any errors, got %v", err)
+	}
+
+	pp := config.MqttClients()[1].PeriodicPublishes()
+	if len(pp) != 2 {
+		t.Fatalf("expect 2 PeriodicPublishes, got %d", len(pp))
+	}
+	if v := pp[0].Topic(); v != "R/c0619ab12345/keepalive" {
+		t.Errorf("expect Topic to be 'R/c0619ab12345/keepalive', got '%s'", v)
+	}
+	if v := pp[0].Payload(); v != `{"keepalive-options": ["suppress-republish"]}` {
+		t.Errorf("expect Payload to be set, got '%s'", v)
+	}
+	if v := pp[0].Interval(); v != 50*time.Second {
+		t.Errorf("expect Interval to be 50s, got %s", v)
+	}
+
+	// defaults
+	if v := pp[1].Payload(); v != "" {
+		t.Errorf("expect Payload to be empty, got '%s'", v)
+	}
+	if v := pp[1].Interval(); v != 30*time.Second {
+		t.Errorf("expect Interval to be 30s, got %s", v)
+	}
+	if v := config.MqttClients()[0].PeriodicPublishes(); len(v) != 0 {
+		t.Errorf("expect PeriodicPublishes of the other client to be empty, got %v", v)
+	}
+
+	// invalid
+	_, err = ReadConfig([]byte(fmt.Sprintf(periodicPublishesConfigTemplate, `
+      - Topic: ""
+      - Topic: R/+/keepalive
+        Interval: 100ms
+      - Topic: R/c0619ab12345/keepalive
+        Interval: foo`,
+	)))
+	for _, field := range []string{"Topic must not be empty", "Topic='R/+/keepalive'", "Interval='100ms'", "Interval='foo'"} {
+		if !containsError("PeriodicPublishes->"+field, err) {
+			t.Errorf("expect invalid %s to be returned as error", field)
+		}
+	}
+}
+
 // check that configuration file in the documentation do not contain any errors
 func TestReadConfig_DocumentationConfig(t *testing.T) {
 	_, err := ReadConfigFile("", "../documentation/config.yaml")
@@ -849,3 +1526,78 @@ func TestReadConfig_DocumentationFullConfig(t *testing.T) {
 		t.Errorf("did not expect any errors, got %v", err)
 	}
 }
+
+func TestConfig_Equal(t *testing.T) {
+	a, err := ReadConfig([]byte(ValidDefaultConfig))
+	if len(err) > 0 {
+		t.Fatalf("did not expect any errors, got: %v", err)
+	}
+	b, err := ReadConfig([]byte(ValidDefaultConfig))
+	if len(err) > 0 {
+		t.Fatalf("did not expect any errors, got: %v", err)
+	}
+
+	if a.MqttClients()[0].ClientId() == b.MqttClients()[0].ClientId() {
+		t.Error("expect a new ClientId to be generated on each read")
+	}
+	if !a.MqttClients()[0].Equal(b.MqttClients()[0]) {
+		t.Error("expect MqttClients with generated ClientIds to be equal")
+	}
+	if !a.InfluxClients()[0].Equal(b.InfluxClients()[0]) {
+		t.Error("expect InfluxClients to be equal")
+	}
+	if !a.Converters()[0].Equal(b.Converters()[0]) {
+		t.Error("expect Converters to be equal")
+	}
+	if !a.HttpServer().Equal(b.HttpServer()) || !a.LocalDb().Equal(b.LocalDb()) || !a.Statistics().Equal(b.Statistics()) {
+		t.Error("expect HttpServer, LocalDb and Statistics to be equal")
+	}
+	if !a.InfluxAuxiliaryTagsEqual(b) {
+		t.Error("expect InfluxAuxiliaryTags to be equal")
+	}
+
+	c, err := ReadConfig([]byte(strings.NewReplacer(
+		"example.com", "example.org",
+		"Bucket: iot", "Bucket: other",
+		"SENSOR", "STATE",
+	).Replace(ValidDefaultConfig)))
+	if len(err) > 0 {
+		t.Fatalf("did not expect any errors, got: %v", err)
+	}
+
+	if a.MqttClients()[0].Equal(c.MqttClients()[0]) {
+		t.Error("expect MqttClients with different Broker to differ")
+	}
+	if a.InfluxClients()[0].Equal(c.InfluxClients()[0]) {
+		t.Error("expect InfluxClients with different Bucket to differ")
+	}
+	if a.Converters()[0].Equal(c.Converters()[0]) {
+		t.Error("expect Converters with different MqttTopics to differ")
+	}
+}
+
+func TestConfig_KeepRunningInfluxClient(t *testing.T) {
+	running, err := ReadConfig([]byte(ValidDefaultConfig))
+	if len(err) > 0 {
+		t.Fatalf("did not expect any errors, got: %v", err)
+	}
+	changed, err := ReadConfig([]byte(strings.NewReplacer(
+		"Bucket: iot", "Bucket: other",
+	).Replace(ValidDefaultConfig)))
+	if len(err) > 0 {
+		t.Fatalf("did not expect any errors, got: %v", err)
+	}
+
+	name := running.InfluxClients()[0].Name()
+	changed.KeepRunningInfluxClient(running, name)
+	if len(changed.InfluxClients()) != 1 || !changed.InfluxClients()[0].Equal(running.InfluxClients()[0]) {
+		t.Error("expect the running InfluxClient to be kept")
+	}
+
+	// a client not running before is removed
+	var empty Config
+	changed.KeepRunningInfluxClient(empty, name)
+	if len(changed.InfluxClients()) != 0 {
+		t.Errorf("expect the InfluxClient to be removed, got %d clients", len(changed.InfluxClients()))
+	}
+}
@@ -0,0 +1,76 @@
+package config
+
+import "reflect"
+
+// The Equal methods are used to determine which parts of a reloaded configuration differ from the running one.
+// Two sections are considered equal when they would be written identically by PrintConfig.
+
+func (c HttpServerConfig) Equal(o HttpServerConfig) bool {
+	return c.enabled == o.enabled && reflect.DeepEqual(c.convertToRead(), o.convertToRead())
+}
+
+func (c LocalDbConfig) Equal(o LocalDbConfig) bool {
+	return c.enabled == o.enabled && reflect.DeepEqual(c.convertToRead(), o.convertToRead())
+}
+
+func (c StatisticsConfig) Equal(o StatisticsConfig) bool {
+	return c.enabled == o.enabled && reflect.DeepEqual(c.convertToRead(), o.convertToRead())
+}
+
+// Equal ignores the ClientId when it is generated in both configurations since a new one is generated on every read
+func (c MqttClientConfig) Equal(o MqttClientConfig) bool {
+	if c.name != o.name || c.clientIdGenerated != o.clientIdGenerated {
+		return false
+	}
+
+	cr, or := c.convertToRead(), o.convertToRead()
+	if c.clientIdGenerated {
+		cr.ClientId, or.ClientId = nil, nil
+	}
+	return reflect.DeepEqual(cr, or)
+}
+
+func (c InfluxClientConfig) Equal(o InfluxClientConfig) bool {
+	return c.name == o.name && reflect.DeepEqual(c.convertToRead(), o.convertToRead())
+}
+
+func (c ConverterConfig) Equal(o ConverterConfig) bool {
+	return c.name == o.name && reflect.DeepEqual(c.convertToRead(), o.convertToRead())
+}
+
+// InfluxAuxiliaryTagsEqual returns true when both configurations define the same auxiliary tags in the same order
+func (c Config) InfluxAuxiliaryTagsEqual(o Config) bool {
+	if len(c.influxAuxiliaryTags) != len(o.influxAuxiliaryTags) {
+		return false
+	}
+	for i := range c.influxAuxiliaryTags {
+		if !reflect.DeepEqual(c.influxAuxiliaryTags[i].convertToRead(), o.influxAuxiliaryTags[i].convertToRead()) {
+			return false
+		}
+	}
+	return true
+}
+
+// KeepRunningInfluxClient is used when the influx client with the given name could not be (re)started during a reload:
+// its entry is replaced by the one of the running configuration or removed when it was not running before.
+// When the auxiliary tags changed, the running ones are kept as well since the old client still uses them.
+// This way the next reload sees the difference again and retries.
+func (c *Config) KeepRunningInfluxClient(running Config, name string) {
+	influxClients := make([]InfluxClientConfig, 0, len(c.influxClients))
+	for _, ic := range c.influxClients {
+		if ic.name != name {
+			influxClients = append(influxClients, ic)
+			continue
+		}
+		for _, rc := range running.influxClients {
+			if rc.name == name {
+				influxClients = append(influxClients, rc)
+			}
+		}
+	}
+	c.influxClients = influxClients
+
+	if !c.InfluxAuxiliaryTagsEqual(running) {
+		c.influxAuxiliaryTags = running.influxAuxiliaryTags
+	}
+}
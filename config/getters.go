@@ -1,6 +1,7 @@
 package config
 
 import (
+	"crypto/tls"
 	"net/url"
 	"regexp"
 	"time"
@@ -76,6 +77,14 @@ func (c LocalDbConfig) Path() string {
 	return c.path
 }
 
+func (c LocalDbConfig) DeadLetterMaxCount() int {
+	return c.deadLetterMaxCount
+}
+
+func (c LocalDbConfig) DeadLetterMaxAge() time.Duration {
+	return c.deadLetterMaxAge
+}
+
 // getters for StatisticsConfig struct
 
 func (c StatisticsConfig) Enabled() bool {
@@ -141,6 +150,23 @@ func (c MqttClientConfig) TopicPrefix() string {
 	return c.topicPrefix
 }
 
+func (c MqttClientConfig) Tls() MqttTlsConfig {
+	return c.tls
+}
+
+// TlsConfig returns the tls.Config used to connect to the broker or nil when no Tls section is configured
+func (c MqttClientConfig) TlsConfig() *tls.Config {
+	return c.tls.tlsConfig
+}
+
+func (c MqttClientConfig) Recorder() RecorderConfig {
+	return c.recorder
+}
+
+func (c MqttClientConfig) PeriodicPublishes() []PeriodicPublishConfig {
+	return c.periodicPublishes
+}
+
 func (c MqttClientConfig) LogDebug() bool {
 	return c.logDebug
 }
@@ -149,12 +175,82 @@ func (c MqttClientConfig) LogMessages() bool {
 	return c.logMessages
 }
 
+// getters for MqttTlsConfig struct
+
+func (c MqttTlsConfig) Enabled() bool {
+	return c.enabled
+}
+
+func (c MqttTlsConfig) CaFile() string {
+	return c.caFile
+}
+
+func (c MqttTlsConfig) CertFile() string {
+	return c.certFile
+}
+
+func (c MqttTlsConfig) KeyFile() string {
+	return c.keyFile
+}
+
+func (c MqttTlsConfig) ServerName() string {
+	return c.serverName
+}
+
+func (c MqttTlsConfig) InsecureSkipVerify() bool {
+	return c.insecureSkipVerify
+}
+
+// getters for RecorderConfig struct
+
+func (c RecorderConfig) Enabled() bool {
+	return c.enabled
+}
+
+func (c RecorderConfig) Topics() []string {
+	return c.topics
+}
+
+func (c RecorderConfig) Directory() string {
+	return c.directory
+}
+
+func (c RecorderConfig) MaxFileSize() int64 {
+	return c.maxFileSize
+}
+
+func (c RecorderConfig) MaxFileAge() time.Duration {
+	return c.maxFileAge
+}
+
+func (c RecorderConfig) MaxAge() time.Duration {
+	return c.maxAge
+}
+
+// getters for PeriodicPublishConfig struct
+
+func (c PeriodicPublishConfig) Topic() string {
+	return c.topic
+}
+
+func (c PeriodicPublishConfig) Payload() string {
+	return c.payload
+}
+
+func (c PeriodicPublishConfig) Interval() time.Duration {
+	return c.interval
+}
+
 // getters for InfluxClientConfig struct
 
 func (c InfluxClientConfig) Name() string {
 	return c.name
 }
 
+func (c InfluxClientConfig) Backend() string {
+	return c.backend
+}
+
 func (c InfluxClientConfig) Url() string {
 	return c.url
 }
@@ -171,6 +267,26 @@ func (c InfluxClientConfig) Bucket() string {
 	return c.bucket
 }
 
+func (c InfluxClientConfig) User() string {
+	return c.user
+}
+
+func (c InfluxClientConfig) Password() string {
+	return c.password
+}
+
+func (c InfluxClientConfig) Database() string {
+	return c.database
+}
+
+func (c InfluxClientConfig) RetentionPolicy() string {
+	return c.retentionPolicy
+}
+
+func (c InfluxClientConfig) Path() string {
+	return c.path
+}
+
 func (c InfluxClientConfig) WriteInterval() time.Duration {
 	return c.writeInterval
 }
@@ -225,6 +341,27 @@ func (c ConverterConfig) InfluxClients() []string {
 	return c.influxClients
 }
 
+func (c ConverterConfig) JsonMappings() []JsonMappingConfig {
+	return c.jsonMappings
+}
+
+func (c ConverterConfig) ErrorHistorySize() int {
+	return c.errorHistorySize
+}
+
+func (c ConverterConfig) GenericFallback() bool {
+	return c.genericFallback
+}
+
+// Precision returns the unit of the timestamps of the lines handled by the influx-line implementation
+func (c ConverterConfig) Precision() time.Duration {
+	return lineProtocolPrecisions[c.precision]
+}
+
+func (c ConverterConfig) AddDeviceTag() bool {
+	return c.addDeviceTag
+}
+
 func (c ConverterConfig) LogHandleOnce() bool {
 	return c.logHandleOnce
 }
@@ -243,12 +380,45 @@ func (c MqttTopicConfig) Device() string {
 	return c.device
 }
 
-var deviceDynamicMatcher = regexp.MustCompile(`\+`)
+var lineProtocolPrecisions = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+}
+
+var deviceDynamicMatcher = regexp.MustCompile(`[+#]`)
 
 func (c MqttTopicConfig) DeviceIsDynamic() bool {
 	return deviceDynamicMatcher.MatchString(c.device)
 }
 
+// getters for JsonMappingConfig struct
+
+func (c JsonMappingConfig) Path() string {
+	return c.path
+}
+
+func (c JsonMappingConfig) Field() string {
+	return c.field
+}
+
+func (c JsonMappingConfig) Unit() string {
+	return c.unit
+}
+
+func (c JsonMappingConfig) ValueType() string {
+	return c.valueType
+}
+
+func (c JsonMappingConfig) Sensor() string {
+	return c.sensor
+}
+
+func (c JsonMappingConfig) TimePath() string {
+	return c.timePath
+}
+
 // getters for InfluxAuxiliaryTags struct
 
 func (c InfluxAuxiliaryTags) Tag() string {
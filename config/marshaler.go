@@ -67,7 +67,9 @@ func (c HttpServerConfig) convertToRead() httpServerConfigRead {
 
 func (c LocalDbConfig) convertToRead() localDbConfigRead {
 	return localDbConfigRead{
-		Path: &c.path,
+		Path:               &c.path,
+		DeadLetterMaxCount: &c.deadLetterMaxCount,
+		DeadLetterMaxAge:   c.DeadLetterMaxAge().String(),
 	}
 }
 
@@ -91,17 +93,71 @@ func (c MqttClientConfig) convertToRead() mqttClientConfigRead {
 		ConnectTimeout:    c.connectTimeout.String(),
 		AvailabilityTopic: &c.availabilityTopic,
 		TopicPrefix:       c.topicPrefix,
-		LogDebug:          &c.logDebug,
-		LogMessages:       &c.logMessages,
+		Tls:               c.tls.convertToRead(),
+		Recorder:          c.recorder.convertToRead(),
+		PeriodicPublishes: func() periodicPublishConfigReadList {
+			if len(c.periodicPublishes) < 1 {
+				return nil
+			}
+			ret := make(periodicPublishConfigReadList, len(c.periodicPublishes))
+			for i, p := range c.periodicPublishes {
+				ret[i] = p.convertToRead()
+			}
+			return ret
+		}(),
+		LogDebug:    &c.logDebug,
+		LogMessages: &c.logMessages,
+	}
+}
+
+func (c MqttTlsConfig) convertToRead() *mqttTlsConfigRead {
+	if !c.enabled {
+		return nil
+	}
+
+	return &mqttTlsConfigRead{
+		CaFile:             c.caFile,
+		CertFile:           c.certFile,
+		KeyFile:            c.keyFile,
+		ServerName:         c.serverName,
+		InsecureSkipVerify: &c.insecureSkipVerify,
+	}
+}
+
+func (c RecorderConfig) convertToRead() *recorderConfigRead {
+	if !c.enabled {
+		return nil
+	}
+
+	return &recorderConfigRead{
+		Topics:      c.topics,
+		Directory:   c.directory,
+		MaxFileSize: &c.maxFileSize,
+		MaxFileAge:  c.maxFileAge.String(),
+		MaxAge:      c.maxAge.String(),
+	}
+}
+
+func (c PeriodicPublishConfig) convertToRead() periodicPublishConfigRead {
+	return periodicPublishConfigRead{
+		Topic:    c.topic,
+		Payload:  c.payload,
+		Interval: c.interval.String(),
 	}
 }
 
 func (c InfluxClientConfig) convertToRead() influxClientConfigRead {
 	return influxClientConfigRead{
+		Backend:           c.backend,
 		Url:               c.url,
 		Token:             c.token,
 		Org:               c.org,
 		Bucket:            c.bucket,
+		User:              c.user,
+		Password:          c.password,
+		Database:          c.database,
+		RetentionPolicy:   c.retentionPolicy,
+		Path:              c.path,
 		WriteInterval:     c.writeInterval.String(),
 		RetryInterval:     c.retryInterval.String(),
 		AggregateInterval: c.aggregateInterval.String(),
@@ -123,9 +179,23 @@ func (c ConverterConfig) convertToRead() converterConfigRead {
 			}
 			return ret
 		}(),
-		MqttClients:   c.mqttClients,
-		LogHandleOnce: &c.logHandleOnce,
-		LogDebug:      &c.logDebug,
+		MqttClients: c.mqttClients,
+		JsonMappings: func() []jsonMappingConfigRead {
+			if len(c.jsonMappings) < 1 {
+				return nil
+			}
+			ret := make([]jsonMappingConfigRead, len(c.jsonMappings))
+			for i, m := range c.jsonMappings {
+				ret[i] = m.convertToRead()
+			}
+			return ret
+		}(),
+		ErrorHistorySize: &c.errorHistorySize,
+		GenericFallback:  &c.genericFallback,
+		Precision:        c.precision,
+		AddDeviceTag:     &c.addDeviceTag,
+		LogHandleOnce:    &c.logHandleOnce,
+		LogDebug:         &c.logDebug,
 	}
 }
 
@@ -136,6 +206,17 @@ func (c MqttTopicConfig) convertToRead() mqttTopicConfigRead {
 	}
 }
 
+func (c JsonMappingConfig) convertToRead() jsonMappingConfigRead {
+	return jsonMappingConfigRead{
+		Path:     c.path,
+		Field:    c.field,
+		Unit:     c.unit,
+		Type:     c.valueType,
+		Sensor:   c.sensor,
+		TimePath: c.timePath,
+	}
+}
+
 func (c InfluxAuxiliaryTags) convertToRead() influxAuxiliaryTagsRead {
 	return influxAuxiliaryTagsRead{
 		Tag:       &c.tag,
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"crypto/tls"
 	"net/url"
 	"regexp"
 	"time"
@@ -27,8 +28,10 @@ type HttpServerConfig struct {
 }
 
 type LocalDbConfig struct {
-	enabled bool   // defined automatically if LocalDbConfig section exists
-	path    string // optional: defaults ./go-mqtt-to-influx.db
+	enabled            bool          // defined automatically if LocalDbConfig section exists
+	path               string        // optional: defaults ./go-mqtt-to-influx.db
+	deadLetterMaxCount int           // optional: default 10000, 0 disables the dead letter storage
+	deadLetterMaxAge   time.Duration // optional: default 168h
 }
 
 type StatisticsConfig struct {
@@ -38,28 +41,63 @@ type StatisticsConfig struct {
 }
 
 type MqttClientConfig struct {
-	name              string        // defined automatically by map key
-	broker            *url.URL      // mandatory
-	protocolVersion   int           // optional: default 5
-	user              string        // optional: default empty
-	password          string        // optional: default empty
-	clientId          string        // optional: default go-mqtt-to-influx-UUID
-	qos               byte          // optional: default 1, must be 0, 1, 2
-	keepAlive         time.Duration // optional: default 60s
-	connectRetryDelay time.Duration // optional: default 10s
-	connectTimeout    time.Duration // optional: default 5s
-	availabilityTopic string        // optional: default %Prefix%tele/%ClientId%/status
-	topicPrefix       string        // optional: default empty
-	logDebug          bool          // optional: default False
-	logMessages       bool          // optional: default False
+	name              string                  // defined automatically by map key
+	broker            *url.URL                // mandatory
+	protocolVersion   int                     // optional: default 5
+	user              string                  // optional: default empty
+	password          string                  // optional: default empty
+	clientId          string                  // optional: default go-mqtt-to-influx-UUID
+	clientIdGenerated bool                    // defined automatically if ClientId is not set
+	qos               byte                    // optional: default 1, must be 0, 1, 2
+	keepAlive         time.Duration           // optional: default 60s
+	connectRetryDelay time.Duration           // optional: default 10s
+	connectTimeout    time.Duration           // optional: default 5s
+	availabilityTopic string                  // optional: default %Prefix%tele/%ClientId%/status
+	topicPrefix       string                  // optional: default empty
+	tls               MqttTlsConfig           // optional: default Disabled
+	recorder          RecorderConfig          // optional: default Disabled
+	periodicPublishes []PeriodicPublishConfig // optional: default empty
+	logDebug          bool                    // optional: default False
+	logMessages       bool                    // optional: default False
+}
+
+type MqttTlsConfig struct {
+	enabled            bool        // defined automatically if Tls section exists
+	caFile             string      // optional: default empty, the system root CAs are used
+	certFile           string      // optional: default empty, mandatory when keyFile is set
+	keyFile            string      // optional: default empty, mandatory when certFile is set
+	serverName         string      // optional: default empty, the host of the broker url is used
+	insecureSkipVerify bool        // optional: default False
+	tlsConfig          *tls.Config // defined automatically by loading the files above
+}
+
+type RecorderConfig struct {
+	enabled     bool          // defined automatically if Recorder section exists
+	topics      []string      // optional: default ["#"]
+	directory   string        // optional: default ./records
+	maxFileSize int64         // optional: default 100000000, uncompressed bytes after which a new file is started
+	maxFileAge  time.Duration // optional: default 1h, age after which a new file is started
+	maxAge      time.Duration // optional: default 168h, files older than this are deleted
+}
+
+type PeriodicPublishConfig struct {
+	topic    string        // mandatory
+	payload  string        // optional: default empty
+	interval time.Duration // optional: default 30s
 }
 
 type InfluxClientConfig struct {
 	name              string        // defined automatically by map key
-	url               string        // mandatory
-	token             string        // mandatory
-	org               string        // mandatory
-	bucket            string        // mandatory
+	backend           string        // optional: default influxdb2, must be influxdb2, influxdb1, prometheus-remote-write or line-protocol
+	url               string        // mandatory: except for the line-protocol backend
+	token             string        // mandatory: for the influxdb2 backend
+	org               string        // mandatory: for the influxdb2 backend
+	bucket            string        // mandatory: for the influxdb2 backend
+	user              string        // optional: default empty, used by the influxdb1 and prometheus-remote-write backends
+	password          string        // optional: default empty, used by the influxdb1 and prometheus-remote-write backends
+	database          string        // mandatory: for the influxdb1 backend
+	retentionPolicy   string        // optional: default empty, the default retention policy of the database is used
+	path              string        // optional: default empty, the line-protocol backend writes to stdout
 	writeInterval     time.Duration // optional: default 10s
 	retryInterval     time.Duration // optional: default 10s
 	aggregateInterval time.Duration // optional: default 60s
@@ -71,13 +109,18 @@ type InfluxClientConfig struct {
 }
 
 type ConverterConfig struct {
-	name           string            // defined automatically by map key
-	implementation string            // mandatory
-	mqttTopics     []MqttTopicConfig // mandatory: at least 1 must be defined
-	mqttClients    []string          // optional: defaults to all defined clients
-	influxClients  []string          // optional: defaults to all defined clients
-	logHandleOnce  bool              // optional: default False
-	logDebug       bool              // optional: default False
+	name             string              // defined automatically by map key
+	implementation   string              // mandatory
+	mqttTopics       []MqttTopicConfig   // mandatory: at least 1 must be defined
+	mqttClients      []string            // optional: defaults to all defined clients
+	influxClients    []string            // optional: defaults to all defined clients
+	jsonMappings     []JsonMappingConfig // optional: mandatory for the json-mapping implementation
+	errorHistorySize int                 // optional: default 10, 0 disables the history
+	genericFallback  bool                // optional: default False
+	precision        string              // optional: default ns, must be ns, us, ms or s
+	addDeviceTag     bool                // optional: default False
+	logHandleOnce    bool                // optional: default False
+	logDebug         bool                // optional: default False
 }
 
 type MqttTopicConfig struct {
@@ -85,6 +128,15 @@ type MqttTopicConfig struct {
 	device string // optional: default "+"
 }
 
+type JsonMappingConfig struct {
+	path      string // mandatory: dotted path or JSONPath like $.a.b[0]
+	field     string // optional: default last element of path
+	unit      string // optional: default empty
+	valueType string // optional: default float, must be float, int, bool or string
+	sensor    string // optional: default name of the converter
+	timePath  string // optional: default empty, the receive time is used
+}
+
 type InfluxAuxiliaryTags struct {
 	tag       string            // optional: defaults to "device"
 	equals    *string           // optional: if not set, matches must be set
@@ -22,7 +22,9 @@ type httpServerConfigRead struct {
 }
 
 type localDbConfigRead struct {
-	Path *string `yaml:"Path"`
+	Path               *string `yaml:"Path"`
+	DeadLetterMaxCount *int    `yaml:"DeadLetterMaxCount"`
+	DeadLetterMaxAge   string  `yaml:"DeadLetterMaxAge"`
 }
 
 type statisticsConfigRead struct {
@@ -31,28 +33,61 @@ type statisticsConfigRead struct {
 }
 
 type mqttClientConfigRead struct {
-	Broker            string  `yaml:"Broker"`
-	ProtocolVersion   *int    `yaml:"ProtocolVersion"`
-	User              string  `yaml:"User"`
-	Password          string  `yaml:"Password"`
-	ClientId          *string `yaml:"ClientId"`
-	Qos               *byte   `yaml:"Qos"`
-	KeepAlive         string  `yaml:"KeepAlive"`
-	ConnectRetryDelay string  `yaml:"ConnectRetryDelay"`
-	ConnectTimeout    string  `yaml:"ConnectTimeout"`
-	AvailabilityTopic *string `yaml:"AvailabilityTopic"`
-	TopicPrefix       string  `yaml:"TopicPrefix"`
-	LogDebug          *bool   `yaml:"LogDebug"`
-	LogMessages       *bool   `yaml:"LogMessages"`
+	Broker            string                        `yaml:"Broker"`
+	ProtocolVersion   *int                          `yaml:"ProtocolVersion"`
+	User              string                        `yaml:"User"`
+	Password          string                        `yaml:"Password"`
+	ClientId          *string                       `yaml:"ClientId"`
+	Qos               *byte                         `yaml:"Qos"`
+	KeepAlive         string                        `yaml:"KeepAlive"`
+	ConnectRetryDelay string                        `yaml:"ConnectRetryDelay"`
+	ConnectTimeout    string                        `yaml:"ConnectTimeout"`
+	AvailabilityTopic *string                       `yaml:"AvailabilityTopic"`
+	TopicPrefix       string                        `yaml:"TopicPrefix"`
+	Tls               *mqttTlsConfigRead            `yaml:"Tls"`
+	Recorder          *recorderConfigRead           `yaml:"Recorder"`
+	PeriodicPublishes periodicPublishConfigReadList `yaml:"PeriodicPublishes"`
+	LogDebug          *bool                         `yaml:"LogDebug"`
+	LogMessages       *bool                         `yaml:"LogMessages"`
 }
 
+type mqttTlsConfigRead struct {
+	CaFile             string `yaml:"CaFile"`
+	CertFile           string `yaml:"CertFile"`
+	KeyFile            string `yaml:"KeyFile"`
+	ServerName         string `yaml:"ServerName"`
+	InsecureSkipVerify *bool  `yaml:"InsecureSkipVerify"`
+}
+
+type recorderConfigRead struct {
+	Topics      []string `yaml:"Topics"`
+	Directory   string   `yaml:"Directory"`
+	MaxFileSize *int64   `yaml:"MaxFileSize"`
+	MaxFileAge  string   `yaml:"MaxFileAge"`
+	MaxAge      string   `yaml:"MaxAge"`
+}
+
+type periodicPublishConfigRead struct {
+	Topic    string `yaml:"Topic"`
+	Payload  string `yaml:"Payload"`
+	Interval string `yaml:"Interval"`
+}
+
+type periodicPublishConfigReadList []periodicPublishConfigRead
+
 type mqttClientConfigReadMap map[string]mqttClientConfigRead
 
 type influxClientConfigRead struct {
+	Backend           string `yaml:"Backend"`
 	Url               string `yaml:"Url"`
 	Token             string `yaml:"Token"`
 	Org               string `yaml:"Org"`
 	Bucket            string `yaml:"Bucket"`
+	User              string `yaml:"User"`
+	Password          string `yaml:"Password"`
+	Database          string `yaml:"Database"`
+	RetentionPolicy   string `yaml:"RetentionPolicy"`
+	Path              string `yaml:"Path"`
 	WriteInterval     string `yaml:"WriteInterval"`
 	RetryInterval     string `yaml:"RetryInterval"`
 	AggregateInterval string `yaml:"AggregateInterval"`
@@ -66,12 +101,17 @@ type influxClientConfigRead struct {
 type influxClientConfigReadMap map[string]influxClientConfigRead
 
 type converterConfigRead struct {
-	Implementation string                  `yaml:"Implementation"`
-	MqttTopics     mqttTopicConfigReadList `yaml:"MqttTopics"`
-	MqttClients    []string                `yaml:"MqttClients"`
-	InfluxClients  []string                `yaml:"InfluxClients"`
-	LogHandleOnce  *bool                   `yaml:"LogHandleOnce"`
-	LogDebug       *bool                   `yaml:"LogDebug"`
+	Implementation   string                    `yaml:"Implementation"`
+	MqttTopics       mqttTopicConfigReadList   `yaml:"MqttTopics"`
+	MqttClients      []string                  `yaml:"MqttClients"`
+	InfluxClients    []string                  `yaml:"InfluxClients"`
+	JsonMappings     jsonMappingConfigReadList `yaml:"JsonMappings"`
+	ErrorHistorySize *int                      `yaml:"ErrorHistorySize"`
+	GenericFallback  *bool                     `yaml:"GenericFallback"`
+	Precision        string                    `yaml:"Precision"`
+	AddDeviceTag     *bool                     `yaml:"AddDeviceTag"`
+	LogHandleOnce    *bool                     `yaml:"LogHandleOnce"`
+	LogDebug         *bool                     `yaml:"LogDebug"`
 }
 
 type converterConfigReadMap map[string]converterConfigRead
@@ -83,6 +123,17 @@ type mqttTopicConfigRead struct {
 
 type mqttTopicConfigReadList []mqttTopicConfigRead
 
+type jsonMappingConfigRead struct {
+	Path     string `yaml:"Path"`
+	Field    string `yaml:"Field"`
+	Unit     string `yaml:"Unit"`
+	Type     string `yaml:"Type"`
+	Sensor   string `yaml:"Sensor"`
+	TimePath string `yaml:"TimePath"`
+}
+
+type jsonMappingConfigReadList []jsonMappingConfigRead
+
 type influxAuxiliaryTagsRead struct {
 	Tag       *string           `yaml:"Tag"`
 	Equals    *string           `yaml:"Equals"`
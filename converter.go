@@ -4,15 +4,21 @@ import (
 	"github.com/koestler/go-mqtt-to-influx/v2/config"
 	"github.com/koestler/go-mqtt-to-influx/v2/converter"
 	"github.com/koestler/go-mqtt-to-influx/v2/influxClient"
+	LocalDb "github.com/koestler/go-mqtt-to-influx/v2/localDb"
+	"github.com/koestler/go-mqtt-to-influx/v2/metrics"
 	"github.com/koestler/go-mqtt-to-influx/v2/mqttClient"
 	"github.com/koestler/go-mqtt-to-influx/v2/statistics"
 	"github.com/pkg/errors"
 	"log"
+	"time"
 )
 
 func createConverters(
 	cfg *config.Config,
 	statisticsInstance statistics.Statistics,
+	metricsInstance *metrics.Metrics,
+	errorHistoryInstance *converter.ErrorHistory,
+	localDbInstance LocalDb.LocalDb,
 	mqttClientPoolInstance *mqttClient.ClientPool,
 	influxClientPoolInstance *influxClient.ClientPool,
 	initiateShutdown chan<- error,
@@ -24,61 +30,121 @@ func createConverters(
 	}
 
 	// iterate through all converters
-	for _, converterConfig := range cfg.Converters() {
-		handleFunc, err := converter.GetHandler(converterConfig.Implementation())
-		if err != nil {
-			log.Printf("converter[%s]: cannot create: %s", converterConfig.Name(), err)
-			continue
-		}
+	for _, cc := range cfg.Converters() {
+		countCreated += createConverter(
+			cfg,
+			cc,
+			mqttClientPoolInstance.GetClientsByNames(cc.MqttClients()),
+			statisticsInstance,
+			metricsInstance,
+			errorHistoryInstance,
+			localDbInstance,
+			influxClientPoolInstance,
+		)
+	}
 
-		// iterate through all connected mqtt clients
-		for _, mqttClientInstance := range mqttClientPoolInstance.GetClientsByNames(converterConfig.MqttClients()) {
-			// iterate through all topics
-			for _, mqttTopic := range converterConfig.MqttTopics() {
-				topicMatcher, err := converter.CreateTopicMatcher(
-					mqttTopic.ApplyTopicReplace(mqttClientInstance.ReplaceTemplate),
-				)
+	if countCreated < 1 {
+		initiateShutdown <- errors.New("no converter was started")
+	} else if cfg.LogWorkerStart() {
+		log.Printf("converter: %d converters created", countCreated)
+	}
+}
 
-				if err != nil {
-					log.Printf("converter[%s]mqtt[%s]: error: %s", converterConfig.Name(), mqttClientInstance.Name(), err)
-					continue
-				}
+// createConverter adds the routes of the given converter to the given mqtt clients
+// and returns the number of routes created
+func createConverter(
+	cfg *config.Config,
+	cc config.ConverterConfig,
+	mqttClients []mqttClient.Client,
+	statisticsInstance statistics.Statistics,
+	metricsInstance *metrics.Metrics,
+	errorHistoryInstance *converter.ErrorHistory,
+	localDbInstance LocalDb.LocalDb,
+	influxClientPoolInstance *influxClient.ClientPool,
+) (countCreated int) {
+	converterConfig := wrapConverterConfig(cc)
+	handleFunc, err := converter.GetHandler(converterConfig.Implementation())
+	if err != nil {
+		log.Printf("converter[%s]: cannot create: %s", converterConfig.Name(), err)
+		return
+	}
+
+	metricsInstance.AddConverter(converterConfig.Name())
+	errorHistoryInstance.AddConverter(converterConfig.Name(), converterConfig.ErrorHistorySize())
+	converter.ResetLoraFrameCounters(converterConfig.Name())
+
+	// iterate through all given mqtt clients
+	for _, mqttClientInstance := range mqttClients {
+		// iterate through all topics
+		for _, mqttTopic := range converterConfig.MqttTopics() {
+			topicMatcher, err := converter.CreateTopicMatcher(
+				mqttTopic.ApplyTopicReplace(mqttClientInstance.ReplaceTemplate),
+			)
+
+			if err != nil {
+				log.Printf("converter[%s]mqtt[%s]: error: %s", converterConfig.Name(), mqttClientInstance.Name(), err)
+				continue
+			}
 
-				mqttClientInstance.AddRoute(
+			mqttClientInstance.AddRoute(
+				converterConfig.Name(),
+				topicMatcher.GetSubscribeTopic(),
+				getMqttMessageHandler(
+					converterConfig, mqttClientInstance.Name(), topicMatcher, handleFunc,
+					statisticsInstance, metricsInstance, errorHistoryInstance, localDbInstance, influxClientPoolInstance,
+				),
+			)
+
+			if cfg.LogWorkerStart() {
+				log.Printf(
+					"converter[%s]: Implementation='%s', MqttClient='%s', InfluxClients=%v, SubscribeTopic='%s'",
+					converterConfig.Name(),
+					converterConfig.Implementation(),
+					mqttClientInstance.Name(),
+					influxClientPoolInstance.GetReceiverClientsNames(converterConfig.InfluxClients()),
 					topicMatcher.GetSubscribeTopic(),
-					getMqttMessageHandler(
-						converterConfig, topicMatcher, handleFunc, statisticsInstance, influxClientPoolInstance,
-					),
 				)
-
-				if cfg.LogWorkerStart() {
-					log.Printf(
-						"converter[%s]: Implementation='%s', MqttClient='%s', InfluxClients=%v, SubscribeTopic='%s'",
-						converterConfig.Name(),
-						converterConfig.Implementation(),
-						mqttClientInstance.Name(),
-						influxClientPoolInstance.GetReceiverClientsNames(converterConfig.InfluxClients()),
-						topicMatcher.GetSubscribeTopic(),
-					)
-				}
-
-				countCreated += 1
 			}
+
+			countCreated += 1
 		}
 	}
 
-	if countCreated < 1 {
-		initiateShutdown <- errors.New("no converter was started")
-	} else if cfg.LogWorkerStart() {
-		log.Printf("converter: %d converters created", countCreated)
+	return
+}
+
+// converterConfig wraps config.ConverterConfig to satisfy the converter.Config interface
+type converterConfig struct {
+	config.ConverterConfig
+	jsonMappings []converter.JsonMappingConfig
+}
+
+func wrapConverterConfig(c config.ConverterConfig) converterConfig {
+	// convert []config.JsonMappingConfig to []converter.JsonMappingConfig
+	jsonMappings := make([]converter.JsonMappingConfig, len(c.JsonMappings()))
+	for i, m := range c.JsonMappings() {
+		jsonMappings[i] = m
+	}
+
+	return converterConfig{
+		ConverterConfig: c,
+		jsonMappings:    jsonMappings,
 	}
 }
 
+func (c converterConfig) JsonMappings() []converter.JsonMappingConfig {
+	return c.jsonMappings
+}
+
 func getMqttMessageHandler(
 	config converter.Config,
+	mqttClientName string,
 	topicMatcher converter.TopicMatcher,
 	handleFunc converter.HandleFunc,
 	statisticsInstance statistics.Statistics,
+	metricsInstance *metrics.Metrics,
+	errorHistoryInstance *converter.ErrorHistory,
+	localDbInstance LocalDb.LocalDb,
 	influxClientPoolInstance *influxClient.ClientPool,
 ) mqttClient.MessageHandler {
 	return func(message mqttClient.Message) {
@@ -86,7 +152,8 @@ func getMqttMessageHandler(
 			converter.LogTopicOnce(config.Name(), message)
 		}
 		statisticsInstance.IncrementOne("converter", config.Name(), message.Topic())
-		handleFunc(
+		metricsInstance.ConverterMessageHandled(config.Name())
+		err := handleFunc(
 			config,
 			topicMatcher,
 			message,
@@ -97,5 +164,21 @@ func getMqttMessageHandler(
 				)
 			},
 		)
+		if err != nil {
+			log.Printf("%s[%s]: %s", config.Implementation(), config.Name(), err)
+			statisticsInstance.IncrementOne("converterError", config.Name(), message.Topic())
+			metricsInstance.ConverterError(config.Name())
+			errorHistoryInstance.Add(config.Name(), message, err)
+			if err := localDbInstance.DeadLetterAdd(LocalDb.DeadLetter{
+				Received:   time.Now(),
+				MqttClient: mqttClientName,
+				Converter:  config.Name(),
+				Topic:      message.Topic(),
+				Payload:    string(message.Payload()),
+				Error:      err.Error(),
+			}); err != nil {
+				log.Printf("localDb: cannot store dead letter: %s", err)
+			}
+		}
 	}
 }
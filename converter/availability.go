@@ -1,7 +1,7 @@
 package converter
 
 import (
-	"log"
+	"fmt"
 	"strings"
 	"time"
 )
@@ -21,15 +21,14 @@ func init() {
 // - piegn/tele/mezzo/zimmer-klein/LWT Offline
 // - piegn/tele/software/hass0/LWT Online
 // - piegn/tele/software/srv1-go-iotdevice/LWT Online
-func availabilityHandler(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) {
+func availabilityHandler(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) error {
 	// use our time
-	timeStamp := time.Now()
+	timeStamp := receiveTime(input)
 
 	// parse topic
 	device, err := tm.MatchDevice(input.Topic())
 	if err != nil {
-		log.Printf("availability[%s]: error: %s", c.Name(), err)
-		return
+		return fmt.Errorf("cannot extract device from topic='%s' err=%s", input.Topic(), err)
 	}
 
 	// parse payload
@@ -43,8 +42,7 @@ func availabilityHandler(c Config, tm TopicMatcher, input Input, outputFunc Outp
 	case "offline":
 		value = false
 	default:
-		log.Printf("availability[%s]: unknown value='%s'", c.Name(), input.Payload())
-		return
+		return fmt.Errorf("unknown value='%s'", input.Payload())
 	}
 
 	outputFunc(availabilityOutputMessage{
@@ -52,6 +50,8 @@ func availabilityHandler(c Config, tm TopicMatcher, input Input, outputFunc Outp
 		device:    device,
 		value:     value,
 	})
+
+	return nil
 }
 
 func (m availabilityOutputMessage) Measurement() string {
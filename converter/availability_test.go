@@ -41,11 +41,13 @@ func TestLwt(t *testing.T) {
 			Topic:             "piegn/tele/software/srv1-go-iotdevice/LWT",
 			Payload:           "invalid",
 			ExpectedLines:     []string{},
+			ExpectedError:     true,
 			ExpectedTimeStamp: now,
 		}, {
 			Topic:             "piegn/tele/software/srv1-go-iotdevice/LWT-invalid-topic",
 			Payload:           "Online",
 			ExpectedLines:     []string{},
+			ExpectedError:     true,
 			ExpectedTimeStamp: now,
 		},
 	}
@@ -0,0 +1,158 @@
+package converter
+
+import (
+	"fmt"
+	jsoniter "github.com/json-iterator/go"
+	"strings"
+	"time"
+)
+
+// chirpstackUpMessage is the uplink event published by the ChirpStack v4 mqtt integration
+// on application/<applicationId>/device/<devEui>/event/up
+type chirpstackUpMessage struct {
+	Time       *time.Time `json:"time"`
+	DeviceInfo struct {
+		DeviceProfileName string            `json:"deviceProfileName"`
+		DeviceName        string            `json:"deviceName"`
+		DevEui            string            `json:"devEui"`
+		Tags              map[string]string `json:"tags"`
+	} `json:"deviceInfo"`
+	FCnt   int64 `json:"fCnt"`
+	FPort  int64 `json:"fPort"`
+	RxInfo []struct {
+		GatewayId string  `json:"gatewayId"`
+		Rssi      int64   `json:"rssi"`
+		Snr       float64 `json:"snr"`
+	} `json:"rxInfo"`
+	TxInfo struct {
+		Frequency  int64 `json:"frequency"`
+		Modulation struct {
+			Lora struct {
+				Bandwidth       int64 `json:"bandwidth"`
+				SpreadingFactor int64 `json:"spreadingFactor"`
+			} `json:"lora"`
+		} `json:"modulation"`
+	} `json:"txInfo"`
+	Object jsoniter.RawMessage `json:"object"`
+}
+
+func init() {
+	registerHandler("chirpstack", chirpstackHandler)
+}
+
+func chirpstackHandler(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) error {
+	// parse topic
+	device, err := tm.MatchDevice(input.Topic())
+	if err != nil {
+		return fmt.Errorf("cannot extract device from topic='%s' err=%s", input.Topic(), err)
+	}
+
+	// parse payload
+	var message chirpstackUpMessage
+	if err := json.Unmarshal(input.Payload(), &message); err != nil {
+		return fmt.Errorf("cannot json decode: %s", err)
+	}
+
+	// the device name is used instead of the devEui given by the topic
+	if message.DeviceInfo.DeviceName != "" {
+		device = message.DeviceInfo.DeviceName
+	}
+
+	timeStamp := receiveTime(input)
+	if message.Time != nil {
+		timeStamp = *message.Time
+	}
+
+	// save general lora data
+	for gatewayIdx, rx := range message.RxInfo {
+		outputFunc(loraOutputMessage{
+			timeStamp:  timeStamp,
+			device:     device,
+			devEui:     message.DeviceInfo.DevEui,
+			gatewayId:  rx.GatewayId,
+			gatewayEui: rx.GatewayId,
+			rssi:       rx.Rssi,
+			// chirpstack only reports a single rssi per gateway
+			channelRssi:     rx.Rssi,
+			snr:             rx.Snr,
+			gatewayIdx:      gatewayIdx,
+			fCnt:            message.FCnt,
+			fPort:           message.FPort,
+			spreadingFactor: message.TxInfo.Modulation.Lora.SpreadingFactor,
+			bandwidth:       message.TxInfo.Modulation.Lora.Bandwidth,
+			frequency:       message.TxInfo.Frequency,
+		})
+	}
+
+	// save frame counter based statistics
+	missed, rejoin := loraFrameCounterUpdate(c.Name(), message.DeviceInfo.DevEui, message.FCnt)
+	outputFunc(loraUplinkOutputMessage{
+		timeStamp:     timeStamp,
+		device:        device,
+		devEui:        message.DeviceInfo.DevEui,
+		fCnt:          message.FCnt,
+		missedUplinks: missed,
+		rejoin:        rejoin,
+		gatewayCount:  len(message.RxInfo),
+	})
+
+	if len(message.Object) < 1 {
+		return fmt.Errorf("no object present, make sure a codec is configured in the device profile='%s'",
+			message.DeviceInfo.DeviceProfileName,
+		)
+	}
+
+	// the model_id and brand_id tags correspond to the version_ids sent by ttn and are preferred,
+	// e.g. model_id=lht65 instead of a device profile name like "Dragino LHT65 EU868"
+	model := message.DeviceInfo.DeviceProfileName
+	if modelId := message.DeviceInfo.Tags["model_id"]; modelId != "" {
+		model = modelId
+	}
+	brand := message.DeviceInfo.Tags["brand_id"]
+	if brand == "" {
+		brand = chirpstackBrand(message.DeviceInfo.DeviceProfileName, message.Object)
+	}
+
+	switch brand {
+	case "dragino":
+		return ttnDraginoHandler(c, device, model, timeStamp, message.Object, outputFunc)
+	case "sensecap":
+		return ttnSensecapHandler(c, device, model, timeStamp, message.Object, outputFunc)
+	case "fencyboy":
+		return ttnFencyboyHandler(c, device, model, timeStamp, message.Object, outputFunc)
+	default:
+		if genericFallbackEnabled(c) {
+			return ttnGenericHandler(c, device, model, timeStamp, message.Object, outputFunc)
+		}
+		return fmt.Errorf("no decoder for device profile='%s'", model)
+	}
+}
+
+// chirpstackBrand selects the decoder by the device profile name
+// and falls back to the keys present in the codec output
+func chirpstackBrand(deviceProfileName string, object []byte) string {
+	name := strings.ToLower(deviceProfileName)
+	for _, brand := range []string{"dragino", "sensecap", "fencyboy"} {
+		if strings.Contains(name, brand) {
+			return brand
+		}
+	}
+
+	var keys map[string]interface{}
+	if err := json.Unmarshal(object, &keys); err != nil {
+		return ""
+	}
+	has := func(key string) bool {
+		_, ok := keys[key]
+		return ok
+	}
+	switch {
+	case has("messages"):
+		return "sensecap"
+	case has("FENCEVOLTAGE"):
+		return "fencyboy"
+	case has("BatV"), has("Bat_mV"):
+		return "dragino"
+	}
+	return ""
+}
@@ -0,0 +1,178 @@
+package converter
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/koestler/go-mqtt-to-influx/v2/converter/mock"
+	"testing"
+	"time"
+)
+
+func TestChirpstack(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	ResetLoraFrameCounters("test-converter")
+
+	mockConfig := converter_mock.NewMockConfig(mockCtrl)
+	mockConfig.EXPECT().Name().Return("test-converter").AnyTimes()
+
+	mockTMConfig := converter_mock.NewMockTopicMatcherConfig(mockCtrl)
+	mockTMConfig.EXPECT().Topic().Return("application/%Device%/event/up").AnyTimes()
+	mockTMConfig.EXPECT().Device().Return("+/device/+").AnyTimes()
+	mockTMConfig.EXPECT().DeviceIsDynamic().Return(true).AnyTimes()
+
+	stimuli := TestStimuliResponse{
+		{
+			Topic: "application/2b5b3f0c-6c6e-4d2a-9c9a-7f3e1c0a1b2c/device/2cf7f1c0443003dd/event/up",
+			Payload: `{
+  "deduplicationId": "3d7d9ee7-4a1a-4b1b-8c1c-0c4b2b4f1a6e",
+  "time": "2025-03-08T10:15:42.123456Z",
+  "deviceInfo": {
+    "tenantId": "52f14cd4-c6f1-4fbd-8f87-4025e1d49242",
+    "tenantName": "ChirpStack",
+    "applicationId": "2b5b3f0c-6c6e-4d2a-9c9a-7f3e1c0a1b2c",
+    "applicationName": "weather",
+    "deviceProfileId": "8a4d2c4e-9c3b-4b6e-8f0e-2b7c1d0e5a3f",
+    "deviceProfileName": "SenseCAP S2120",
+    "deviceName": "s2120-0",
+    "devEui": "2cf7f1c0443003dd",
+    "deviceClassEnabled": "CLASS_A",
+    "tags": {}
+  },
+  "devAddr": "01f2a3b4",
+  "adr": true,
+  "dr": 5,
+  "fCnt": 1042,
+  "fPort": 3,
+  "confirmed": true,
+  "data": "AQBBQgAAAAAAAAACAE8AAAAAJdw=",
+  "object": {
+    "err": 0,
+    "messages": [
+      {"measurementId": "4097", "measurementValue": 6.5, "type": "Air Temperature"},
+      {"measurementId": "4098", "measurementValue": 66, "type": "Air Humidity"}
+    ],
+    "payload": "010041420000000000000002004F000000002",
+    "valid": true
+  },
+  "rxInfo": [
+    {
+      "gatewayId": "e45f01fffedecbe3",
+      "uplinkId": 31245,
+      "nsTime": "2025-03-08T10:15:42.140Z",
+      "rssi": -80,
+      "snr": 6.5,
+      "channel": 4,
+      "location": {},
+      "context": "AAAAAAAAAAAAAAAA",
+      "crcStatus": "CRC_OK"
+    },
+    {
+      "gatewayId": "0016c001f153a14c",
+      "uplinkId": 9921,
+      "rssi": -112,
+      "snr": -7.25,
+      "channel": 4,
+      "crcStatus": "CRC_OK"
+    }
+  ],
+  "txInfo": {
+    "frequency": 867300000,
+    "modulation": {"lora": {"bandwidth": 125000, "spreadingFactor": 7, "codeRate": "CR_4_5"}}
+  }
+}`,
+			ExpectedLines: []string{
+				"lora,devEui=2cf7f1c0443003dd,device=s2120-0,gatewayEui=e45f01fffedecbe3,gatewayId=e45f01fffedecbe3 bandwidth=125000i,channelRssi=-80i,consumedAirtimeUs=0i,fCnt=1042i,fPort=3i,frequency=867300000i,gatewayIdx=0i,rssi=-80i,snr=6.5,spreadingFactor=7i",
+				"loraUplink,devEui=2cf7f1c0443003dd,device=s2120-0 fCnt=1042i,gatewayCount=2i,missedUplinks=0i,rejoin=false",
+				"lora,devEui=2cf7f1c0443003dd,device=s2120-0,gatewayEui=0016c001f153a14c,gatewayId=0016c001f153a14c bandwidth=125000i,channelRssi=-112i,consumedAirtimeUs=0i,fCnt=1042i,fPort=3i,frequency=867300000i,gatewayIdx=1i,rssi=-112i,snr=-7.25,spreadingFactor=7i",
+				"telemetry,device=s2120-0,field=Air\\ Temperature,sensor=SenseCAP\\ S2120,unit=°C floatValue=6.5",
+				"telemetry,device=s2120-0,field=Air\\ Humidity,sensor=SenseCAP\\ S2120,unit=%\\ RH floatValue=66",
+			},
+			ExpectedTimeStamp: time.Date(2025, time.March, 8, 10, 15, 42, 123456000, time.UTC),
+		}, {
+			// no brand in the device profile name, the decoder is chosen by the codec output
+			Topic: "application/2b5b3f0c-6c6e-4d2a-9c9a-7f3e1c0a1b2c/device/a84041000181c061/event/up",
+			Payload: `{
+  "time": "2025-03-08T10:20:00Z",
+  "deviceInfo": {"deviceProfileName": "LHT65N", "deviceName": "cellar", "devEui": "a84041000181c061"},
+  "object": {"BatV": 3.05, "Hum_SHT": 71.3, "TempC_DS": 12.25},
+  "rxInfo": [{"gatewayId": "e45f01fffedecbe3", "rssi": -95, "snr": 2}]
+}`,
+			ExpectedLines: []string{
+				"lora,devEui=a84041000181c061,device=cellar,gatewayEui=e45f01fffedecbe3,gatewayId=e45f01fffedecbe3 channelRssi=-95i,consumedAirtimeUs=0i,fCnt=0i,fPort=0i,gatewayIdx=0i,rssi=-95i,snr=2",
+				"loraUplink,devEui=a84041000181c061,device=cellar fCnt=0i,gatewayCount=1i,missedUplinks=0i,rejoin=false",
+				"telemetry,device=cellar,field=BatV,sensor=LHT65N,unit=V floatValue=3.05",
+				"telemetry,device=cellar,field=HumSHT,sensor=LHT65N,unit=% floatValue=71.3",
+				"telemetry,device=cellar,field=TempCDs,sensor=LHT65N,unit=°C floatValue=12.25",
+			},
+			ExpectedTimeStamp: time.Date(2025, time.March, 8, 10, 20, 0, 0, time.UTC),
+		}, {
+			// the model_id tag is used as sensor tag like the model_id of the version_ids sent by ttn
+			Topic: "application/2b5b3f0c-6c6e-4d2a-9c9a-7f3e1c0a1b2c/device/a84041000181c062/event/up",
+			Payload: `{
+  "time": "2025-03-08T10:20:00Z",
+  "deviceInfo": {
+    "deviceProfileName": "Dragino LHT65 EU868",
+    "deviceName": "attic",
+    "devEui": "a84041000181c062",
+    "tags": {"brand_id": "dragino", "model_id": "lht65"}
+  },
+  "object": {"BatV": 3.1, "TempC_DS": 18.5},
+  "rxInfo": []
+}`,
+			ExpectedLines: []string{
+				"loraUplink,devEui=a84041000181c062,device=attic fCnt=0i,gatewayCount=0i,missedUplinks=0i,rejoin=false",
+				"telemetry,device=attic,field=BatV,sensor=lht65,unit=V floatValue=3.1",
+				"telemetry,device=attic,field=TempCDs,sensor=lht65,unit=°C floatValue=18.5",
+			},
+			ExpectedTimeStamp: time.Date(2025, time.March, 8, 10, 20, 0, 0, time.UTC),
+		}, {
+			Topic: "application/2b5b3f0c-6c6e-4d2a-9c9a-7f3e1c0a1b2c/device/0000000000000001/event/up",
+			Payload: `{
+  "time": "2025-03-08T10:20:00Z",
+  "deviceInfo": {"deviceProfileName": "unknown", "deviceName": "other", "devEui": "0000000000000001"},
+  "fCnt": 10,
+  "object": {"foo": 1},
+  "rxInfo": []
+}`,
+			ExpectedLines: []string{
+				"loraUplink,devEui=0000000000000001,device=other fCnt=10i,gatewayCount=0i,missedUplinks=0i,rejoin=false",
+			},
+			ExpectedTimeStamp: time.Date(2025, time.March, 8, 10, 20, 0, 0, time.UTC),
+			ExpectedError:     true,
+		}, {
+			Topic: "application/2b5b3f0c-6c6e-4d2a-9c9a-7f3e1c0a1b2c/device/0000000000000001/event/up",
+			Payload: `{
+  "time": "2025-03-08T10:20:00Z",
+  "deviceInfo": {"deviceProfileName": "no codec", "deviceName": "other", "devEui": "0000000000000001"},
+  "fCnt": 14,
+  "fPort": 1,
+  "rxInfo": [{"gatewayId": "e45f01fffedecbe3", "rssi": -95, "snr": 2}]
+}`,
+			ExpectedLines: []string{
+				"lora,devEui=0000000000000001,device=other,gatewayEui=e45f01fffedecbe3,gatewayId=e45f01fffedecbe3 channelRssi=-95i,consumedAirtimeUs=0i,fCnt=14i,fPort=1i,gatewayIdx=0i,rssi=-95i,snr=2",
+				// 3 uplinks are missing between 10 and 14
+				"loraUplink,devEui=0000000000000001,device=other fCnt=14i,gatewayCount=1i,missedUplinks=3i,rejoin=false",
+			},
+			ExpectedTimeStamp: time.Date(2025, time.March, 8, 10, 20, 0, 0, time.UTC),
+			ExpectedError:     true,
+		}, {
+			Topic:             "application/2b5b3f0c/device/0000000000000001/event/up",
+			Payload:           "invalid",
+			ExpectedLines:     []string{},
+			ExpectedTimeStamp: time.Now(),
+			ExpectedError:     true,
+		}, {
+			Topic:             "invalid",
+			Payload:           "",
+			ExpectedLines:     []string{},
+			ExpectedTimeStamp: time.Now(),
+			ExpectedError:     true,
+		},
+	}
+
+	if h, err := GetHandler("chirpstack"); err != nil {
+		t.Errorf("did not expect an error while getting handler: %s", err)
+	} else {
+		testStimuliResponse(t, mockCtrl, mockConfig, mockTMConfig, h, stimuli)
+	}
+}
@@ -13,11 +13,49 @@ type Config interface {
 	LogDebug() bool
 }
 
+// JsonMappingsConfig is implemented by the Config given to the json-mapping implementation
+type JsonMappingsConfig interface {
+	JsonMappings() []JsonMappingConfig
+}
+
+// GenericFallbackConfig is implemented by the Config given to the ttn and chirpstack implementations
+type GenericFallbackConfig interface {
+	GenericFallback() bool
+}
+
+// LineProtocolConfig is implemented by the Config given to the influx-line implementation
+type LineProtocolConfig interface {
+	Precision() time.Duration
+	AddDeviceTag() bool
+}
+
+type JsonMappingConfig interface {
+	Path() string
+	Field() string
+	Unit() string
+	ValueType() string
+	Sensor() string
+	TimePath() string
+}
+
 type Input interface {
 	Topic() string
 	Payload() []byte
 }
 
+// TimedInput is implemented by inputs which were not received just now, e.g. when messages are replayed
+type TimedInput interface {
+	ReceivedAt() time.Time
+}
+
+// receiveTime returns the time the given input was received at
+func receiveTime(input Input) time.Time {
+	if t, ok := input.(TimedInput); ok {
+		return t.ReceivedAt()
+	}
+	return time.Now()
+}
+
 type Output interface {
 	Measurement() string
 	Tags() map[string]string
@@ -26,7 +64,10 @@ type Output interface {
 }
 
 type OutputFunc func(output Output)
-type HandleFunc func(c Config, topicMatcher TopicMatcher, input Input, outputFunc OutputFunc)
+
+// HandleFunc parses the given input and calls outputFunc once per point;
+// an error is returned when the input cannot be used at all, e.g. when it cannot be decoded
+type HandleFunc func(c Config, topicMatcher TopicMatcher, input Input, outputFunc OutputFunc) error
 
 var converterImplementations = make(map[string]HandleFunc)
 
@@ -38,6 +38,7 @@ type TestStimuliResponse []struct {
 	Payload           string
 	ExpectedTimeStamp time.Time
 	ExpectedLines     []string
+	ExpectedError     bool
 }
 
 func testStimuliResponse(
@@ -80,7 +81,12 @@ func testStimuliResponse(
 				t.Errorf("expect timestamp to %s but got %s", s.ExpectedTimeStamp, output.Time())
 			}
 		}
-		dut(config, tm, mockInput, outputTestFunc)
+		err := dut(config, tm, mockInput, outputTestFunc)
+		if s.ExpectedError && err == nil {
+			t.Errorf("expected an error")
+		} else if !s.ExpectedError && err != nil {
+			t.Errorf("did not expect an error, got: %s", err)
+		}
 
 		// sort strings before comparison
 		sort.Strings(s.ExpectedLines)
@@ -117,6 +123,6 @@ func TestRegisterTwice(t *testing.T) {
 		}
 	}()
 
-	registerHandler("empty", func(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) {})
-	registerHandler("empty", func(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) {})
+	registerHandler("empty", func(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) error { return nil })
+	registerHandler("empty", func(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) error { return nil })
 }
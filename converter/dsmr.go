@@ -0,0 +1,279 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"github.com/pkg/errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dsmrObis defines the field names and default units of the known OBIS codes; the unit sent in the telegram takes precedence
+var dsmrObis = map[string]struct {
+	field string
+	unit  string
+}{
+	"1-0:1.8.0":   {"EnergyDelivered", "kWh"},
+	"1-0:1.8.1":   {"EnergyDeliveredTariff1", "kWh"},
+	"1-0:1.8.2":   {"EnergyDeliveredTariff2", "kWh"},
+	"1-0:2.8.0":   {"EnergyReturned", "kWh"},
+	"1-0:2.8.1":   {"EnergyReturnedTariff1", "kWh"},
+	"1-0:2.8.2":   {"EnergyReturnedTariff2", "kWh"},
+	"0-0:96.14.0": {"ElectricityTariff", ""},
+	"1-0:1.7.0":   {"PowerDelivered", "kW"},
+	"1-0:2.7.0":   {"PowerReturned", "kW"},
+	"1-0:21.7.0":  {"PowerDeliveredL1", "kW"},
+	"1-0:41.7.0":  {"PowerDeliveredL2", "kW"},
+	"1-0:61.7.0":  {"PowerDeliveredL3", "kW"},
+	"1-0:22.7.0":  {"PowerReturnedL1", "kW"},
+	"1-0:42.7.0":  {"PowerReturnedL2", "kW"},
+	"1-0:62.7.0":  {"PowerReturnedL3", "kW"},
+	"1-0:32.7.0":  {"VoltageL1", "V"},
+	"1-0:52.7.0":  {"VoltageL2", "V"},
+	"1-0:72.7.0":  {"VoltageL3", "V"},
+	"1-0:31.7.0":  {"CurrentL1", "A"},
+	"1-0:51.7.0":  {"CurrentL2", "A"},
+	"1-0:71.7.0":  {"CurrentL3", "A"},
+	"0-0:96.7.21": {"PowerFailures", ""},
+	"0-0:96.7.9":  {"LongPowerFailures", ""},
+	"1-0:32.32.0": {"VoltageSagsL1", ""},
+	"1-0:52.32.0": {"VoltageSagsL2", ""},
+	"1-0:72.32.0": {"VoltageSagsL3", ""},
+	"1-0:32.36.0": {"VoltageSwellsL1", ""},
+	"1-0:52.36.0": {"VoltageSwellsL2", ""},
+	"1-0:72.36.0": {"VoltageSwellsL3", ""},
+}
+
+// dsmrMbusDeviceTypes defines the names of the devices connected by M-Bus, e.g. 0-1:24.1.0(003)
+var dsmrMbusDeviceTypes = map[string]string{
+	"003": "Gas",
+	"004": "Heat",
+	"007": "Water",
+}
+
+// example: 1-0:1.8.1(001234.567*kWh) or 0-1:24.2.1(101209112500W)(12785.123*m3)
+var dsmrLineMatcher = regexp.MustCompile(`^(\d+-\d+:\d+\.\d+\.\d+)((?:\([^()]*\))+)$`)
+var dsmrValueMatcher = regexp.MustCompile(`\(([^()]*)\)`)
+
+func init() {
+	registerHandler("dsmr", dsmrHandler)
+}
+
+// parses raw DSMR / P1 telegrams of smart meters, e.g.
+//
+//	/ISk5\2MT382-1000
+//
+//	1-3:0.2.8(50)
+//	0-0:1.0.0(101209113020W)
+//	0-0:96.1.1(4B384547303034303436333935353037)
+//	1-0:1.8.1(123456.789*kWh)
+//	1-0:1.7.0(01.193*kW)
+//	0-1:24.1.0(003)
+//	0-1:96.1.0(3232323241424344313233343536373839)
+//	0-1:24.2.1(101209112500W)(12785.123*m3)
+//	!EF2F
+//
+// The CRC16 is validated when present (DSMR 4 and later). The equipment id of the meter is used as sensor.
+func dsmrHandler(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) error {
+	// parse topic
+	device, err := tm.MatchDevice(input.Topic())
+	if err != nil {
+		return fmt.Errorf("cannot extract device from topic='%s' err=%s", input.Topic(), err)
+	}
+
+	// check telegram
+	telegram, err := dsmrCheckTelegram(input.Payload())
+	if err != nil {
+		return err
+	}
+
+	// parse lines
+	values := make(map[string][]string)
+	var obisCodes []string
+	for _, line := range strings.Split(string(telegram), "\n") {
+		matches := dsmrLineMatcher.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil {
+			// header, empty lines and the footer
+			continue
+		}
+		var v []string
+		for _, m := range dsmrValueMatcher.FindAllStringSubmatch(matches[2], -1) {
+			v = append(v, m[1])
+		}
+		obisCodes = append(obisCodes, matches[1])
+		values[matches[1]] = v
+	}
+
+	// use the time of the telegram when given, otherwise our time
+	timeStamp := receiveTime(input)
+	if v, ok := values["0-0:1.0.0"]; ok {
+		t, err := dsmrParseTime(v[0])
+		if err != nil {
+			return err
+		}
+		timeStamp = t
+	}
+
+	sensor := "dsmr"
+	if v, ok := values["0-0:96.1.1"]; ok {
+		sensor = dsmrDecodeEquipmentId(v[0])
+	}
+
+	// send points
+	count := 0
+	output := func(timeStamp time.Time, field, sensor, value, unit string) error {
+		m := telemetryOutputMessage{
+			timeStamp: timeStamp,
+			device:    device,
+			field:     field,
+			sensor:    sensor,
+		}
+
+		if number, u, ok := strings.Cut(value, "*"); ok {
+			value, unit = number, u
+		}
+		if unit != "" {
+			m.unit = &unit
+		}
+
+		if strings.Contains(value, ".") {
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("cannot parse value='%s' of field='%s': %s", value, field, err)
+			}
+			m.floatValue = &v
+		} else {
+			v, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("cannot parse value='%s' of field='%s': %s", value, field, err)
+			}
+			m.intValue = &v
+		}
+
+		count += 1
+		outputFunc(m)
+		return nil
+	}
+
+	for _, obis := range obisCodes {
+		v := values[obis]
+
+		if o, ok := dsmrObis[obis]; ok {
+			if err := output(timeStamp, o.field, sensor, v[0], o.unit); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// M-Bus devices like gas meters send their last reading with its own time, e.g. 0-1:24.2.1(101209112500W)(12785.123*m3)
+		channel, ok := strings.CutSuffix(obis, ":24.2.1")
+		if !ok {
+			channel, ok = strings.CutSuffix(obis, ":24.2.3")
+		}
+		if !ok || len(v) < 2 {
+			continue
+		}
+		t, err := dsmrParseTime(v[0])
+		if err != nil {
+			return err
+		}
+		name := "Gas"
+		if deviceType, ok := values[channel+":24.1.0"]; ok {
+			if n, ok := dsmrMbusDeviceTypes[deviceType[0]]; ok {
+				name = n
+			}
+		}
+		mbusSensor := sensor
+		if id, ok := values[channel+":96.1.0"]; ok {
+			mbusSensor = dsmrDecodeEquipmentId(id[0])
+		}
+		if err := output(t, name+"Delivered", mbusSensor, v[1], ""); err != nil {
+			return err
+		}
+	}
+
+	// any points sent?
+	if count < 1 {
+		return errors.New("could not extract any sensor data")
+	}
+
+	return nil
+}
+
+// dsmrCheckTelegram returns the telegram from the / of the header up to the ! of the footer
+// and validates the CRC16 following the ! when present
+func dsmrCheckTelegram(payload []byte) ([]byte, error) {
+	start := bytes.IndexByte(payload, '/')
+	end := bytes.LastIndexByte(payload, '!')
+	if start < 0 || end < start {
+		return nil, errors.New("cannot find header and footer of telegram")
+	}
+	telegram := payload[start : end+1]
+
+	crc := strings.TrimSpace(string(payload[end+1:]))
+	if crc == "" {
+		// DSMR 2 and 3 do not send a checksum
+		return telegram, nil
+	}
+	expected, err := strconv.ParseUint(crc, 16, 16)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse crc='%s': %s", crc, err)
+	}
+	if actual := dsmrCrc16(telegram); uint16(expected) != actual {
+		return nil, fmt.Errorf("crc mismatch: telegram has %04X, computed %04X", expected, actual)
+	}
+	return telegram, nil
+}
+
+// dsmrCrc16 computes the CRC16 (polynomial 0xA001, reversed, initial value 0) used by DSMR
+func dsmrCrc16(data []byte) (crc uint16) {
+	for _, b := range data {
+		crc ^= uint16(b)
+		for i := 0; i < 8; i++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0xA001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return
+}
+
+// dsmrParseTime parses times like 101209113020W; W denotes winter time (CET) and S summer time (CEST)
+func dsmrParseTime(v string) (time.Time, error) {
+	if len(v) != 13 {
+		return time.Time{}, fmt.Errorf("cannot parse time='%s': expect format YYMMDDhhmmssX", v)
+	}
+	var offset int
+	switch v[12] {
+	case 'W':
+		offset = 1
+	case 'S':
+		offset = 2
+	default:
+		return time.Time{}, fmt.Errorf("cannot parse time='%s': expect W or S as last character", v)
+	}
+	t, err := time.ParseInLocation("060102150405", v[:12], time.FixedZone("", offset*60*60))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot parse time='%s': %s", v, err)
+	}
+	return t, nil
+}
+
+// dsmrDecodeEquipmentId decodes equipment ids sent as hex encoded ascii; other ids are returned unchanged
+func dsmrDecodeEquipmentId(v string) string {
+	b, err := hex.DecodeString(v)
+	if err != nil || len(b) < 1 {
+		return v
+	}
+	for _, c := range b {
+		if c < 0x20 || c > 0x7e {
+			return v
+		}
+	}
+	return string(b)
+}
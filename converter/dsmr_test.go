@@ -0,0 +1,119 @@
+package converter
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/koestler/go-mqtt-to-influx/v2/converter/mock"
+	"testing"
+	"time"
+)
+
+func TestDsmr(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockConfig := converter_mock.NewMockConfig(mockCtrl)
+	mockConfig.EXPECT().Name().Return("test-converter").AnyTimes()
+
+	mockTMConfig := converter_mock.NewMockTopicMatcherConfig(mockCtrl)
+	mockTMConfig.EXPECT().Topic().Return("dsmr/%Device%/telegram").AnyTimes()
+	mockTMConfig.EXPECT().Device().Return("+").AnyTimes()
+	mockTMConfig.EXPECT().DeviceIsDynamic().Return(true).AnyTimes()
+
+	now := time.Now()
+
+	stimuli := TestStimuliResponse{
+		{
+			Topic: "dsmr/house-1/telegram",
+			Payload: "/ISk5\\2MT382-1000\r\n" +
+				"\r\n" +
+				"1-3:0.2.8(50)\r\n" +
+				"0-0:1.0.0(250502180000S)\r\n" +
+				"0-0:96.1.1(4B384547303034303436333935353037)\r\n" +
+				"1-0:1.8.1(001234.567*kWh)\r\n" +
+				"1-0:1.8.2(002345.678*kWh)\r\n" +
+				"1-0:2.8.1(000012.345*kWh)\r\n" +
+				"1-0:2.8.2(000023.456*kWh)\r\n" +
+				"0-0:96.14.0(0002)\r\n" +
+				"1-0:1.7.0(01.193*kW)\r\n" +
+				"1-0:2.7.0(00.000*kW)\r\n" +
+				"0-0:96.7.21(00004)\r\n" +
+				"0-0:96.13.0()\r\n" +
+				"1-0:32.7.0(230.1*V)\r\n" +
+				"1-0:31.7.0(005*A)\r\n" +
+				"!30BA\r\n",
+			ExpectedLines: []string{
+				"telemetry,device=house-1,field=EnergyDeliveredTariff1,sensor=K8EG004046395507,unit=kWh floatValue=1234.567",
+				"telemetry,device=house-1,field=EnergyDeliveredTariff2,sensor=K8EG004046395507,unit=kWh floatValue=2345.678",
+				"telemetry,device=house-1,field=EnergyReturnedTariff1,sensor=K8EG004046395507,unit=kWh floatValue=12.345",
+				"telemetry,device=house-1,field=EnergyReturnedTariff2,sensor=K8EG004046395507,unit=kWh floatValue=23.456",
+				"telemetry,device=house-1,field=ElectricityTariff,sensor=K8EG004046395507 intValue=2i",
+				"telemetry,device=house-1,field=PowerDelivered,sensor=K8EG004046395507,unit=kW floatValue=1.193",
+				"telemetry,device=house-1,field=PowerReturned,sensor=K8EG004046395507,unit=kW floatValue=0",
+				"telemetry,device=house-1,field=PowerFailures,sensor=K8EG004046395507 intValue=4i",
+				"telemetry,device=house-1,field=VoltageL1,sensor=K8EG004046395507,unit=V floatValue=230.1",
+				"telemetry,device=house-1,field=CurrentL1,sensor=K8EG004046395507,unit=A intValue=5i",
+			},
+			ExpectedTimeStamp: time.Date(2025, time.May, 2, 16, 0, 0, 0, time.UTC),
+		}, {
+			// gas with its own timestamp
+			Topic: "dsmr/house-1/telegram",
+			Payload: "/ISk5\\2MT382-1000\r\n" +
+				"\r\n" +
+				"0-0:96.1.1(4B384547303034303436333935353037)\r\n" +
+				"0-1:24.1.0(003)\r\n" +
+				"0-1:96.1.0(4730303332353631323831363736323135)\r\n" +
+				"0-1:24.2.1(250502175500S)(01234.567*m3)\r\n" +
+				"!20A8\r\n",
+			ExpectedLines: []string{
+				"telemetry,device=house-1,field=GasDelivered,sensor=G0032561281676215,unit=m3 floatValue=1234.567",
+			},
+			ExpectedTimeStamp: time.Date(2025, time.May, 2, 15, 55, 0, 0, time.UTC),
+		}, {
+			// DSMR 2.2 without crc and equipment id
+			Topic: "dsmr/house-2/telegram",
+			Payload: "/KFM5KAIFA-METER\r\n" +
+				"\r\n" +
+				"1-0:1.8.1(00185.000*kWh)\r\n" +
+				"1-0:1.7.0(0000.98*kW)\r\n" +
+				"!\r\n",
+			ExpectedLines: []string{
+				"telemetry,device=house-2,field=EnergyDeliveredTariff1,sensor=dsmr,unit=kWh floatValue=185",
+				"telemetry,device=house-2,field=PowerDelivered,sensor=dsmr,unit=kW floatValue=0.98",
+			},
+			ExpectedTimeStamp: now,
+		}, {
+			// wrong crc
+			Topic: "dsmr/house-1/telegram",
+			Payload: "/ISk5\\2MT382-1000\r\n" +
+				"\r\n" +
+				"0-0:96.1.1(4B384547303034303436333935353037)\r\n" +
+				"0-1:24.1.0(003)\r\n" +
+				"0-1:96.1.0(4730303332353631323831363736323135)\r\n" +
+				"0-1:24.2.1(250502175500S)(01234.568*m3)\r\n" +
+				"!20A8\r\n",
+			ExpectedLines:     []string{},
+			ExpectedError:     true,
+			ExpectedTimeStamp: now,
+		}, {
+			// incomplete
+			Topic:             "dsmr/house-1/telegram",
+			Payload:           "/ISk5\\2MT382-1000\r\n\r\n1-0:1.8.1(001234.567*kWh)\r\n",
+			ExpectedLines:     []string{},
+			ExpectedError:     true,
+			ExpectedTimeStamp: now,
+		}, {
+			// no known obis code
+			Topic:             "dsmr/house-1/telegram",
+			Payload:           "/ISk5\\2MT382-1000\r\n\r\n0-0:96.13.0()\r\n!00EC\r\n",
+			ExpectedLines:     []string{},
+			ExpectedError:     true,
+			ExpectedTimeStamp: now,
+		},
+	}
+
+	if h, err := GetHandler("dsmr"); err != nil {
+		t.Errorf("did not expect an error while getting handler: %s", err)
+	} else {
+		testStimuliResponse(t, mockCtrl, mockConfig, mockTMConfig, h, stimuli)
+	}
+}
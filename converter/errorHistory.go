@@ -0,0 +1,128 @@
+package converter
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxErrorPayloadLength limits the memory used by a single entry; longer payloads are truncated
+const maxErrorPayloadLength = 4096
+
+// ErrorHistory keeps the last inputs per converter which could not be handled to allow debugging
+// them using the http server
+type ErrorHistory struct {
+	mutex      sync.RWMutex
+	converters map[string]*errorRing
+}
+
+type ErrorHistoryEntry struct {
+	Time      time.Time `json:"time"`
+	Topic     string    `json:"topic"`
+	Payload   string    `json:"payload"`
+	Truncated bool      `json:"truncated,omitempty"`
+	Error     string    `json:"error"`
+}
+
+type errorRing struct {
+	entries []ErrorHistoryEntry
+	next    int
+	count   int
+}
+
+func NewErrorHistory() *ErrorHistory {
+	return &ErrorHistory{
+		converters: make(map[string]*errorRing),
+	}
+}
+
+// AddConverter sets up a history keeping the last size entries for the given converter; size 0 disables it
+func (h *ErrorHistory) AddConverter(converter string, size int) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if size < 1 {
+		delete(h.converters, converter)
+		return
+	}
+	if ring, ok := h.converters[converter]; ok && len(ring.entries) == size {
+		// keep the entries when the converter is recreated during a configuration reload
+		return
+	}
+	h.converters[converter] = &errorRing{
+		entries: make([]ErrorHistoryEntry, size),
+	}
+}
+
+func (h *ErrorHistory) Add(converter string, input Input, err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	ring, ok := h.converters[converter]
+	if !ok {
+		return
+	}
+
+	payload := input.Payload()
+	truncated := len(payload) > maxErrorPayloadLength
+	if truncated {
+		payload = payload[:maxErrorPayloadLength]
+	}
+
+	ring.entries[ring.next] = ErrorHistoryEntry{
+		Time:      receiveTime(input),
+		Topic:     input.Topic(),
+		Payload:   string(payload),
+		Truncated: truncated,
+		Error:     err.Error(),
+	}
+	ring.next = (ring.next + 1) % len(ring.entries)
+	if ring.count < len(ring.entries) {
+		ring.count += 1
+	}
+}
+
+// Get returns the stored entries of the given converter, newest first
+func (h *ErrorHistory) Get(converter string) (entries []ErrorHistoryEntry, ok bool) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	ring, ok := h.converters[converter]
+	if !ok {
+		return nil, false
+	}
+
+	entries = make([]ErrorHistoryEntry, 0, ring.count)
+	for i := 1; i <= ring.count; i += 1 {
+		idx := (ring.next - i + len(ring.entries)) % len(ring.entries)
+		entries = append(entries, ring.entries[idx])
+	}
+	return entries, true
+}
+
+// Converters returns the sorted names of all converters with an enabled history
+func (h *ErrorHistory) Converters() (converters []string) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	converters = make([]string, 0, len(h.converters))
+	for converter := range h.converters {
+		converters = append(converters, converter)
+	}
+	sort.Strings(converters)
+	return
+}
+
+func (h *ErrorHistory) GetStructless(converter string) (entries interface{}, ok bool) {
+	return h.Get(converter)
+}
+
+func (h *ErrorHistory) GetAllStructless() interface{} {
+	ret := make(map[string][]ErrorHistoryEntry)
+	for _, converter := range h.Converters() {
+		if entries, ok := h.Get(converter); ok {
+			ret[converter] = entries
+		}
+	}
+	return ret
+}
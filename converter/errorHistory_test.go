@@ -0,0 +1,72 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	converter_mock "github.com/koestler/go-mqtt-to-influx/v2/converter/mock"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestErrorHistory(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	h := NewErrorHistory()
+	h.AddConverter("tasmota-state", 3)
+	h.AddConverter("disabled", 0)
+
+	add := func(converter, topic, payload string) {
+		mockInput := converter_mock.NewMockInput(mockCtrl)
+		mockInput.EXPECT().Topic().Return(topic).AnyTimes()
+		mockInput.EXPECT().Payload().Return([]byte(payload)).AnyTimes()
+		h.Add(converter, mockInput, errors.New("cannot json decode"))
+	}
+
+	for i := 0; i < 5; i += 1 {
+		add("tasmota-state", fmt.Sprintf("tele/dev%d/STATE", i), "invalid")
+	}
+	add("disabled", "tele/dev/STATE", "invalid")
+	add("unknown", "tele/dev/STATE", "invalid")
+
+	entries, ok := h.Get("tasmota-state")
+	if !ok {
+		t.Fatalf("expected tasmota-state to have a history")
+	}
+	topics := make([]string, len(entries))
+	for i, e := range entries {
+		topics[i] = e.Topic
+		if e.Payload != "invalid" || e.Error != "cannot json decode" || e.Truncated {
+			t.Errorf("unexpected entry: %v", e)
+		}
+	}
+	if expected := []string{"tele/dev4/STATE", "tele/dev3/STATE", "tele/dev2/STATE"}; !reflect.DeepEqual(expected, topics) {
+		t.Errorf("expected topics %v but got %v", expected, topics)
+	}
+
+	if _, ok := h.Get("disabled"); ok {
+		t.Errorf("expected disabled to have no history")
+	}
+
+	if expected := []string{"tasmota-state"}; !reflect.DeepEqual(expected, h.Converters()) {
+		t.Errorf("expected converters %v but got %v", expected, h.Converters())
+	}
+
+	add("tasmota-state", "tele/big/STATE", strings.Repeat("x", maxErrorPayloadLength+1))
+	entries, _ = h.Get("tasmota-state")
+	if !entries[0].Truncated || len(entries[0].Payload) != maxErrorPayloadLength {
+		t.Errorf("expected long payload to be truncated")
+	}
+
+	// adding a converter again with the same size must keep its entries
+	h.AddConverter("tasmota-state", 3)
+	if entries, _ := h.Get("tasmota-state"); len(entries) != 3 {
+		t.Errorf("expected entries to be kept, got %d entries", len(entries))
+	}
+	h.AddConverter("tasmota-state", 5)
+	if entries, _ := h.Get("tasmota-state"); len(entries) != 0 {
+		t.Errorf("expected entries to be reset on size change, got %d entries", len(entries))
+	}
+}
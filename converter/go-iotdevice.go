@@ -1,9 +1,9 @@
 package converter
 
 import (
+	"fmt"
 	"log"
 	"strings"
-	"time"
 )
 
 type goIotdeviceTelemetryMessage struct {
@@ -41,22 +41,20 @@ func init() {
 
 // parses messages generated by the go-iotdevices tool in the format given by goVeSensorTelemetryMessage
 // and write one point per value to the influxdb
-func goIotdeviceHandler(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) {
+func goIotdeviceHandler(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) error {
 	// use our time
-	timeStamp := time.Now()
+	timeStamp := receiveTime(input)
 
 	// parse topic
 	device, err := tm.MatchDevice(input.Topic())
 	if err != nil {
-		log.Printf("go-iotdevice[%s]: cannot extract device from topic='%s' err=%s", c.Name(), input.Topic(), err)
-		return
+		return fmt.Errorf("cannot extract device from topic='%s' err=%s", input.Topic(), err)
 	}
 
 	// parse payload
 	var message goIotdeviceTelemetryMessage
 	if err := json.Unmarshal(input.Payload(), &message); err != nil {
-		log.Printf("go-iotdevice[%s]: cannot json decode: %s", c.Name(), err)
-		return
+		return fmt.Errorf("cannot json decode: %s", err)
 	}
 
 	if sentClock, err := parseTimeWithZone(message.Time); err == nil {
@@ -119,4 +117,6 @@ func goIotdeviceHandler(c Config, tm TopicMatcher, input Input, outputFunc Outpu
 			},
 		})
 	}
+
+	return nil
 }
@@ -139,11 +139,13 @@ func TestGoVeSensorV2(t *testing.T) {
 			Topic:             "piegn/tele/iot-device/24v-bmv/state",
 			Payload:           "invalid",
 			ExpectedLines:     []string{},
+			ExpectedError:     true,
 			ExpectedTimeStamp: time.Now(),
 		}, {
 			Topic:             "invalid",
 			Payload:           "{}",
 			ExpectedLines:     []string{},
+			ExpectedError:     true,
 			ExpectedTimeStamp: time.Now(),
 		},
 		{
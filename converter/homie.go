@@ -0,0 +1,216 @@
+package converter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// homieProperty holds the attributes of a property received from the retained $ topics
+type homieProperty struct {
+	datatype string
+	unit     string
+	format   string
+}
+
+var (
+	// homieProperties holds the property attributes by converter name and <device>/<node>/<property>
+	homieProperties      = make(map[string]map[string]homieProperty)
+	homiePropertiesMutex sync.RWMutex
+)
+
+func init() {
+	registerHandler("homie", homieHandler)
+}
+
+// parses messages of devices following the Homie 4 convention, see https://homieiot.github.io/specification/
+// the device given by the topic matcher must be <device>/<node>/<property>[/<attribute>], e.g.
+// - homie/esp-kitchen/climate/temperature/$datatype float
+// - homie/esp-kitchen/climate/temperature/$unit °C
+// - homie/esp-kitchen/climate/temperature 21.5
+func homieHandler(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) error {
+	// use our time
+	timeStamp := receiveTime(input)
+
+	// parse topic
+	matched, err := tm.MatchDevice(input.Topic())
+	if err != nil {
+		return fmt.Errorf("cannot extract device from topic='%s' err=%s", input.Topic(), err)
+	}
+	parts := strings.Split(matched, "/")
+	if len(parts) < 3 || len(parts) > 4 {
+		return fmt.Errorf("cannot extract device, node and property from device='%s'", matched)
+	}
+	device, node, property := parts[0], parts[1], parts[2]
+	if strings.HasPrefix(node, "$") || strings.HasPrefix(property, "$") {
+		// attributes of devices and nodes like $state or $properties are not used
+		return nil
+	}
+	key := device + "/" + node + "/" + property
+
+	if len(parts) == 4 {
+		homieAttributeHandler(c.Name(), key, parts[3], string(input.Payload()))
+		return nil
+	}
+
+	// properties without a cached entry are strings, e.g. when only $name was received
+	homiePropertiesMutex.RLock()
+	prop := homieProperties[c.Name()][key]
+	homiePropertiesMutex.RUnlock()
+
+	payload := string(input.Payload())
+	if payload == "" {
+		// an empty value means the value is unknown
+		return nil
+	}
+
+	var unit *string
+	if prop.unit != "" {
+		unit = &prop.unit
+	}
+	auxTags := &map[string]string{"node": node}
+
+	output := func(field string, unit *string) telemetryOutputMessage {
+		return telemetryOutputMessage{
+			timeStamp: timeStamp,
+			device:    device,
+			field:     field,
+			unit:      unit,
+			sensor:    "homie",
+			auxTags:   auxTags,
+		}
+	}
+
+	switch prop.datatype {
+	case "integer":
+		v, err := strconv.ParseInt(payload, 10, 64)
+		if err != nil {
+			return fmt.Errorf("cannot parse integer value='%s' of property='%s': %s", payload, key, err)
+		}
+		m := output(property, unit)
+		m.intValue = &v
+		outputFunc(m)
+	case "float":
+		v, err := strconv.ParseFloat(payload, 64)
+		if err != nil {
+			return fmt.Errorf("cannot parse float value='%s' of property='%s': %s", payload, key, err)
+		}
+		m := output(property, unit)
+		m.floatValue = &v
+		outputFunc(m)
+	case "boolean":
+		var v bool
+		switch payload {
+		case "true":
+			v = true
+		case "false":
+			v = false
+		default:
+			return fmt.Errorf("cannot parse boolean value='%s' of property='%s'", payload, key)
+		}
+		m := output(property, unit)
+		m.boolValue = &v
+		outputFunc(m)
+	case "enum":
+		if prop.format != "" && !homieEnumContains(prop.format, payload) {
+			return fmt.Errorf("value='%s' of property='%s' is not in format='%s'", payload, key, prop.format)
+		}
+		m := output(property, unit)
+		m.stringValue = &payload
+		outputFunc(m)
+	case "color":
+		return homieColorHandler(payload, prop.format, property, output, outputFunc)
+	default:
+		// string, datetime and duration as well as properties without a $datatype, which default to string
+		m := output(property, unit)
+		m.stringValue = &payload
+		outputFunc(m)
+	}
+
+	return nil
+}
+
+// homieAttributeHandler stores the attributes needed to convert the property values
+func homieAttributeHandler(converter, key, attribute, value string) {
+	homiePropertiesMutex.Lock()
+	defer homiePropertiesMutex.Unlock()
+
+	properties, ok := homieProperties[converter]
+	if !ok {
+		properties = make(map[string]homieProperty)
+		homieProperties[converter] = properties
+	}
+
+	prop := properties[key]
+	switch attribute {
+	case "$datatype":
+		prop.datatype = value
+	case "$unit":
+		prop.unit = value
+	case "$format":
+		prop.format = value
+	default:
+		// e.g. $name, $settable and the set topic used to send commands
+		return
+	}
+	properties[key] = prop
+}
+
+// homieColorHandler outputs one point per color component, the $format defines which ones the value contains
+func homieColorHandler(
+	payload, format, property string,
+	output func(field string, unit *string) telemetryOutputMessage,
+	outputFunc OutputFunc,
+) error {
+	var fields, units []string
+	switch format {
+	case "rgb":
+		fields = []string{"Red", "Green", "Blue"}
+		units = []string{"", "", ""}
+	case "hsv":
+		fields = []string{"Hue", "Saturation", "Value"}
+		units = []string{"°", "%", "%"}
+	case "xyz":
+		fields = []string{"X", "Y"}
+		units = []string{"", ""}
+	default:
+		return fmt.Errorf("unknown color format='%s' of property='%s'", format, property)
+	}
+
+	parts := strings.Split(payload, ",")
+	if len(parts) != len(fields) {
+		return fmt.Errorf("expect %d comma separated values for color format='%s' but got value='%s'",
+			len(fields), format, payload,
+		)
+	}
+
+	values := make([]float64, len(parts))
+	for i, p := range parts {
+		var err error
+		if values[i], err = strconv.ParseFloat(strings.TrimSpace(p), 64); err != nil {
+			return fmt.Errorf("cannot parse color value='%s' of property='%s': %s", payload, property, err)
+		}
+	}
+
+	for i, field := range fields {
+		var unit *string
+		if units[i] != "" {
+			unit = &units[i]
+		}
+		m := output(property+field, unit)
+		m.floatValue = &values[i]
+		outputFunc(m)
+	}
+	return nil
+}
+
+// homieEnumContains checks whether the value is one of the comma separated values of the $format of an enum
+func homieEnumContains(format, value string) bool {
+	for _, v := range strings.Split(format, ",") {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package converter
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/koestler/go-mqtt-to-influx/v2/converter/mock"
+	"testing"
+	"time"
+)
+
+func TestHomie(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockConfig := converter_mock.NewMockConfig(mockCtrl)
+	mockConfig.EXPECT().Name().Return("test-converter").AnyTimes()
+
+	mockAttributeTMConfig := converter_mock.NewMockTopicMatcherConfig(mockCtrl)
+	mockAttributeTMConfig.EXPECT().Topic().Return("homie/%Device%").AnyTimes()
+	mockAttributeTMConfig.EXPECT().Device().Return("+/+/+/+").AnyTimes()
+	mockAttributeTMConfig.EXPECT().DeviceIsDynamic().Return(true).AnyTimes()
+
+	mockValueTMConfig := converter_mock.NewMockTopicMatcherConfig(mockCtrl)
+	mockValueTMConfig.EXPECT().Topic().Return("homie/%Device%").AnyTimes()
+	mockValueTMConfig.EXPECT().Device().Return("+/+/+").AnyTimes()
+	mockValueTMConfig.EXPECT().DeviceIsDynamic().Return(true).AnyTimes()
+
+	now := time.Now()
+
+	attributes := [][2]string{
+		{"homie/esp-kitchen/climate/temperature/$name", "Temperature"},
+		{"homie/esp-kitchen/climate/temperature/$datatype", "float"},
+		{"homie/esp-kitchen/climate/temperature/$unit", "°C"},
+		{"homie/esp-kitchen/climate/co2/$datatype", "integer"},
+		{"homie/esp-kitchen/climate/co2/$unit", "ppm"},
+		{"homie/esp-kitchen/relay/on/$datatype", "boolean"},
+		{"homie/esp-kitchen/relay/on/$settable", "true"},
+		{"homie/esp-kitchen/relay/on/set", "false"},
+		{"homie/esp-kitchen/fan/mode/$datatype", "enum"},
+		{"homie/esp-kitchen/fan/mode/$format", "off,low,high"},
+		{"homie/esp-kitchen/led/color/$datatype", "color"},
+		{"homie/esp-kitchen/led/color/$format", "rgb"},
+		{"homie/esp-kitchen/bulb/color/$datatype", "color"},
+		{"homie/esp-kitchen/bulb/color/$format", "hsv"},
+		{"homie/esp-kitchen/info/version/$name", "Version"},
+	}
+	attributeStimuli := make(TestStimuliResponse, len(attributes))
+	for i, a := range attributes {
+		attributeStimuli[i].Topic = a[0]
+		attributeStimuli[i].Payload = a[1]
+		attributeStimuli[i].ExpectedLines = []string{}
+		attributeStimuli[i].ExpectedTimeStamp = now
+	}
+
+	valueStimuli := TestStimuliResponse{
+		{
+			Topic:   "homie/esp-kitchen/climate/temperature",
+			Payload: "21.5",
+			ExpectedLines: []string{
+				"telemetry,device=esp-kitchen,field=temperature,node=climate,sensor=homie,unit=°C floatValue=21.5",
+			},
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:   "homie/esp-kitchen/climate/co2",
+			Payload: "612",
+			ExpectedLines: []string{
+				"telemetry,device=esp-kitchen,field=co2,node=climate,sensor=homie,unit=ppm intValue=612i",
+			},
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:   "homie/esp-kitchen/relay/on",
+			Payload: "true",
+			ExpectedLines: []string{
+				"telemetry,device=esp-kitchen,field=on,node=relay,sensor=homie boolValue=true",
+			},
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:   "homie/esp-kitchen/fan/mode",
+			Payload: "low",
+			ExpectedLines: []string{
+				"telemetry,device=esp-kitchen,field=mode,node=fan,sensor=homie stringValue=\"low\"",
+			},
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:             "homie/esp-kitchen/fan/mode",
+			Payload:           "turbo",
+			ExpectedLines:     []string{},
+			ExpectedError:     true,
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:   "homie/esp-kitchen/led/color",
+			Payload: "255,128,0",
+			ExpectedLines: []string{
+				"telemetry,device=esp-kitchen,field=colorRed,node=led,sensor=homie floatValue=255",
+				"telemetry,device=esp-kitchen,field=colorGreen,node=led,sensor=homie floatValue=128",
+				"telemetry,device=esp-kitchen,field=colorBlue,node=led,sensor=homie floatValue=0",
+			},
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:   "homie/esp-kitchen/bulb/color",
+			Payload: "30,100,50",
+			ExpectedLines: []string{
+				"telemetry,device=esp-kitchen,field=colorHue,node=bulb,sensor=homie,unit=° floatValue=30",
+				"telemetry,device=esp-kitchen,field=colorSaturation,node=bulb,sensor=homie,unit=% floatValue=100",
+				"telemetry,device=esp-kitchen,field=colorValue,node=bulb,sensor=homie,unit=% floatValue=50",
+			},
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:             "homie/esp-kitchen/led/color",
+			Payload:           "255,128",
+			ExpectedLines:     []string{},
+			ExpectedError:     true,
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:             "homie/esp-kitchen/climate/co2",
+			Payload:           "612.5",
+			ExpectedLines:     []string{},
+			ExpectedError:     true,
+			ExpectedTimeStamp: now,
+		}, {
+			// only attributes not needed to convert the value were received, the datatype defaults to string
+			Topic:   "homie/esp-kitchen/info/version",
+			Payload: "1.0.2",
+			ExpectedLines: []string{
+				"telemetry,device=esp-kitchen,field=version,node=info,sensor=homie stringValue=\"1.0.2\"",
+			},
+			ExpectedTimeStamp: now,
+		}, {
+			// no attributes received at all
+			Topic:   "homie/esp-kitchen/info/ip",
+			Payload: "192.168.1.42",
+			ExpectedLines: []string{
+				"telemetry,device=esp-kitchen,field=ip,node=info,sensor=homie stringValue=\"192.168.1.42\"",
+			},
+			ExpectedTimeStamp: now,
+		}, {
+			// device attributes are not matched
+			Topic:             "homie/esp-kitchen/$state",
+			Payload:           "ready",
+			ExpectedLines:     []string{},
+			ExpectedError:     true,
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:             "homie/esp-kitchen/climate/$properties",
+			Payload:           "temperature,co2",
+			ExpectedLines:     []string{},
+			ExpectedTimeStamp: now,
+		},
+	}
+
+	if h, err := GetHandler("homie"); err != nil {
+		t.Errorf("did not expect an error while getting handler: %s", err)
+	} else {
+		testStimuliResponse(t, mockCtrl, mockConfig, mockAttributeTMConfig, h, attributeStimuli)
+		testStimuliResponse(t, mockCtrl, mockConfig, mockValueTMConfig, h, valueStimuli)
+	}
+}
@@ -0,0 +1,95 @@
+package converter
+
+import (
+	"fmt"
+	protocol "github.com/influxdata/line-protocol"
+	"github.com/pkg/errors"
+	"time"
+)
+
+type lineProtocolOutputMessage struct {
+	timeStamp   time.Time
+	measurement string
+	tags        map[string]string
+	fields      map[string]interface{}
+}
+
+func init() {
+	registerHandler("influx-line", influxLineHandler)
+}
+
+// parses one or more lines of influxdb line protocol as sent by Telegraf or firmware speaking influx
+// and writes them unchanged, except for the optional device tag, e.g.
+// - cpu,host=edge-1 usage_idle=98.2,usage_user=1.1 1746201600000000000
+// - weather,location=roof temperature=21.5,humidity=45i,raining=false
+// Lines without a timestamp use the time the message was received.
+func influxLineHandler(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) error {
+	// use our time
+	timeStamp := receiveTime(input)
+
+	// parse topic
+	device, err := tm.MatchDevice(input.Topic())
+	if err != nil {
+		return fmt.Errorf("cannot extract device from topic='%s' err=%s", input.Topic(), err)
+	}
+
+	precision := time.Nanosecond
+	addDeviceTag := false
+	if lc, ok := c.(LineProtocolConfig); ok {
+		precision = lc.Precision()
+		addDeviceTag = lc.AddDeviceTag()
+	}
+
+	// parse payload
+	handler := protocol.NewMetricHandler()
+	handler.SetTimePrecision(precision)
+	handler.SetTimeFunc(func() time.Time {
+		return timeStamp
+	})
+	metrics, err := protocol.NewParser(handler).Parse(input.Payload())
+	if err != nil {
+		return fmt.Errorf("cannot parse line protocol: %s", err)
+	}
+
+	if len(metrics) < 1 {
+		return errors.New("could not extract any sensor data")
+	}
+
+	for _, metric := range metrics {
+		m := lineProtocolOutputMessage{
+			timeStamp:   metric.Time(),
+			measurement: metric.Name(),
+			tags:        make(map[string]string, len(metric.TagList())+1),
+			fields:      make(map[string]interface{}, len(metric.FieldList())),
+		}
+		for _, t := range metric.TagList() {
+			m.tags[t.Key] = t.Value
+		}
+		// tags already present in the line take precedence
+		if _, ok := m.tags["device"]; addDeviceTag && !ok {
+			m.tags["device"] = device
+		}
+		for _, f := range metric.FieldList() {
+			m.fields[f.Key] = f.Value
+		}
+		outputFunc(m)
+	}
+
+	return nil
+}
+
+func (m lineProtocolOutputMessage) Measurement() string {
+	return m.measurement
+}
+
+func (m lineProtocolOutputMessage) Tags() map[string]string {
+	return m.tags
+}
+
+func (m lineProtocolOutputMessage) Fields() map[string]interface{} {
+	return m.fields
+}
+
+func (m lineProtocolOutputMessage) Time() time.Time {
+	return m.timeStamp
+}
@@ -0,0 +1,112 @@
+package converter
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/koestler/go-mqtt-to-influx/v2/converter/mock"
+	"testing"
+	"time"
+)
+
+type lineProtocolTestConfig struct {
+	Config
+	precision    time.Duration
+	addDeviceTag bool
+}
+
+func (c lineProtocolTestConfig) Precision() time.Duration {
+	return c.precision
+}
+
+func (c lineProtocolTestConfig) AddDeviceTag() bool {
+	return c.addDeviceTag
+}
+
+func TestInfluxLine(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockConfig := converter_mock.NewMockConfig(mockCtrl)
+	mockConfig.EXPECT().Name().Return("test-converter").AnyTimes()
+
+	mockTMConfig := converter_mock.NewMockTopicMatcherConfig(mockCtrl)
+	mockTMConfig.EXPECT().Topic().Return("telegraf/%Device%").AnyTimes()
+	mockTMConfig.EXPECT().Device().Return("+/+").AnyTimes()
+	mockTMConfig.EXPECT().DeviceIsDynamic().Return(true).AnyTimes()
+
+	now := time.Now()
+	expectedTimeStamp := time.Date(2025, time.May, 2, 16, 0, 0, 0, time.UTC)
+
+	h, err := GetHandler("influx-line")
+	if err != nil {
+		t.Fatalf("did not expect an error while getting handler: %s", err)
+	}
+
+	// nanosecond precision, no device tag
+	stimuli := TestStimuliResponse{
+		{
+			Topic: "telegraf/edge-1/cpu",
+			Payload: "cpu,cpu=cpu-total,host=edge-1 usage_idle=98.2,usage_user=1.1 1746201600000000000\n" +
+				"cpu,cpu=cpu0,host=edge-1 usage_idle=97.5,usage_user=1.9 1746201600000000000\n",
+			ExpectedLines: []string{
+				"cpu,cpu=cpu-total,host=edge-1 usage_idle=98.2,usage_user=1.1",
+				"cpu,cpu=cpu0,host=edge-1 usage_idle=97.5,usage_user=1.9",
+			},
+			ExpectedTimeStamp: expectedTimeStamp,
+		}, {
+			// without timestamp
+			Topic:   "telegraf/roof/weather",
+			Payload: `weather,location=roof temperature=21.5,humidity=45i,uptime=123456u,raining=false,condition="partly cloudy"`,
+			ExpectedLines: []string{
+				"weather,location=roof condition=\"partly cloudy\",humidity=45i,raining=false,temperature=21.5,uptime=123456u",
+			},
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:             "telegraf/roof/weather",
+			Payload:           `weather,location=roof temperature=`,
+			ExpectedLines:     []string{},
+			ExpectedError:     true,
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:             "telegraf/roof/weather",
+			Payload:           "\n",
+			ExpectedLines:     []string{},
+			ExpectedError:     true,
+			ExpectedTimeStamp: now,
+		},
+	}
+	testStimuliResponse(t, mockCtrl, lineProtocolTestConfig{Config: mockConfig, precision: time.Nanosecond}, mockTMConfig, h, stimuli)
+
+	// second precision with device tag
+	stimuli = TestStimuliResponse{
+		{
+			Topic:   "telegraf/edge-1/mem",
+			Payload: "mem,host=edge-1 used_percent=41.7 1746201600",
+			ExpectedLines: []string{
+				"mem,device=edge-1/mem,host=edge-1 used_percent=41.7",
+			},
+			ExpectedTimeStamp: expectedTimeStamp,
+		}, {
+			// a device tag given by the line is kept
+			Topic:   "telegraf/edge-1/mem",
+			Payload: "mem,device=edge-1 used_percent=41.7 1746201600",
+			ExpectedLines: []string{
+				"mem,device=edge-1 used_percent=41.7",
+			},
+			ExpectedTimeStamp: expectedTimeStamp,
+		},
+	}
+	testStimuliResponse(t, mockCtrl, lineProtocolTestConfig{Config: mockConfig, precision: time.Second, addDeviceTag: true}, mockTMConfig, h, stimuli)
+
+	// without the optional config interface
+	stimuli = TestStimuliResponse{
+		{
+			Topic:   "telegraf/edge-1/mem",
+			Payload: "mem,host=edge-1 used_percent=41.7 1746201600000000000",
+			ExpectedLines: []string{
+				"mem,host=edge-1 used_percent=41.7",
+			},
+			ExpectedTimeStamp: expectedTimeStamp,
+		},
+	}
+	testStimuliResponse(t, mockCtrl, mockConfig, mockTMConfig, h, stimuli)
+}
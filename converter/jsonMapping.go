@@ -0,0 +1,230 @@
+package converter
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	registerHandler("json-mapping", jsonMappingHandler)
+}
+
+// parses arbitrary json messages and writes one point per configured mapping to the influxdb
+// example configuration:
+//
+//	JsonMappings:
+//	  - Path: temperature
+//	    Unit: °C
+//	  - Path: $.state
+//	    Type: bool
+//
+// example input:
+// - zigbee2mqtt/living-room {"temperature":21.5,"humidity":48,"state":"ON","linkquality":120}
+func jsonMappingHandler(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) error {
+	mc, ok := c.(JsonMappingsConfig)
+	if !ok {
+		return errors.New("no JsonMappings configured")
+	}
+
+	// use our time
+	timeStamp := receiveTime(input)
+
+	// parse topic
+	device, err := tm.MatchDevice(input.Topic())
+	if err != nil {
+		return fmt.Errorf("cannot extract device from topic='%s' err=%s", input.Topic(), err)
+	}
+
+	// parse payload
+	var message interface{}
+	if err := json.Unmarshal(input.Payload(), &message); err != nil {
+		return fmt.Errorf("cannot json decode: %s", err)
+	}
+
+	for _, m := range mc.JsonMappings() {
+		value, ok := jsonPathLookup(message, m.Path())
+		if !ok {
+			if c.LogDebug() {
+				log.Printf("json-mapping[%s]: path='%s' not found in payload='%s'", c.Name(), m.Path(), input.Payload())
+			}
+			continue
+		}
+
+		pointTimeStamp := timeStamp
+		if timePath := m.TimePath(); len(timePath) > 0 {
+			if t, ok := jsonPathLookup(message, timePath); !ok {
+				log.Printf("json-mapping[%s]: timePath='%s' not found, use receive time", c.Name(), timePath)
+			} else if ts, err := jsonMappingParseTime(t); err != nil {
+				log.Printf("json-mapping[%s]: cannot parse time at timePath='%s': %s", c.Name(), timePath, err)
+			} else {
+				pointTimeStamp = ts
+			}
+		}
+
+		output := telemetryOutputMessage{
+			timeStamp: pointTimeStamp,
+			device:    device,
+			field:     m.Field(),
+			unit: func(u string) *string {
+				if len(u) > 0 {
+					return &u
+				}
+				return nil
+			}(m.Unit()),
+			sensor: m.Sensor(),
+		}
+
+		switch m.ValueType() {
+		case "int":
+			if v, err := jsonMappingToInt(value); err != nil {
+				log.Printf("json-mapping[%s]: path='%s': %s", c.Name(), m.Path(), err)
+				continue
+			} else {
+				output.intValue = &v
+			}
+		case "bool":
+			if v, err := jsonMappingToBool(value); err != nil {
+				log.Printf("json-mapping[%s]: path='%s': %s", c.Name(), m.Path(), err)
+				continue
+			} else {
+				output.boolValue = &v
+			}
+		case "string":
+			v := jsonMappingToString(value)
+			output.stringValue = &v
+		default:
+			if v, err := jsonMappingToFloat(value); err != nil {
+				log.Printf("json-mapping[%s]: path='%s': %s", c.Name(), m.Path(), err)
+				continue
+			} else {
+				output.floatValue = &v
+			}
+		}
+
+		outputFunc(output)
+	}
+
+	return nil
+}
+
+// jsonPathLookup resolves dotted paths like a.b.0.c and simple JSONPath expressions like $.a.b[0].c
+// within a message decoded into interface{} by json.Unmarshal
+func jsonPathLookup(message interface{}, path string) (value interface{}, ok bool) {
+	path = strings.TrimPrefix(path, "$")
+	path = strings.TrimPrefix(path, ".")
+	path = strings.ReplaceAll(path, "[", ".")
+	path = strings.ReplaceAll(path, "]", "")
+
+	value = message
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if value, ok = v[key]; !ok {
+				return nil, false
+			}
+		case []interface{}:
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return nil, false
+			}
+			value = v[idx]
+		default:
+			return nil, false
+		}
+	}
+
+	return value, value != nil
+}
+
+func jsonMappingToFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("cannot convert value='%v' to float", value)
+}
+
+func jsonMappingToInt(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case float64:
+		if v == math.Trunc(v) {
+			return int64(v), nil
+		}
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("cannot convert value='%v' to int", value)
+}
+
+func jsonMappingToBool(value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case float64:
+		return v != 0, nil
+	case string:
+		switch strings.ToUpper(v) {
+		case "ON", "TRUE", "1", "YES", "OPEN":
+			return true, nil
+		case "OFF", "FALSE", "0", "NO", "CLOSED":
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("cannot convert value='%v' to bool", value)
+}
+
+func jsonMappingToString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+// jsonMappingParseTime accepts RFC3339 strings, times without zone in the Tasmota format, which are read in the local
+// time zone of this host, and numeric unix timestamps in seconds; numbers larger than 1e11 are interpreted as milliseconds
+func jsonMappingParseTime(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case string:
+		if t, err := time.Parse(timeWithZoneFormat, v); err == nil {
+			return t, nil
+		}
+		if t, err := time.ParseInLocation(timeFormat, v, time.Local); err == nil {
+			return t, nil
+		}
+	case float64:
+		if v > 1e11 {
+			return time.UnixMilli(int64(v)).UTC(), nil
+		}
+		sec, frac := math.Modf(v)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("unknown time format of value='%v'", value)
+}
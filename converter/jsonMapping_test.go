@@ -0,0 +1,156 @@
+package converter
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/koestler/go-mqtt-to-influx/v2/converter/mock"
+	"testing"
+	"time"
+)
+
+type testJsonMapping struct {
+	path, field, unit, valueType, sensor, timePath string
+}
+
+func (m testJsonMapping) Path() string      { return m.path }
+func (m testJsonMapping) Field() string     { return m.field }
+func (m testJsonMapping) Unit() string      { return m.unit }
+func (m testJsonMapping) ValueType() string { return m.valueType }
+func (m testJsonMapping) Sensor() string    { return m.sensor }
+func (m testJsonMapping) TimePath() string  { return m.timePath }
+
+type testJsonMappingsConfig struct {
+	*converter_mock.MockConfig
+	jsonMappings []JsonMappingConfig
+}
+
+func (c testJsonMappingsConfig) JsonMappings() []JsonMappingConfig {
+	return c.jsonMappings
+}
+
+func TestJsonMapping(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockConfig := converter_mock.NewMockConfig(mockCtrl)
+	mockConfig.EXPECT().Name().Return("test-converter").AnyTimes()
+	mockConfig.EXPECT().LogDebug().Return(false).AnyTimes()
+
+	config := testJsonMappingsConfig{
+		MockConfig: mockConfig,
+		jsonMappings: []JsonMappingConfig{
+			testJsonMapping{path: "temperature", field: "Temperature", unit: "°C", valueType: "float", sensor: "z2m"},
+			testJsonMapping{path: "$.humidity", field: "Humidity", unit: "%", valueType: "float", sensor: "z2m"},
+			testJsonMapping{path: "state", field: "State", valueType: "bool", sensor: "z2m"},
+			testJsonMapping{path: "linkquality", field: "LinkQuality", valueType: "int", sensor: "z2m"},
+			testJsonMapping{path: "$.update.state", field: "UpdateState", valueType: "string", sensor: "z2m"},
+			testJsonMapping{path: "sensors[1].value", field: "Sensor1", valueType: "float", sensor: "esp", timePath: "ts"},
+		},
+	}
+
+	mockTMConfig := converter_mock.NewMockTopicMatcherConfig(mockCtrl)
+	mockTMConfig.EXPECT().Topic().Return("zigbee2mqtt/%Device%").AnyTimes()
+	mockTMConfig.EXPECT().Device().Return("+").AnyTimes()
+	mockTMConfig.EXPECT().DeviceIsDynamic().Return(true).AnyTimes()
+
+	now := time.Now()
+
+	stimuli := TestStimuliResponse{
+		{
+			Topic:   "zigbee2mqtt/living-room",
+			Payload: `{"temperature":21.5,"humidity":"48","state":"ON","linkquality":120,"update":{"state":"idle"}}`,
+			ExpectedLines: []string{
+				"telemetry,device=living-room,field=Temperature,sensor=z2m,unit=°C floatValue=21.5",
+				"telemetry,device=living-room,field=Humidity,sensor=z2m,unit=% floatValue=48",
+				"telemetry,device=living-room,field=State,sensor=z2m boolValue=true",
+				"telemetry,device=living-room,field=LinkQuality,sensor=z2m intValue=120i",
+				"telemetry,device=living-room,field=UpdateState,sensor=z2m stringValue=\"idle\"",
+			},
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:             "zigbee2mqtt/garage",
+			Payload:           `{"temperature":null,"state":"unknown","linkquality":12.5}`,
+			ExpectedLines:     []string{},
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:             "zigbee2mqtt/garage",
+			Payload:           "invalid",
+			ExpectedLines:     []string{},
+			ExpectedError:     true,
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:             "invalid/garage",
+			Payload:           `{"temperature":21.5}`,
+			ExpectedLines:     []string{},
+			ExpectedError:     true,
+			ExpectedTimeStamp: now,
+		},
+	}
+
+	if h, err := GetHandler("json-mapping"); err != nil {
+		t.Errorf("did not expect an error while getting handler: %s", err)
+	} else {
+		testStimuliResponse(t, mockCtrl, config, mockTMConfig, h, stimuli)
+	}
+
+	timeStimuli := TestStimuliResponse{
+		{
+			Topic:   "zigbee2mqtt/esp-0",
+			Payload: `{"ts":1661461942,"sensors":[{"value":1},{"value":2.5}]}`,
+			ExpectedLines: []string{
+				"telemetry,device=esp-0,field=Sensor1,sensor=esp floatValue=2.5",
+			},
+			ExpectedTimeStamp: time.Unix(1661461942, 0),
+		}, {
+			Topic:   "zigbee2mqtt/esp-1",
+			Payload: `{"ts":"2022-08-25T21:12:53Z","sensors":[{"value":1},{"value":3}]}`,
+			ExpectedLines: []string{
+				"telemetry,device=esp-1,field=Sensor1,sensor=esp floatValue=3",
+			},
+			ExpectedTimeStamp: time.Date(2022, time.August, 25, 21, 12, 53, 0, time.UTC),
+		}, {
+			// times without zone are local times
+			Topic:   "zigbee2mqtt/esp-2",
+			Payload: `{"ts":"2022-08-25T21:12:53","sensors":[{"value":1},{"value":4}]}`,
+			ExpectedLines: []string{
+				"telemetry,device=esp-2,field=Sensor1,sensor=esp floatValue=4",
+			},
+			ExpectedTimeStamp: time.Date(2022, time.August, 25, 21, 12, 53, 0, time.Local),
+		},
+	}
+
+	if h, err := GetHandler("json-mapping"); err != nil {
+		t.Errorf("did not expect an error while getting handler: %s", err)
+	} else {
+		testStimuliResponse(t, mockCtrl, config, mockTMConfig, h, timeStimuli)
+	}
+}
+
+func TestJsonPathLookup(t *testing.T) {
+	var message interface{}
+	if err := json.Unmarshal([]byte(`{"a":{"b":[{"c":1},{"c":2}]},"d":true}`), &message); err != nil {
+		t.Fatal(err)
+	}
+
+	values := []struct {
+		Path  string
+		Found bool
+		Value interface{}
+	}{
+		{"a.b.1.c", true, 2.0},
+		{"$.a.b[0].c", true, 1.0},
+		{"$.d", true, true},
+		{"d", true, true},
+		{"a.b[2].c", false, nil},
+		{"a.x", false, nil},
+		{"d.e", false, nil},
+	}
+
+	for _, v := range values {
+		value, ok := jsonPathLookup(message, v.Path)
+		if ok != v.Found {
+			t.Errorf("path='%s': expected found=%t but got %t", v.Path, v.Found, ok)
+		} else if value != v.Value {
+			t.Errorf("path='%s': expected value=%v but got %v", v.Path, v.Value, value)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package converter
+
+import "time"
+
+type locationOutputMessage struct {
+	timeStamp time.Time
+	device    string // empty for gateway locations
+	gateway   string // empty for device locations
+	source    string
+	latitude  float64
+	longitude float64
+	altitude  *float64
+	accuracy  *float64
+}
+
+func (m locationOutputMessage) Measurement() string {
+	return "location"
+}
+
+func (m locationOutputMessage) Tags() map[string]string {
+	ret := map[string]string{
+		"source": m.source,
+	}
+
+	if m.device != "" {
+		ret["device"] = m.device
+	}
+	if m.gateway != "" {
+		ret["gateway"] = m.gateway
+	}
+
+	return ret
+}
+
+func (m locationOutputMessage) Fields() (ret map[string]interface{}) {
+	ret = map[string]interface{}{
+		"lat": m.latitude,
+		"lon": m.longitude,
+	}
+
+	if m.altitude != nil {
+		ret["altitude"] = *m.altitude
+	}
+	if m.accuracy != nil {
+		ret["accuracy"] = *m.accuracy
+	}
+
+	return
+}
+
+func (m locationOutputMessage) Time() time.Time {
+	return m.timeStamp
+}
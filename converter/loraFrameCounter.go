@@ -0,0 +1,45 @@
+package converter
+
+import "sync"
+
+var (
+	// loraFrameCounters holds the last frame counter seen by converter name and devEui
+	loraFrameCounters      = make(map[string]map[string]int64)
+	loraFrameCountersMutex sync.Mutex
+)
+
+// ResetLoraFrameCounters forgets the frame counters seen by the given converter, e.g. when it is (re)created
+func ResetLoraFrameCounters(converter string) {
+	loraFrameCountersMutex.Lock()
+	defer loraFrameCountersMutex.Unlock()
+	delete(loraFrameCounters, converter)
+}
+
+// loraFrameCounterUpdate stores the frame counter of the given uplink and returns the number of uplinks missed
+// since the last one seen. A decreasing counter means the device has rejoined (or was reset) and started over at 0.
+func loraFrameCounterUpdate(converter, devEui string, fCnt int64) (missed int64, rejoin bool) {
+	loraFrameCountersMutex.Lock()
+	defer loraFrameCountersMutex.Unlock()
+
+	counters, ok := loraFrameCounters[converter]
+	if !ok {
+		counters = make(map[string]int64)
+		loraFrameCounters[converter] = counters
+	}
+
+	last, ok := counters[devEui]
+	counters[devEui] = fCnt
+
+	switch {
+	case !ok:
+		// first uplink seen, nothing known about earlier ones
+		return 0, false
+	case fCnt < last:
+		return fCnt, true
+	case fCnt == last:
+		// the same uplink received twice
+		return 0, false
+	default:
+		return fCnt - last - 1, false
+	}
+}
@@ -0,0 +1,38 @@
+package converter
+
+import "testing"
+
+func TestLoraFrameCounterUpdate(t *testing.T) {
+	ResetLoraFrameCounters("test-converter")
+
+	tests := []struct {
+		name           string
+		devEui         string
+		fCnt           int64
+		expectedMissed int64
+		expectedRejoin bool
+	}{
+		{"first uplink", "0000000000000001", 41, 0, false},
+		{"next uplink", "0000000000000001", 42, 0, false},
+		{"gap", "0000000000000001", 45, 2, false},
+		{"duplicate", "0000000000000001", 45, 0, false},
+		{"other device", "0000000000000002", 7, 0, false},
+		{"rejoin", "0000000000000001", 3, 3, true},
+		{"after rejoin", "0000000000000001", 4, 0, false},
+	}
+
+	for _, tc := range tests {
+		missed, rejoin := loraFrameCounterUpdate("test-converter", tc.devEui, tc.fCnt)
+		if missed != tc.expectedMissed || rejoin != tc.expectedRejoin {
+			t.Errorf("%s: expected missed=%d rejoin=%v, got missed=%d rejoin=%v",
+				tc.name, tc.expectedMissed, tc.expectedRejoin, missed, rejoin,
+			)
+		}
+	}
+
+	// a reset converter starts over
+	ResetLoraFrameCounters("test-converter")
+	if missed, rejoin := loraFrameCounterUpdate("test-converter", "0000000000000001", 10); missed != 0 || rejoin {
+		t.Errorf("after reset: expected missed=0 rejoin=false, got missed=%d rejoin=%v", missed, rejoin)
+	}
+}
@@ -13,6 +13,22 @@ type loraOutputMessage struct {
 	snr             float64
 	consumedAirtime time.Duration
 	gatewayIdx      int
+	fCnt            int64
+	fPort           int64
+	spreadingFactor int64 // 0 when unknown
+	bandwidth       int64 // in Hz, 0 when unknown
+	frequency       int64 // in Hz, 0 when unknown
+}
+
+// loraUplinkOutputMessage is written once per uplink, independent of the number of gateways receiving it
+type loraUplinkOutputMessage struct {
+	timeStamp     time.Time
+	device        string
+	devEui        string
+	fCnt          int64
+	missedUplinks int64
+	rejoin        bool
+	gatewayCount  int
 }
 
 func (m loraOutputMessage) Measurement() string {
@@ -31,15 +47,53 @@ func (m loraOutputMessage) Tags() map[string]string {
 }
 
 func (m loraOutputMessage) Fields() (ret map[string]interface{}) {
-	return map[string]interface{}{
+	ret = map[string]interface{}{
 		"rssi":              m.rssi,
 		"channelRssi":       m.channelRssi,
 		"snr":               m.snr,
 		"consumedAirtimeUs": m.consumedAirtime.Microseconds(),
 		"gatewayIdx":        m.gatewayIdx,
+		"fCnt":              m.fCnt,
+		"fPort":             m.fPort,
+	}
+
+	if m.spreadingFactor > 0 {
+		ret["spreadingFactor"] = m.spreadingFactor
 	}
+	if m.bandwidth > 0 {
+		ret["bandwidth"] = m.bandwidth
+	}
+	if m.frequency > 0 {
+		ret["frequency"] = m.frequency
+	}
+
+	return
 }
 
 func (m loraOutputMessage) Time() time.Time {
 	return m.timeStamp
 }
+
+func (m loraUplinkOutputMessage) Measurement() string {
+	return "loraUplink"
+}
+
+func (m loraUplinkOutputMessage) Tags() map[string]string {
+	return map[string]string{
+		"device": m.device,
+		"devEui": m.devEui,
+	}
+}
+
+func (m loraUplinkOutputMessage) Fields() (ret map[string]interface{}) {
+	return map[string]interface{}{
+		"fCnt":          m.fCnt,
+		"missedUplinks": m.missedUplinks,
+		"rejoin":        m.rejoin,
+		"gatewayCount":  m.gatewayCount,
+	}
+}
+
+func (m loraUplinkOutputMessage) Time() time.Time {
+	return m.timeStamp
+}
@@ -0,0 +1,283 @@
+package converter
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rtl433OutputMessage holds the radio quality of a received event
+type rtl433OutputMessage struct {
+	timeStamp time.Time
+	device    string
+	model     string
+	receiver  string
+	rssi      *float64
+	snr       *float64
+	noise     *float64
+	freq      *float64
+}
+
+// rtl433MetaKeys are not written as telemetry but used to build the device or the radio quality point
+var rtl433MetaKeys = map[string]bool{
+	"time":     true,
+	"model":    true,
+	"id":       true,
+	"channel":  true,
+	"subtype":  true,
+	"mic":      true,
+	"mod":      true,
+	"protocol": true,
+	"freq":     true,
+	"freq1":    true,
+	"freq2":    true,
+	"rssi":     true,
+	"snr":      true,
+	"noise":    true,
+}
+
+// rtl433Units defines the units of keys without a unit suffix
+var rtl433Units = map[string]string{
+	"humidity":    "%",
+	"humidity_1":  "%",
+	"humidity_2":  "%",
+	"moisture":    "%",
+	"battery_pct": "%",
+}
+
+// rtl433UnitSuffixes defines the units by the suffix rtl_433 appends to the keys, e.g. temperature_C or wind_avg_km_h;
+// longer suffixes come first
+var rtl433UnitSuffixes = []struct {
+	suffix string
+	unit   string
+}{
+	{"_km_h", "km/h"},
+	{"_m_s", "m/s"},
+	{"_mi_h", "mph"},
+	{"_mm_h", "mm/h"},
+	{"_in_h", "in/h"},
+	{"_hPa", "hPa"},
+	{"_kPa", "kPa"},
+	{"_inHg", "inHg"},
+	{"_psi", "psi"},
+	{"_kWh", "kWh"},
+	{"_deg", "°"},
+	{"_lux", "lux"},
+	{"_ppm", "ppm"},
+	{"_mV", "mV"},
+	{"_mm", "mm"},
+	{"_cm", "cm"},
+	{"_in", "in"},
+	{"_C", "°C"},
+	{"_F", "°F"},
+	{"_V", "V"},
+	{"_A", "A"},
+	{"_W", "W"},
+}
+
+// rtl433TimeFormats are the formats produced by the -M time options of rtl_433;
+// times without zone are local times of the rtl_433 host, expected to run in the same time zone as this host
+var rtl433TimeFormats = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05Z0700",
+	timeFormat,
+	"2006-01-02T15:04:05Z0700",
+	timeWithZoneFormat,
+}
+
+func init() {
+	registerHandler("rtl433", rtl433Handler)
+}
+
+// parses the events published by the mqtt output of rtl_433, e.g.
+// {"time":"2025-05-02 16:00:00","model":"Acurite-Tower","id":1234,"channel":"A","battery_ok":1,"temperature_C":21.5,"humidity":45,"rssi":-0.1,"snr":13.2,"noise":-13.3}
+// The device is built from the model, the id and the channel; the radio quality is written into the rtl433 measurement
+// tagged by the receiver given by the topic matcher.
+func rtl433Handler(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) error {
+	// parse topic
+	receiver, err := tm.MatchDevice(input.Topic())
+	if err != nil {
+		return fmt.Errorf("cannot extract device from topic='%s' err=%s", input.Topic(), err)
+	}
+
+	// parse payload
+	var event map[string]interface{}
+	if err := json.Unmarshal(input.Payload(), &event); err != nil {
+		return fmt.Errorf("cannot json decode: %s", err)
+	}
+
+	device, model := rtl433Device(event)
+	if device == "" {
+		return errors.New("cannot build device from event without model")
+	}
+
+	timeStamp := receiveTime(input)
+	if v, ok := event["time"]; ok {
+		if t, err := rtl433ParseTime(v); err != nil {
+			return err
+		} else if !t.IsZero() {
+			timeStamp = t
+		}
+	}
+
+	// send points
+	count := 0
+	for key, value := range event {
+		if rtl433MetaKeys[key] {
+			continue
+		}
+
+		m := telemetryOutputMessage{
+			timeStamp: timeStamp,
+			device:    device,
+			field:     key,
+			sensor:    "rtl433",
+		}
+		switch v := value.(type) {
+		case float64:
+			m.floatValue = &v
+			m.unit = rtl433Unit(key)
+		case string:
+			m.stringValue = &v
+		case bool:
+			m.boolValue = &v
+		default:
+			// lists and objects, e.g. the rows of some devices, are not used
+			continue
+		}
+		count += 1
+		outputFunc(m)
+	}
+
+	if count < 1 {
+		return errors.New("could not extract any sensor data")
+	}
+
+	// radio quality; only present when rtl_433 is started with -M level
+	q := rtl433OutputMessage{
+		timeStamp: timeStamp,
+		device:    device,
+		model:     model,
+		receiver:  receiver,
+		rssi:      rtl433Float(event["rssi"]),
+		snr:       rtl433Float(event["snr"]),
+		noise:     rtl433Float(event["noise"]),
+		freq:      rtl433Float(event["freq"]),
+	}
+	if q.freq == nil {
+		// FSK devices report the two frequencies used
+		q.freq = rtl433Float(event["freq1"])
+	}
+	if q.rssi != nil || q.snr != nil || q.noise != nil {
+		outputFunc(q)
+	}
+
+	return nil
+}
+
+// rtl433Device returns a stable device id like Acurite-Tower/1234/A built from the model, the id and the channel
+func rtl433Device(event map[string]interface{}) (device, model string) {
+	model = rtl433String(event["model"])
+	if model == "" {
+		return "", ""
+	}
+	parts := []string{model}
+	for _, key := range []string{"id", "channel"} {
+		if v := rtl433String(event[key]); v != "" {
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, "/"), model
+}
+
+func rtl433String(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+func rtl433Float(value interface{}) *float64 {
+	if v, ok := value.(float64); ok {
+		return &v
+	}
+	return nil
+}
+
+func rtl433Unit(key string) *string {
+	if unit, ok := rtl433Units[key]; ok {
+		return &unit
+	}
+	for _, s := range rtl433UnitSuffixes {
+		if strings.HasSuffix(key, s.suffix) {
+			unit := s.unit
+			return &unit
+		}
+	}
+	return nil
+}
+
+// rtl433ParseTime returns the zero time for relative times like @0.123s
+func rtl433ParseTime(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case string:
+		if strings.HasPrefix(v, "@") {
+			return time.Time{}, nil
+		}
+		for _, format := range rtl433TimeFormats {
+			if t, err := time.ParseInLocation(format, v, time.Local); err == nil {
+				return t, nil
+			}
+		}
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return rtl433UnixTime(f), nil
+		}
+	case float64:
+		return rtl433UnixTime(v), nil
+	}
+	return time.Time{}, fmt.Errorf("cannot parse time='%v'", value)
+}
+
+func rtl433UnixTime(t float64) time.Time {
+	sec, frac := math.Modf(t)
+	return time.Unix(int64(sec), int64(math.Round(frac*1e9))).UTC()
+}
+
+func (m rtl433OutputMessage) Measurement() string {
+	return "rtl433"
+}
+
+func (m rtl433OutputMessage) Tags() map[string]string {
+	return map[string]string{
+		"device":   m.device,
+		"model":    m.model,
+		"receiver": m.receiver,
+	}
+}
+
+func (m rtl433OutputMessage) Fields() map[string]interface{} {
+	ret := make(map[string]interface{}, 4)
+	if m.rssi != nil {
+		ret["rssi"] = *m.rssi
+	}
+	if m.snr != nil {
+		ret["snr"] = *m.snr
+	}
+	if m.noise != nil {
+		ret["noise"] = *m.noise
+	}
+	if m.freq != nil {
+		ret["freq"] = *m.freq
+	}
+	return ret
+}
+
+func (m rtl433OutputMessage) Time() time.Time {
+	return m.timeStamp
+}
@@ -0,0 +1,101 @@
+package converter
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/koestler/go-mqtt-to-influx/v2/converter/mock"
+	"testing"
+	"time"
+)
+
+func TestRtl433(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockConfig := converter_mock.NewMockConfig(mockCtrl)
+	mockConfig.EXPECT().Name().Return("test-converter").AnyTimes()
+
+	mockTMConfig := converter_mock.NewMockTopicMatcherConfig(mockCtrl)
+	mockTMConfig.EXPECT().Topic().Return("rtl_433/%Device%/events").AnyTimes()
+	mockTMConfig.EXPECT().Device().Return("+").AnyTimes()
+	mockTMConfig.EXPECT().DeviceIsDynamic().Return(true).AnyTimes()
+
+	now := time.Now()
+	expectedTimeStamp := time.Date(2025, time.May, 2, 16, 0, 0, 0, time.UTC)
+
+	stimuli := TestStimuliResponse{
+		{
+			// time without zone, the default of rtl_433, is a local time
+			Topic:   "rtl_433/raspberrypi/events",
+			Payload: `{"time":"2025-05-02 16:00:00","model":"Acurite-Tower","id":1234,"channel":"A","battery_ok":1,"temperature_C":21.5,"humidity":45,"mic":"CHECKSUM","mod":"ASK","freq":433.92,"rssi":-0.1,"snr":13.2,"noise":-13.3}`,
+			ExpectedLines: []string{
+				"telemetry,device=Acurite-Tower/1234/A,field=battery_ok,sensor=rtl433 floatValue=1",
+				"telemetry,device=Acurite-Tower/1234/A,field=temperature_C,sensor=rtl433,unit=°C floatValue=21.5",
+				"telemetry,device=Acurite-Tower/1234/A,field=humidity,sensor=rtl433,unit=% floatValue=45",
+				"rtl433,device=Acurite-Tower/1234/A,model=Acurite-Tower,receiver=raspberrypi freq=433.92,noise=-13.3,rssi=-0.1,snr=13.2",
+			},
+			ExpectedTimeStamp: time.Date(2025, time.May, 2, 16, 0, 0, 0, time.Local),
+		}, {
+			// time with zone
+			Topic:   "rtl_433/raspberrypi/events",
+			Payload: `{"time":"2025-05-02 18:00:00+0200","model":"Acurite-Tower","id":1234,"channel":"A","temperature_C":21.5}`,
+			ExpectedLines: []string{
+				"telemetry,device=Acurite-Tower/1234/A,field=temperature_C,sensor=rtl433,unit=°C floatValue=21.5",
+			},
+			ExpectedTimeStamp: expectedTimeStamp,
+		}, {
+			// iso time with zone, without channel and level
+			Topic:   "rtl_433/raspberrypi/events",
+			Payload: `{"time":"2025-05-02T18:00:00+0200","model":"Fineoffset-WH24","id":140,"battery_ok":1,"temperature_C":11.2,"wind_avg_m_s":1.4,"wind_dir_deg":225,"rain_mm":3.6,"light_lux":12500}`,
+			ExpectedLines: []string{
+				"telemetry,device=Fineoffset-WH24/140,field=battery_ok,sensor=rtl433 floatValue=1",
+				"telemetry,device=Fineoffset-WH24/140,field=temperature_C,sensor=rtl433,unit=°C floatValue=11.2",
+				"telemetry,device=Fineoffset-WH24/140,field=wind_avg_m_s,sensor=rtl433,unit=m/s floatValue=1.4",
+				"telemetry,device=Fineoffset-WH24/140,field=wind_dir_deg,sensor=rtl433,unit=° floatValue=225",
+				"telemetry,device=Fineoffset-WH24/140,field=rain_mm,sensor=rtl433,unit=mm floatValue=3.6",
+				"telemetry,device=Fineoffset-WH24/140,field=light_lux,sensor=rtl433,unit=lux floatValue=12500",
+			},
+			ExpectedTimeStamp: expectedTimeStamp,
+		}, {
+			// unix time, string values
+			Topic:   "rtl_433/raspberrypi/events",
+			Payload: `{"time":"1746201600","model":"Generic-Remote","id":"a5f3","cmd":"open","tristate":"X01Z"}`,
+			ExpectedLines: []string{
+				"telemetry,device=Generic-Remote/a5f3,field=cmd,sensor=rtl433 stringValue=\"open\"",
+				"telemetry,device=Generic-Remote/a5f3,field=tristate,sensor=rtl433 stringValue=\"X01Z\"",
+			},
+			ExpectedTimeStamp: expectedTimeStamp,
+		}, {
+			// relative time
+			Topic:   "rtl_433/raspberrypi/events",
+			Payload: `{"time":"@0.262144s","model":"Nexus-TH","id":77,"channel":1,"temperature_C":4.1}`,
+			ExpectedLines: []string{
+				"telemetry,device=Nexus-TH/77/1,field=temperature_C,sensor=rtl433,unit=°C floatValue=4.1",
+			},
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:             "rtl_433/raspberrypi/events",
+			Payload:           `{"time":"yesterday","model":"Nexus-TH","id":77,"channel":1,"temperature_C":4.1}`,
+			ExpectedLines:     []string{},
+			ExpectedError:     true,
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:             "rtl_433/raspberrypi/events",
+			Payload:           `{"temperature_C":4.1}`,
+			ExpectedLines:     []string{},
+			ExpectedError:     true,
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:             "rtl_433/raspberrypi/events",
+			Payload:           `{"model":"Nexus-TH","id":77,"rssi":-12.1}`,
+			ExpectedLines:     []string{},
+			ExpectedError:     true,
+			ExpectedTimeStamp: now,
+		},
+	}
+
+	if h, err := GetHandler("rtl433"); err != nil {
+		t.Errorf("did not expect an error while getting handler: %s", err)
+	} else {
+		testStimuliResponse(t, mockCtrl, mockConfig, mockTMConfig, h, stimuli)
+	}
+}
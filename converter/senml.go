@@ -0,0 +1,165 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/pkg/errors"
+	"math"
+	"strings"
+	"time"
+)
+
+// senmlRecord is a record of a SenML JSON pack as defined by RFC 8428
+type senmlRecord struct {
+	BaseName  *string  `json:"bn"`
+	BaseTime  *float64 `json:"bt"`
+	BaseUnit  *string  `json:"bu"`
+	BaseValue *float64 `json:"bv"`
+	BaseSum   *float64 `json:"bs"`
+	Name      string   `json:"n"`
+	Unit      string   `json:"u"`
+	Value     *float64 `json:"v"`
+	StringVal *string  `json:"vs"`
+	BoolVal   *bool    `json:"vb"`
+	DataVal   *string  `json:"vd"`
+	Sum       *float64 `json:"s"`
+	Time      float64  `json:"t"`
+}
+
+// senmlRelativeTimeLimit is the time below which SenML times are relative to now
+const senmlRelativeTimeLimit = 1 << 28
+
+func init() {
+	registerHandler("senml", senmlHandler)
+}
+
+// parses SenML JSON packs and writes one point per value, e.g.
+//
+//	[
+//	  {"bn":"urn:dev:ow:10e2073a01080063:","bt":1.320067464e+09,"n":"temperature","u":"Cel","v":23.1},
+//	  {"n":"humidity","u":"%RH","v":45.2},
+//	  {"n":"door","vb":false,"t":-5},
+//	  {"n":"energy","u":"kWh","s":1043.2}
+//	]
+//
+// The base name is used as device when given, otherwise the device given by the topic matcher is used.
+func senmlHandler(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) error {
+	// use our time
+	receivedAt := receiveTime(input)
+
+	// parse topic
+	topicDevice, err := tm.MatchDevice(input.Topic())
+	if err != nil {
+		return fmt.Errorf("cannot extract device from topic='%s' err=%s", input.Topic(), err)
+	}
+
+	// parse payload
+	payload := bytes.TrimSpace(input.Payload())
+	if len(payload) > 0 && payload[0] == '{' {
+		// accept a single record not wrapped in a pack
+		payload = append(append([]byte{'['}, payload...), ']')
+	}
+	var pack []senmlRecord
+	if err := json.Unmarshal(payload, &pack); err != nil {
+		return fmt.Errorf("cannot json decode: %s", err)
+	}
+
+	// send points
+	count := 0
+
+	// base values apply to all following records until they are redefined
+	var baseName, baseUnit string
+	var baseTime, baseValue, baseSum float64
+	for _, r := range pack {
+		if r.BaseName != nil {
+			baseName = *r.BaseName
+		}
+		if r.BaseTime != nil {
+			baseTime = *r.BaseTime
+		}
+		if r.BaseUnit != nil {
+			baseUnit = *r.BaseUnit
+		}
+		if r.BaseValue != nil {
+			baseValue = *r.BaseValue
+		}
+		if r.BaseSum != nil {
+			baseSum = *r.BaseSum
+		}
+
+		device, field := senmlResolveName(topicDevice, baseName, r.Name)
+
+		unit := r.Unit
+		if unit == "" {
+			unit = baseUnit
+		}
+		var unitPtr *string
+		if unit != "" {
+			unitPtr = &unit
+		}
+
+		m := telemetryOutputMessage{
+			timeStamp: senmlResolveTime(receivedAt, baseTime+r.Time),
+			device:    device,
+			field:     field,
+			unit:      unitPtr,
+			sensor:    "senml",
+		}
+
+		switch {
+		case r.Value != nil:
+			v := baseValue + *r.Value
+			m.floatValue = &v
+		case r.StringVal != nil:
+			m.stringValue = r.StringVal
+		case r.BoolVal != nil:
+			m.boolValue = r.BoolVal
+		case r.DataVal != nil:
+			m.stringValue = r.DataVal
+		}
+		if m.floatValue != nil || m.stringValue != nil || m.boolValue != nil {
+			count += 1
+			outputFunc(m)
+		}
+
+		if r.Sum != nil {
+			v := baseSum + *r.Sum
+			s := m
+			s.field = field + "_sum"
+			s.floatValue, s.stringValue, s.boolValue = &v, nil, nil
+			count += 1
+			outputFunc(s)
+		}
+	}
+
+	// any points sent?
+	if count < 1 {
+		return errors.New("could not extract any sensor data")
+	}
+
+	return nil
+}
+
+// senmlResolveName returns the device and field of a record; the base name is used as device
+// without the separator it usually ends with, e.g. urn:dev:ow:10e2073a01080063: or sensor-1/;
+// records without a name, whose name is given by the base name alone, use value as field
+func senmlResolveName(topicDevice, baseName, name string) (device, field string) {
+	device = strings.TrimRight(baseName, ":/.-_")
+	if device == "" {
+		device = topicDevice
+	}
+	field = name
+	if field == "" {
+		field = "value"
+	}
+	return
+}
+
+// senmlResolveTime converts a resolved SenML time; values below 2**28 are relative to the time the message was received
+func senmlResolveTime(receivedAt time.Time, t float64) time.Time {
+	if t < senmlRelativeTimeLimit {
+		return receivedAt.Add(time.Duration(t * float64(time.Second)))
+	}
+	sec, frac := math.Modf(t)
+	return time.Unix(int64(sec), int64(math.Round(frac*1e9)))
+}
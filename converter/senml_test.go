@@ -0,0 +1,94 @@
+package converter
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/koestler/go-mqtt-to-influx/v2/converter/mock"
+	"testing"
+	"time"
+)
+
+func TestSenml(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockConfig := converter_mock.NewMockConfig(mockCtrl)
+	mockConfig.EXPECT().Name().Return("test-converter").AnyTimes()
+
+	mockTMConfig := converter_mock.NewMockTopicMatcherConfig(mockCtrl)
+	mockTMConfig.EXPECT().Topic().Return("senml/%Device%").AnyTimes()
+	mockTMConfig.EXPECT().Device().Return("+").AnyTimes()
+	mockTMConfig.EXPECT().DeviceIsDynamic().Return(true).AnyTimes()
+
+	now := time.Now()
+
+	stimuli := TestStimuliResponse{
+		{
+			Topic: "senml/gateway-0",
+			Payload: `[
+  {"bn":"urn:dev:ow:10e2073a01080063:","bt":1.7462016e+09,"n":"temperature","u":"Cel","v":23.1},
+  {"n":"humidity","u":"%RH","v":45.2},
+  {"n":"door","vb":false},
+  {"n":"firmware","vs":"1.4.2"},
+  {"n":"raw","vd":"aGVsbG8"}
+]`,
+			ExpectedLines: []string{
+				"telemetry,device=urn:dev:ow:10e2073a01080063,field=temperature,sensor=senml,unit=Cel floatValue=23.1",
+				"telemetry,device=urn:dev:ow:10e2073a01080063,field=humidity,sensor=senml,unit=%RH floatValue=45.2",
+				"telemetry,device=urn:dev:ow:10e2073a01080063,field=door,sensor=senml boolValue=false",
+				"telemetry,device=urn:dev:ow:10e2073a01080063,field=firmware,sensor=senml stringValue=\"1.4.2\"",
+				"telemetry,device=urn:dev:ow:10e2073a01080063,field=raw,sensor=senml stringValue=\"aGVsbG8\"",
+			},
+			ExpectedTimeStamp: time.Date(2025, time.May, 2, 16, 0, 0, 0, time.UTC),
+		}, {
+			// absolute time given by the base time and the record time
+			Topic: "senml/gateway-0",
+			Payload: `[
+  {"bn":"meter-1/","bt":1.7462016e+09,"bu":"V","bv":100,"bs":1000,"t":-60,"n":"voltage","v":131.5},
+  {"t":-60,"n":"energy","u":"kWh","s":2.5}
+]`,
+			ExpectedLines: []string{
+				"telemetry,device=meter-1,field=voltage,sensor=senml,unit=V floatValue=231.5",
+				"telemetry,device=meter-1,field=energy_sum,sensor=senml,unit=kWh floatValue=1002.5",
+			},
+			ExpectedTimeStamp: time.Date(2025, time.May, 2, 15, 59, 0, 0, time.UTC),
+		}, {
+			// relative time, no base name
+			Topic: "senml/thermostat-3",
+			Payload: `[
+  {"n":"temperature","u":"Cel","v":21.5,"t":-30},
+  {"n":"setpoint","u":"Cel","v":22,"t":-30}
+]`,
+			ExpectedLines: []string{
+				"telemetry,device=thermostat-3,field=temperature,sensor=senml,unit=Cel floatValue=21.5",
+				"telemetry,device=thermostat-3,field=setpoint,sensor=senml,unit=Cel floatValue=22",
+			},
+			ExpectedTimeStamp: now.Add(-30 * time.Second),
+		}, {
+			// a single record with the name given by the base name alone
+			Topic:   "senml/thermostat-3",
+			Payload: `{"bn":"urn:dev:mac:0024befffe804ff1","u":"Cel","v":19.5}`,
+			ExpectedLines: []string{
+				"telemetry,device=urn:dev:mac:0024befffe804ff1,field=value,sensor=senml,unit=Cel floatValue=19.5",
+			},
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:             "senml/thermostat-3",
+			Payload:           `[{"bn":"thermostat-3/","bt":1.7462016e+09}]`,
+			ExpectedLines:     []string{},
+			ExpectedError:     true,
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:             "senml/thermostat-3",
+			Payload:           `[{"n":"temperature","v":"hot"}]`,
+			ExpectedLines:     []string{},
+			ExpectedError:     true,
+			ExpectedTimeStamp: now,
+		},
+	}
+
+	if h, err := GetHandler("senml"); err != nil {
+		t.Errorf("did not expect an error while getting handler: %s", err)
+	} else {
+		testStimuliResponse(t, mockCtrl, mockConfig, mockTMConfig, h, stimuli)
+	}
+}
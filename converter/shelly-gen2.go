@@ -0,0 +1,159 @@
+package converter
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// shellyGen2RpcMessage is a notification published on <prefix>/events/rpc
+type shellyGen2RpcMessage struct {
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params"`
+}
+
+// shellyGen2Units defines the units of the flattened fields of the components
+var shellyGen2Units = map[string]string{
+	"apower":            "W",
+	"voltage":           "V",
+	"current":           "A",
+	"freq":              "Hz",
+	"aenergy_total":     "Wh",
+	"ret_aenergy_total": "Wh",
+	"temperature_tC":    "°C",
+	"temperature_tF":    "°F",
+	"tC":                "°C",
+	"tF":                "°F",
+	"rh":                "%",
+	"battery_V":         "V",
+	"battery_percent":   "%",
+	"current_pos":       "%",
+	"target_pos":        "%",
+	"brightness":        "%",
+	"rssi":              "dBm",
+	"uptime":            "s",
+	"ram_size":          "B",
+	"ram_free":          "B",
+	"fs_size":           "B",
+	"fs_free":           "B",
+	"total_act":         "Wh",
+	"total_act_ret":     "Wh",
+}
+
+// shellyGen2UnitSuffixes defines the units of the per phase fields of em and emdata components like a_act_power
+var shellyGen2UnitSuffixes = map[string]string{
+	"_current":        "A",
+	"_voltage":        "V",
+	"_act_power":      "W",
+	"_aprt_power":     "VA",
+	"_freq":           "Hz",
+	"_act_energy":     "Wh",
+	"_act_ret_energy": "Wh",
+}
+
+func init() {
+	registerHandler("shelly-gen2", shellyGen2Handler)
+}
+
+// parses the status messages of Shelly Plus / Pro (Gen2 / Gen3) devices and writes one point per numeric or boolean value;
+// the device given by the topic matcher must be <device>/status/<component> or <device>/events/rpc, e.g.
+// - shellyplus1pm-a8032ab12345/status/switch:0 {"id":0,"output":true,"apower":12.3,"voltage":230.7,"aenergy":{"total":12.345}}
+// - shellyplus1pm-a8032ab12345/events/rpc {"method":"NotifyStatus","params":{"ts":1746201600.12,"switch:0":{"id":0,"apower":14.1}}}
+func shellyGen2Handler(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) error {
+	// use our time
+	timeStamp := receiveTime(input)
+
+	// parse topic
+	matched, err := tm.MatchDevice(input.Topic())
+	if err != nil {
+		return fmt.Errorf("cannot extract device from topic='%s' err=%s", input.Topic(), err)
+	}
+
+	output := func(device, component string, values map[string]interface{}) {
+		var walk func(prefix string, values map[string]interface{})
+		walk = func(prefix string, values map[string]interface{}) {
+			for key, value := range values {
+				field := joinFieldPrefix(prefix, key)
+				m := telemetryOutputMessage{
+					timeStamp: timeStamp,
+					device:    device,
+					field:     field,
+					unit:      shellyGen2Unit(field),
+					sensor:    component,
+				}
+				switch v := value.(type) {
+				case map[string]interface{}:
+					walk(field, v)
+					continue
+				case float64:
+					if key == "id" || strings.HasSuffix(key, "_ts") {
+						// the component id is part of the sensor tag, timestamps are not values
+						continue
+					}
+					m.floatValue = &v
+				case bool:
+					m.boolValue = &v
+				default:
+					// strings like the source of the last change and arrays like the energy by minute are not used
+					continue
+				}
+				outputFunc(m)
+			}
+		}
+		walk("", values)
+	}
+
+	if device, component, ok := strings.Cut(matched, "/status/"); ok {
+		// parse payload
+		var values map[string]interface{}
+		if err := json.Unmarshal(input.Payload(), &values); err != nil {
+			return fmt.Errorf("cannot json decode: %s", err)
+		}
+		output(device, component, values)
+		return nil
+	}
+
+	device, ok := strings.CutSuffix(matched, "/events/rpc")
+	if !ok {
+		return fmt.Errorf("expect device='%s' to be <device>/status/<component> or <device>/events/rpc", matched)
+	}
+
+	// parse payload
+	var message shellyGen2RpcMessage
+	if err := json.Unmarshal(input.Payload(), &message); err != nil {
+		return fmt.Errorf("cannot json decode: %s", err)
+	}
+
+	switch message.Method {
+	case "NotifyStatus", "NotifyFullStatus":
+	default:
+		// e.g. NotifyEvent for button presses
+		return nil
+	}
+
+	if ts, ok := message.Params["ts"].(float64); ok {
+		timeStamp = time.UnixMilli(int64(math.Round(ts * 1000)))
+	}
+
+	// NotifyStatus only contains the changed values, NotifyFullStatus all values of all components
+	for component, values := range message.Params {
+		if v, ok := values.(map[string]interface{}); ok {
+			output(device, component, v)
+		}
+	}
+
+	return nil
+}
+
+func shellyGen2Unit(field string) *string {
+	if unit, ok := shellyGen2Units[field]; ok {
+		return &unit
+	}
+	for suffix, unit := range shellyGen2UnitSuffixes {
+		if strings.HasSuffix(field, suffix) {
+			return &unit
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package converter
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/koestler/go-mqtt-to-influx/v2/converter/mock"
+	"testing"
+	"time"
+)
+
+func TestShellyGen2(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockConfig := converter_mock.NewMockConfig(mockCtrl)
+	mockConfig.EXPECT().Name().Return("test-converter").AnyTimes()
+
+	now := time.Now()
+	expectedTimeStamp := time.Date(2025, time.May, 2, 16, 0, 0, 120000000, time.UTC)
+
+	h, err := GetHandler("shelly-gen2")
+	if err != nil {
+		t.Fatalf("did not expect an error while getting handler: %s", err)
+	}
+
+	// status topics
+	mockTMConfig := converter_mock.NewMockTopicMatcherConfig(mockCtrl)
+	mockTMConfig.EXPECT().Topic().Return("shelly/%Device%").AnyTimes()
+	mockTMConfig.EXPECT().Device().Return("+/status/+").AnyTimes()
+	mockTMConfig.EXPECT().DeviceIsDynamic().Return(true).AnyTimes()
+
+	stimuli := TestStimuliResponse{
+		{
+			Topic:   "shelly/shellyplus1pm-a8032ab12345/status/switch:0",
+			Payload: `{"id":0,"source":"init","output":true,"apower":12.3,"voltage":230.7,"freq":50,"current":0.061,"aenergy":{"total":12.345,"by_minute":[0,0,0],"minute_ts":1746201600},"temperature":{"tC":41.2,"tF":106.2}}`,
+			ExpectedLines: []string{
+				"telemetry,device=shellyplus1pm-a8032ab12345,field=output,sensor=switch:0 boolValue=true",
+				"telemetry,device=shellyplus1pm-a8032ab12345,field=apower,sensor=switch:0,unit=W floatValue=12.3",
+				"telemetry,device=shellyplus1pm-a8032ab12345,field=voltage,sensor=switch:0,unit=V floatValue=230.7",
+				"telemetry,device=shellyplus1pm-a8032ab12345,field=freq,sensor=switch:0,unit=Hz floatValue=50",
+				"telemetry,device=shellyplus1pm-a8032ab12345,field=current,sensor=switch:0,unit=A floatValue=0.061",
+				"telemetry,device=shellyplus1pm-a8032ab12345,field=aenergy_total,sensor=switch:0,unit=Wh floatValue=12.345",
+				"telemetry,device=shellyplus1pm-a8032ab12345,field=temperature_tC,sensor=switch:0,unit=°C floatValue=41.2",
+				"telemetry,device=shellyplus1pm-a8032ab12345,field=temperature_tF,sensor=switch:0,unit=°F floatValue=106.2",
+			},
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:   "shelly/shellypro3em-08f9e0a12345/status/em:0",
+			Payload: `{"id":0,"a_current":1.2,"a_voltage":231.1,"a_act_power":250.4,"a_aprt_power":277.3,"a_pf":0.9,"n_current":null,"total_act_power":250.4}`,
+			ExpectedLines: []string{
+				"telemetry,device=shellypro3em-08f9e0a12345,field=a_current,sensor=em:0,unit=A floatValue=1.2",
+				"telemetry,device=shellypro3em-08f9e0a12345,field=a_voltage,sensor=em:0,unit=V floatValue=231.1",
+				"telemetry,device=shellypro3em-08f9e0a12345,field=a_act_power,sensor=em:0,unit=W floatValue=250.4",
+				"telemetry,device=shellypro3em-08f9e0a12345,field=a_aprt_power,sensor=em:0,unit=VA floatValue=277.3",
+				"telemetry,device=shellypro3em-08f9e0a12345,field=a_pf,sensor=em:0 floatValue=0.9",
+				"telemetry,device=shellypro3em-08f9e0a12345,field=total_act_power,sensor=em:0,unit=W floatValue=250.4",
+			},
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:             "shelly/shellyplus1pm-a8032ab12345/status/switch:0",
+			Payload:           `{"id":0,`,
+			ExpectedLines:     []string{},
+			ExpectedError:     true,
+			ExpectedTimeStamp: now,
+		},
+	}
+	testStimuliResponse(t, mockCtrl, mockConfig, mockTMConfig, h, stimuli)
+
+	// rpc notifications
+	mockTMConfig = converter_mock.NewMockTopicMatcherConfig(mockCtrl)
+	mockTMConfig.EXPECT().Topic().Return("shelly/%Device%").AnyTimes()
+	mockTMConfig.EXPECT().Device().Return("+/events/rpc").AnyTimes()
+	mockTMConfig.EXPECT().DeviceIsDynamic().Return(true).AnyTimes()
+
+	stimuli = TestStimuliResponse{
+		{
+			Topic:   "shelly/shellyplus1pm-a8032ab12345/events/rpc",
+			Payload: `{"src":"shellyplus1pm-a8032ab12345","dst":"shelly/shellyplus1pm-a8032ab12345/events","method":"NotifyStatus","params":{"ts":1746201600.12,"switch:0":{"id":0,"apower":14.1}}}`,
+			ExpectedLines: []string{
+				"telemetry,device=shellyplus1pm-a8032ab12345,field=apower,sensor=switch:0,unit=W floatValue=14.1",
+			},
+			ExpectedTimeStamp: expectedTimeStamp,
+		}, {
+			Topic:   "shelly/shellyplusht-c049ef812345/events/rpc",
+			Payload: `{"src":"shellyplusht-c049ef812345","method":"NotifyFullStatus","params":{"ts":1746201600.12,"temperature:0":{"id":0,"tC":21.4,"tF":70.5},"humidity:0":{"id":0,"rh":48.2},"devicepower:0":{"id":0,"battery":{"V":5.71,"percent":88},"external":{"present":false}},"wifi":{"sta_ip":"192.168.1.42","status":"got ip","ssid":"home","rssi":-61}}}`,
+			ExpectedLines: []string{
+				"telemetry,device=shellyplusht-c049ef812345,field=tC,sensor=temperature:0,unit=°C floatValue=21.4",
+				"telemetry,device=shellyplusht-c049ef812345,field=tF,sensor=temperature:0,unit=°F floatValue=70.5",
+				"telemetry,device=shellyplusht-c049ef812345,field=rh,sensor=humidity:0,unit=% floatValue=48.2",
+				"telemetry,device=shellyplusht-c049ef812345,field=battery_V,sensor=devicepower:0,unit=V floatValue=5.71",
+				"telemetry,device=shellyplusht-c049ef812345,field=battery_percent,sensor=devicepower:0,unit=% floatValue=88",
+				"telemetry,device=shellyplusht-c049ef812345,field=external_present,sensor=devicepower:0 boolValue=false",
+				"telemetry,device=shellyplusht-c049ef812345,field=rssi,sensor=wifi,unit=dBm floatValue=-61",
+			},
+			ExpectedTimeStamp: expectedTimeStamp,
+		}, {
+			// events are ignored
+			Topic:             "shelly/shellyplusi4-d4d4da812345/events/rpc",
+			Payload:           `{"src":"shellyplusi4-d4d4da812345","method":"NotifyEvent","params":{"ts":1746201600.12,"events":[{"component":"input:0","id":0,"event":"single_push","ts":1746201600.12}]}}`,
+			ExpectedLines:     []string{},
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:             "shelly/shellyplus1pm-a8032ab12345/events/rpc",
+			Payload:           `not json`,
+			ExpectedLines:     []string{},
+			ExpectedError:     true,
+			ExpectedTimeStamp: now,
+		},
+	}
+	testStimuliResponse(t, mockCtrl, mockConfig, mockTMConfig, h, stimuli)
+}
@@ -0,0 +1,153 @@
+package converter
+
+import (
+	"fmt"
+	"google.golang.org/protobuf/encoding/protowire"
+	"math"
+)
+
+// sparkplugPayload holds the parts of the Sparkplug B protobuf payload used by the converter, see
+// https://github.com/eclipse/tahu/blob/master/sparkplug_b/sparkplug_b.proto
+type sparkplugPayload struct {
+	timestamp *uint64 // ms since epoch
+	metrics   []sparkplugMetric
+	seq       *uint64
+}
+
+type sparkplugMetric struct {
+	name      string
+	alias     *uint64
+	timestamp *uint64 // ms since epoch
+	datatype  uint32
+	isNull    bool
+
+	// the value is stored in one of these depending on the wire type used
+	intValue    *uint64 // int_value, long_value and boolean_value
+	floatValue  *float32
+	doubleValue *float64
+	stringValue *string
+}
+
+// Sparkplug B data types
+const (
+	sparkplugInt8     = 1
+	sparkplugInt16    = 2
+	sparkplugInt32    = 3
+	sparkplugInt64    = 4
+	sparkplugUInt8    = 5
+	sparkplugUInt16   = 6
+	sparkplugUInt32   = 7
+	sparkplugUInt64   = 8
+	sparkplugFloat    = 9
+	sparkplugDouble   = 10
+	sparkplugBoolean  = 11
+	sparkplugString   = 12
+	sparkplugDateTime = 13
+	sparkplugText     = 14
+	sparkplugUUID     = 15
+)
+
+func decodeSparkplugPayload(b []byte) (ret sparkplugPayload, err error) {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return ret, fmt.Errorf("invalid tag: %s", protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			v, m := protowire.ConsumeVarint(b)
+			if m < 0 {
+				return ret, fmt.Errorf("invalid timestamp: %s", protowire.ParseError(m))
+			}
+			ret.timestamp = &v
+			n = m
+		case num == 2 && typ == protowire.BytesType:
+			v, m := protowire.ConsumeBytes(b)
+			if m < 0 {
+				return ret, fmt.Errorf("invalid metric: %s", protowire.ParseError(m))
+			}
+			metric, err := decodeSparkplugMetric(v)
+			if err != nil {
+				return ret, fmt.Errorf("invalid metric: %s", err)
+			}
+			ret.metrics = append(ret.metrics, metric)
+			n = m
+		case num == 3 && typ == protowire.VarintType:
+			v, m := protowire.ConsumeVarint(b)
+			if m < 0 {
+				return ret, fmt.Errorf("invalid seq: %s", protowire.ParseError(m))
+			}
+			ret.seq = &v
+			n = m
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return ret, fmt.Errorf("invalid field %d: %s", num, protowire.ParseError(n))
+			}
+		}
+		b = b[n:]
+	}
+	return
+}
+
+func decodeSparkplugMetric(b []byte) (ret sparkplugMetric, err error) {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return ret, fmt.Errorf("invalid tag: %s", protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			var v []byte
+			v, n = protowire.ConsumeBytes(b)
+			ret.name = string(v)
+		case num == 2 && typ == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(b)
+			ret.alias = &v
+		case num == 3 && typ == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(b)
+			ret.timestamp = &v
+		case num == 4 && typ == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(b)
+			ret.datatype = uint32(v)
+		case num == 7 && typ == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(b)
+			ret.isNull = v != 0
+		case (num == 10 || num == 11 || num == 14) && typ == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(b)
+			ret.intValue = &v
+		case num == 12 && typ == protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(b)
+			f := math.Float32frombits(v)
+			ret.floatValue = &f
+		case num == 13 && typ == protowire.Fixed64Type:
+			var v uint64
+			v, n = protowire.ConsumeFixed64(b)
+			f := math.Float64frombits(v)
+			ret.doubleValue = &f
+		case num == 15 && typ == protowire.BytesType:
+			var v []byte
+			v, n = protowire.ConsumeBytes(b)
+			s := string(v)
+			ret.stringValue = &s
+		default:
+			// metadata, properties, bytes, data sets, templates and extensions are not used
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return ret, fmt.Errorf("invalid field %d: %s", num, protowire.ParseError(n))
+		}
+		b = b[n:]
+	}
+	return
+}
@@ -0,0 +1,284 @@
+package converter
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sparkplugNode holds what the birth certificates of an edge node told us
+type sparkplugNode struct {
+	aliases   map[uint64]sparkplugAlias
+	datatypes map[sparkplugMetricKey]uint32
+	devices   map[string]bool
+	bdSeq     *uint64
+}
+
+// sparkplugMetricKey identifies a metric by name, data messages may omit the datatype of metrics sent by name
+type sparkplugMetricKey struct {
+	device string
+	name   string
+}
+
+type sparkplugAlias struct {
+	name     string
+	datatype uint32
+}
+
+var (
+	// sparkplugNodes holds the state of the edge nodes by converter name, group and edge node id
+	sparkplugNodes      = make(map[string]*sparkplugNode)
+	sparkplugNodesMutex sync.Mutex
+)
+
+func init() {
+	registerHandler("sparkplug-b", sparkplugBHandler)
+}
+
+// parses Sparkplug B messages and writes one telemetry point per metric
+// the device given by the topic matcher must be <group>/<message type>/<edge node>[/<device>], e.g.
+// - spBv1.0/plant1/NBIRTH/plc0
+// - spBv1.0/plant1/DDATA/plc0/pump3
+func sparkplugBHandler(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) error {
+	// parse topic
+	matched, err := tm.MatchDevice(input.Topic())
+	if err != nil {
+		return fmt.Errorf("cannot extract device from topic='%s' err=%s", input.Topic(), err)
+	}
+	parts := strings.Split(matched, "/")
+	if len(parts) != 3 && len(parts) != 4 {
+		return fmt.Errorf("cannot extract group, message type and edge node from device='%s'", matched)
+	}
+	group, messageType, edgeNode := parts[0], parts[1], parts[2]
+	nodeDevice := group + "/" + edgeNode
+	device := nodeDevice
+	if len(parts) == 4 {
+		device += "/" + parts[3]
+	}
+
+	switch messageType {
+	case "NBIRTH", "DBIRTH", "NDATA", "DDATA", "NDEATH", "DDEATH":
+	default:
+		// e.g. NCMD and DCMD sent to the edge nodes
+		return nil
+	}
+
+	// parse payload
+	payload, err := decodeSparkplugPayload(input.Payload())
+	if err != nil {
+		return fmt.Errorf("cannot protobuf decode: %s", err)
+	}
+
+	timeStamp := receiveTime(input)
+	if payload.timestamp != nil {
+		timeStamp = time.UnixMilli(int64(*payload.timestamp))
+	}
+
+	nodeKey := c.Name() + "," + nodeDevice
+	sparkplugNodesMutex.Lock()
+	defer sparkplugNodesMutex.Unlock()
+
+	node := sparkplugNodes[nodeKey]
+	switch messageType {
+	case "NBIRTH":
+		// a new birth certificate replaces all aliases including the ones of the devices
+		node = &sparkplugNode{
+			aliases:   make(map[uint64]sparkplugAlias),
+			datatypes: make(map[sparkplugMetricKey]uint32),
+			devices:   make(map[string]bool),
+			bdSeq:     sparkplugBdSeq(payload),
+		}
+		sparkplugNodes[nodeKey] = node
+	case "NDEATH":
+		// a death certificate of an earlier session must not mark the current one offline
+		if bdSeq := sparkplugBdSeq(payload); node != nil && node.bdSeq != nil && bdSeq != nil && *bdSeq != *node.bdSeq {
+			return nil
+		}
+
+		// the death certificate is registered as will when the edge node connects; its timestamp is outdated
+		timeStamp = receiveTime(input)
+		outputFunc(availabilityOutputMessage{
+			timeStamp: timeStamp,
+			device:    device,
+			value:     false,
+		})
+		// all devices of an edge node are offline as well
+		if node != nil {
+			for d := range node.devices {
+				outputFunc(availabilityOutputMessage{
+					timeStamp: timeStamp,
+					device:    d,
+					value:     false,
+				})
+			}
+		}
+		delete(sparkplugNodes, nodeKey)
+		return nil
+	case "DDEATH":
+		outputFunc(availabilityOutputMessage{
+			timeStamp: timeStamp,
+			device:    device,
+			value:     false,
+		})
+		if node != nil {
+			delete(node.devices, device)
+		}
+		return nil
+	}
+
+	if node == nil {
+		return fmt.Errorf("no birth certificate received yet for edge node='%s'", nodeDevice)
+	}
+
+	if messageType == "NBIRTH" || messageType == "DBIRTH" {
+		if messageType == "DBIRTH" {
+			node.devices[device] = true
+		}
+		outputFunc(availabilityOutputMessage{
+			timeStamp: timeStamp,
+			device:    device,
+			value:     true,
+		})
+
+		// birth certificates define the aliases and datatypes used by the data messages
+		for _, metric := range payload.metrics {
+			if metric.alias != nil && metric.name != "" {
+				node.aliases[*metric.alias] = sparkplugAlias{name: metric.name, datatype: metric.datatype}
+			}
+			if metric.name != "" && metric.datatype != 0 {
+				node.datatypes[sparkplugMetricKey{device: device, name: metric.name}] = metric.datatype
+			}
+		}
+	}
+
+	// send points
+	count := 0
+	for _, metric := range payload.metrics {
+		name, datatype := metric.name, metric.datatype
+		if metric.alias != nil {
+			if alias, ok := node.aliases[*metric.alias]; ok {
+				if name == "" {
+					name = alias.name
+				}
+				if datatype == 0 {
+					datatype = alias.datatype
+				}
+			}
+		}
+		if name == "" {
+			if metric.alias == nil {
+				continue
+			}
+			log.Printf("sparkplug-b[%s]: unknown alias=%d of device='%s'", c.Name(), *metric.alias, device)
+			continue
+		}
+		if name == "bdSeq" || metric.isNull {
+			continue
+		}
+		if datatype == 0 {
+			datatype = node.datatypes[sparkplugMetricKey{device: device, name: name}]
+		}
+
+		m := telemetryOutputMessage{
+			timeStamp: timeStamp,
+			device:    device,
+			field:     name,
+			sensor:    "sparkplug-b",
+		}
+		if metric.timestamp != nil {
+			m.timeStamp = time.UnixMilli(int64(*metric.timestamp))
+		}
+		if !sparkplugSetValue(&m, datatype, metric) {
+			continue
+		}
+		count += 1
+		outputFunc(m)
+	}
+
+	// any points sent?
+	if count < 1 && (messageType == "NDATA" || messageType == "DDATA") {
+		return errors.New("could not extract any sensor data")
+	}
+
+	return nil
+}
+
+// sparkplugBdSeq returns the birth / death sequence number used to match a death certificate to its birth
+func sparkplugBdSeq(payload sparkplugPayload) *uint64 {
+	for _, metric := range payload.metrics {
+		if metric.name == "bdSeq" && metric.intValue != nil {
+			return metric.intValue
+		}
+	}
+	return nil
+}
+
+// sparkplugSetValue stores the value of the metric using the field type matching the Sparkplug data type;
+// false is returned for data types not supported like data sets, templates and bytes
+func sparkplugSetValue(m *telemetryOutputMessage, datatype uint32, metric sparkplugMetric) bool {
+	setInt := func(v int64) {
+		m.intValue = &v
+	}
+
+	switch datatype {
+	case sparkplugInt8, sparkplugInt16, sparkplugInt32:
+		if metric.intValue == nil {
+			return false
+		}
+		// int_value is an uint32 holding the two's complement of the signed value
+		setInt(int64(int32(uint32(*metric.intValue))))
+	case sparkplugInt64:
+		if metric.intValue == nil {
+			return false
+		}
+		setInt(int64(*metric.intValue))
+	case sparkplugUInt8, sparkplugUInt16, sparkplugUInt32:
+		if metric.intValue == nil {
+			return false
+		}
+		setInt(int64(uint32(*metric.intValue)))
+	case sparkplugUInt64:
+		if metric.intValue == nil || *metric.intValue > math.MaxInt64 {
+			return false
+		}
+		setInt(int64(*metric.intValue))
+	case sparkplugDateTime:
+		if metric.intValue == nil {
+			return false
+		}
+		unit := "ms"
+		m.unit = &unit
+		setInt(int64(*metric.intValue))
+	case sparkplugFloat, sparkplugDouble:
+		var v float64
+		switch {
+		case metric.floatValue != nil:
+			// use the shortest decimal representation of the float32, e.g. 0.1 instead of 0.10000000149011612
+			v, _ = strconv.ParseFloat(strconv.FormatFloat(float64(*metric.floatValue), 'g', -1, 32), 64)
+		case metric.doubleValue != nil:
+			v = *metric.doubleValue
+		default:
+			return false
+		}
+		m.floatValue = &v
+	case sparkplugBoolean:
+		if metric.intValue == nil {
+			return false
+		}
+		v := *metric.intValue != 0
+		m.boolValue = &v
+	case sparkplugString, sparkplugText, sparkplugUUID:
+		if metric.stringValue == nil {
+			return false
+		}
+		m.stringValue = metric.stringValue
+	default:
+		return false
+	}
+	return true
+}
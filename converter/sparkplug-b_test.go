@@ -0,0 +1,229 @@
+package converter
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/koestler/go-mqtt-to-influx/v2/converter/mock"
+	"google.golang.org/protobuf/encoding/protowire"
+	"math"
+	"testing"
+	"time"
+)
+
+// sparkplugTestMetric encodes a metric; a name of "" or an alias of 0 omits the field, so does a nil value
+func sparkplugTestMetric(name string, alias uint64, datatype uint32, value interface{}) []byte {
+	var b []byte
+	if name != "" {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, name)
+	}
+	if alias != 0 {
+		b = protowire.AppendTag(b, 2, protowire.VarintType)
+		b = protowire.AppendVarint(b, alias)
+	}
+	if datatype != 0 {
+		b = protowire.AppendTag(b, 4, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(datatype))
+	}
+	switch v := value.(type) {
+	case int32:
+		b = protowire.AppendTag(b, 10, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(uint32(v)))
+	case uint64:
+		b = protowire.AppendTag(b, 11, protowire.VarintType)
+		b = protowire.AppendVarint(b, v)
+	case float32:
+		b = protowire.AppendTag(b, 12, protowire.Fixed32Type)
+		b = protowire.AppendFixed32(b, math.Float32bits(v))
+	case float64:
+		b = protowire.AppendTag(b, 13, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(v))
+	case bool:
+		b = protowire.AppendTag(b, 14, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(v))
+	case string:
+		b = protowire.AppendTag(b, 15, protowire.BytesType)
+		b = protowire.AppendString(b, v)
+	}
+	return b
+}
+
+func sparkplugTestPayload(timeStamp time.Time, metrics ...[]byte) string {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(timeStamp.UnixMilli()))
+	for _, m := range metrics {
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendBytes(b, m)
+	}
+	b = protowire.AppendTag(b, 3, protowire.VarintType)
+	b = protowire.AppendVarint(b, 0)
+	return string(b)
+}
+
+func TestSparkplugB(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockConfig := converter_mock.NewMockConfig(mockCtrl)
+	mockConfig.EXPECT().Name().Return("test-converter").AnyTimes()
+
+	mockNodeTMConfig := converter_mock.NewMockTopicMatcherConfig(mockCtrl)
+	mockNodeTMConfig.EXPECT().Topic().Return("spBv1.0/%Device%").AnyTimes()
+	mockNodeTMConfig.EXPECT().Device().Return("+/+/+").AnyTimes()
+	mockNodeTMConfig.EXPECT().DeviceIsDynamic().Return(true).AnyTimes()
+
+	mockDeviceTMConfig := converter_mock.NewMockTopicMatcherConfig(mockCtrl)
+	mockDeviceTMConfig.EXPECT().Topic().Return("spBv1.0/%Device%").AnyTimes()
+	mockDeviceTMConfig.EXPECT().Device().Return("+/+/+/+").AnyTimes()
+	mockDeviceTMConfig.EXPECT().DeviceIsDynamic().Return(true).AnyTimes()
+
+	birthTimeStamp := time.Date(2025, time.May, 6, 7, 0, 0, 0, time.UTC)
+	dataTimeStamp := time.Date(2025, time.May, 6, 7, 0, 10, 0, time.UTC)
+
+	nodeStimuli := TestStimuliResponse{
+		{
+			Topic:             "spBv1.0/plant1/NDATA/plc0",
+			Payload:           sparkplugTestPayload(dataTimeStamp, sparkplugTestMetric("", 1, 0, uint64(1))),
+			ExpectedLines:     []string{},
+			ExpectedError:     true,
+			ExpectedTimeStamp: dataTimeStamp,
+		}, {
+			Topic: "spBv1.0/plant1/NBIRTH/plc0",
+			Payload: sparkplugTestPayload(birthTimeStamp,
+				sparkplugTestMetric("bdSeq", 0, sparkplugInt64, uint64(4)),
+				sparkplugTestMetric("Node Control/Rebirth", 1, sparkplugBoolean, false),
+				sparkplugTestMetric("Properties/Firmware", 2, sparkplugString, "1.2.3"),
+				sparkplugTestMetric("Uptime", 3, sparkplugUInt64, uint64(3600)),
+			),
+			ExpectedLines: []string{
+				"availability,device=plant1/plc0 boolValue=true",
+				"telemetry,device=plant1/plc0,field=Node\\ Control/Rebirth,sensor=sparkplug-b boolValue=false",
+				"telemetry,device=plant1/plc0,field=Properties/Firmware,sensor=sparkplug-b stringValue=\"1.2.3\"",
+				"telemetry,device=plant1/plc0,field=Uptime,sensor=sparkplug-b intValue=3600i",
+			},
+			ExpectedTimeStamp: birthTimeStamp,
+		}, {
+			Topic:   "spBv1.0/plant1/NDATA/plc0",
+			Payload: sparkplugTestPayload(dataTimeStamp, sparkplugTestMetric("", 3, 0, uint64(3610))),
+			ExpectedLines: []string{
+				"telemetry,device=plant1/plc0,field=Uptime,sensor=sparkplug-b intValue=3610i",
+			},
+			ExpectedTimeStamp: dataTimeStamp,
+		}, {
+			Topic:             "spBv1.0/plant1/NCMD/plc0",
+			Payload:           sparkplugTestPayload(dataTimeStamp, sparkplugTestMetric("Node Control/Rebirth", 0, sparkplugBoolean, true)),
+			ExpectedLines:     []string{},
+			ExpectedTimeStamp: dataTimeStamp,
+		}, {
+			Topic:             "spBv1.0/plant1/NDATA/plc0",
+			Payload:           "\x0a\xff",
+			ExpectedLines:     []string{},
+			ExpectedError:     true,
+			ExpectedTimeStamp: dataTimeStamp,
+		},
+	}
+
+	deviceStimuli := TestStimuliResponse{
+		{
+			Topic: "spBv1.0/plant1/DBIRTH/plc0/pump3",
+			Payload: sparkplugTestPayload(birthTimeStamp,
+				sparkplugTestMetric("Temperature", 10, sparkplugFloat, float32(21.1)),
+				sparkplugTestMetric("Pressure", 11, sparkplugDouble, 1.25),
+				sparkplugTestMetric("Offset", 12, sparkplugInt16, int32(-3)),
+				sparkplugTestMetric("Running", 13, sparkplugBoolean, true),
+				sparkplugTestMetric("LastStart", 14, sparkplugDateTime, uint64(1746514800000)),
+			),
+			ExpectedLines: []string{
+				"availability,device=plant1/plc0/pump3 boolValue=true",
+				"telemetry,device=plant1/plc0/pump3,field=Temperature,sensor=sparkplug-b floatValue=21.1",
+				"telemetry,device=plant1/plc0/pump3,field=Pressure,sensor=sparkplug-b floatValue=1.25",
+				"telemetry,device=plant1/plc0/pump3,field=Offset,sensor=sparkplug-b intValue=-3i",
+				"telemetry,device=plant1/plc0/pump3,field=Running,sensor=sparkplug-b boolValue=true",
+				"telemetry,device=plant1/plc0/pump3,field=LastStart,sensor=sparkplug-b,unit=ms intValue=1746514800000i",
+			},
+			ExpectedTimeStamp: birthTimeStamp,
+		}, {
+			Topic: "spBv1.0/plant1/DBIRTH/plc0/pump4",
+			Payload: sparkplugTestPayload(birthTimeStamp,
+				sparkplugTestMetric("Speed", 20, sparkplugUInt32, uint64(1450)),
+			),
+			ExpectedLines: []string{
+				"availability,device=plant1/plc0/pump4 boolValue=true",
+				"telemetry,device=plant1/plc0/pump4,field=Speed,sensor=sparkplug-b intValue=1450i",
+			},
+			ExpectedTimeStamp: birthTimeStamp,
+		}, {
+			Topic: "spBv1.0/plant1/DDATA/plc0/pump3",
+			Payload: sparkplugTestPayload(dataTimeStamp,
+				sparkplugTestMetric("", 10, 0, float32(22.4)),
+				sparkplugTestMetric("", 12, 0, int32(-7)),
+				sparkplugTestMetric("", 13, 0, false),
+				sparkplugTestMetric("", 99, 0, uint64(1)),
+			),
+			ExpectedLines: []string{
+				"telemetry,device=plant1/plc0/pump3,field=Temperature,sensor=sparkplug-b floatValue=22.4",
+				"telemetry,device=plant1/plc0/pump3,field=Offset,sensor=sparkplug-b intValue=-7i",
+				"telemetry,device=plant1/plc0/pump3,field=Running,sensor=sparkplug-b boolValue=false",
+			},
+			ExpectedTimeStamp: dataTimeStamp,
+		}, {
+			// metrics sent by name without datatype use the datatype of the birth certificate
+			Topic: "spBv1.0/plant1/DDATA/plc0/pump3",
+			Payload: sparkplugTestPayload(dataTimeStamp,
+				sparkplugTestMetric("Pressure", 0, 0, 1.5),
+				sparkplugTestMetric("Offset", 0, 0, int32(-5)),
+				sparkplugTestMetric("Unknown", 0, 0, uint64(1)),
+			),
+			ExpectedLines: []string{
+				"telemetry,device=plant1/plc0/pump3,field=Pressure,sensor=sparkplug-b floatValue=1.5",
+				"telemetry,device=plant1/plc0/pump3,field=Offset,sensor=sparkplug-b intValue=-5i",
+			},
+			ExpectedTimeStamp: dataTimeStamp,
+		}, {
+			Topic:             "spBv1.0/plant1/DDATA/plc0/pump3",
+			Payload:           sparkplugTestPayload(dataTimeStamp, sparkplugTestMetric("", 99, 0, uint64(1))),
+			ExpectedLines:     []string{},
+			ExpectedError:     true,
+			ExpectedTimeStamp: dataTimeStamp,
+		}, {
+			Topic:   "spBv1.0/plant1/DDEATH/plc0/pump4",
+			Payload: sparkplugTestPayload(dataTimeStamp),
+			ExpectedLines: []string{
+				"availability,device=plant1/plc0/pump4 boolValue=false",
+			},
+			ExpectedTimeStamp: dataTimeStamp,
+		},
+	}
+
+	deathStimuli := TestStimuliResponse{
+		{
+			// death certificate of an earlier session
+			Topic:             "spBv1.0/plant1/NDEATH/plc0",
+			Payload:           sparkplugTestPayload(birthTimeStamp, sparkplugTestMetric("bdSeq", 0, sparkplugInt64, uint64(3))),
+			ExpectedLines:     []string{},
+			ExpectedTimeStamp: time.Now(),
+		}, {
+			Topic:   "spBv1.0/plant1/NDEATH/plc0",
+			Payload: sparkplugTestPayload(birthTimeStamp, sparkplugTestMetric("bdSeq", 0, sparkplugInt64, uint64(4))),
+			ExpectedLines: []string{
+				"availability,device=plant1/plc0 boolValue=false",
+				"availability,device=plant1/plc0/pump3 boolValue=false",
+			},
+			ExpectedTimeStamp: time.Now(),
+		}, {
+			Topic:             "spBv1.0/plant1/NDATA/plc0",
+			Payload:           sparkplugTestPayload(dataTimeStamp, sparkplugTestMetric("", 3, 0, uint64(3620))),
+			ExpectedLines:     []string{},
+			ExpectedError:     true,
+			ExpectedTimeStamp: dataTimeStamp,
+		},
+	}
+
+	if h, err := GetHandler("sparkplug-b"); err != nil {
+		t.Errorf("did not expect an error while getting handler: %s", err)
+	} else {
+		testStimuliResponse(t, mockCtrl, mockConfig, mockNodeTMConfig, h, nodeStimuli)
+		testStimuliResponse(t, mockCtrl, mockConfig, mockDeviceTMConfig, h, deviceStimuli)
+		testStimuliResponse(t, mockCtrl, mockConfig, mockNodeTMConfig, h, deathStimuli)
+	}
+}
@@ -1,129 +1,190 @@
 package converter
 
 import (
+	"fmt"
+	"github.com/pkg/errors"
 	"log"
-	"time"
+	"strconv"
+	"strings"
 )
 
-type tasmotaSensorTemperatureHumidity struct {
-	Temperature *float64
-	Humidity    *float64
+// examples:
+//
+//	{"Time":"2019-01-10T22:15:52","SI7021":{"Temperature":5.4,"Humidity":27.7},"TempUnit":"C"}
+//
+//	{
+//	  "Time":"2024-03-02T10:12:00",
+//	  "BME280":{"Temperature":21.3,"Humidity":45.1,"DewPoint":8.9,"Pressure":963.2},
+//	  "DS18B20-1":{"Id":"01144F3D6AAA","Temperature":20.1},"DS18B20-2":{"Id":"0316A2790DFF","Temperature":19.8},
+//	  "PressureUnit":"hPa","TempUnit":"C"
+//	}
+//
+//	{
+//	  "Time":"2024-03-02T10:12:00",
+//	  "ENERGY":{"TotalStartTime":"2023-01-01T00:00:00","Total":12.3,"Yesterday":1.1,"Today":0.4,
+//	            "Power":[12,0],"ApparentPower":20,"ReactivePower":16,"Factor":0.6,"Voltage":231,"Current":0.087}
+//	}
+//
+//	{"ZbReceived":{"0x8F20":{"Device":"0x8F20","Name":"kitchen","Temperature":21.5,"Humidity":50,"Endpoint":1,"LinkQuality":66}}}
+
+// tasmotaSensorMetaKeys are the keys of the top level object which are no sensor objects
+var tasmotaSensorMetaKeys = map[string]bool{
+	"Time":         true,
+	"TempUnit":     true,
+	"PressureUnit": true,
+	"SpeedUnit":    true,
 }
 
-type tasmotaSensorMessage struct {
-	Time    string
-	AM2301  *tasmotaSensorTemperatureHumidity
-	SI7021  *tasmotaSensorTemperatureHumidity
-	DS18B20 *struct {
-		Temperature float64
-	}
-	TempUnit string
+// tasmotaSensorUnits defines the units of well known keys of the sensor objects;
+// temperatures and pressures use the TempUnit and PressureUnit sent along with the values
+var tasmotaSensorUnits = map[string]string{
+	"Humidity":      "%",
+	"Illuminance":   "lx",
+	"CarbonDioxide": "ppm",
+	"CO2":           "ppm",
+	"eCO2":          "ppm",
+	"TVOC":          "ppb",
+	"PM1":           "µg/m³",
+	"PM2.5":         "µg/m³",
+	"PM10":          "µg/m³",
+	"Distance":      "cm",
+	"Voltage":       "V",
+	"Current":       "A",
+	"Power":         "W",
+	"ApparentPower": "VA",
+	"ReactivePower": "VAr",
+	"Frequency":     "Hz",
+	"Total":         "kWh",
+	"Yesterday":     "kWh",
+	"Today":         "kWh",
 }
 
 func init() {
 	registerHandler("tasmota-sensor", tasmotaSensorHandler)
 }
 
-func tasmotaSensorHandler(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) {
+func tasmotaSensorHandler(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) error {
 	// use our time
-	timeStamp := time.Now()
+	timeStamp := receiveTime(input)
 
 	// parse topic
 	device, err := tm.MatchDevice(input.Topic())
 	if err != nil {
-		log.Printf("tasmota-sensor[%s]: cannot extract device from topic='%s' err=%s", c.Name(), input.Topic(), err)
-		return
+		return fmt.Errorf("cannot extract device from topic='%s' err=%s", input.Topic(), err)
 	}
 
 	// parse payload
-	var message tasmotaSensorMessage
+	var message map[string]interface{}
 	if err := json.Unmarshal(input.Payload(), &message); err != nil {
-		log.Printf("tasmota-sensor[%s]: cannot json decode: %s", c.Name(), err)
-		return
+		return fmt.Errorf("cannot json decode: %s", err)
 	}
 
 	// save clock
-	if sentClock, err := parseTime(message.Time); err == nil {
+	timeStr, _ := message["Time"].(string)
+	if sentClock, err := parseTime(timeStr); err == nil {
 		outputFunc(stateClockOutputMessage{
 			timeStamp: timeStamp,
 			device:    device,
 			value:     sentClock,
 		})
 	} else {
-		log.Printf("tasmota-sensor[%s]: cannot parse time='%s': %s", c.Name(), message.Time, err)
+		log.Printf("tasmota-sensor[%s]: cannot parse time='%s': %s", c.Name(), timeStr, err)
+	}
+
+	tempUnit, _ := message["TempUnit"].(string)
+	pressureUnit, _ := message["PressureUnit"].(string)
+	getUnit := func(key string) *string {
+		var unit string
+		switch {
+		case strings.Contains(key, "Temperature") || key == "DewPoint":
+			unit = tempUnit
+		case strings.Contains(key, "Pressure"):
+			unit = pressureUnit
+		default:
+			var ok bool
+			if unit, ok = tasmotaSensorUnits[key]; !ok {
+				// indexed keys like Power1
+				unit = tasmotaSensorUnits[strings.TrimRight(key, "0123456789")]
+			}
+		}
+		if unit == "" {
+			return nil
+		}
+		return &unit
 	}
 
 	// send points
 	count := 0
 
-	output := func(field string, unit *string, sensor string, value float64) {
-		count += 1
-		outputFunc(telemetryOutputMessage{
-			timeStamp:  timeStamp,
-			device:     device,
-			field:      field,
-			unit:       unit,
-			sensor:     sensor,
-			floatValue: &value,
-		})
-	}
-
-	percentStr := "%"
+	// walk outputs every value of a sensor object;
+	// nested objects like the ones of ZbReceived are handled as sensors named by their Name or key
+	var walk func(sensor string, values map[string]interface{})
+	walk = func(sensor string, values map[string]interface{}) {
+		var auxTags *map[string]string
+		if id, ok := values["Id"].(string); ok {
+			auxTags = &map[string]string{"sensorId": id}
+		} else if id, ok := values["Device"].(string); ok {
+			auxTags = &map[string]string{"sensorId": id}
+		}
 
-	if message.AM2301 != nil {
-		if message.AM2301.Temperature != nil {
-			output(
-				"Temperature",
-				&message.TempUnit,
-				"AM2301",
-				*message.AM2301.Temperature,
-			)
+		output := func(field string, value interface{}) {
+			m := telemetryOutputMessage{
+				timeStamp: timeStamp,
+				device:    device,
+				field:     field,
+				unit:      getUnit(field),
+				sensor:    sensor,
+				auxTags:   auxTags,
+			}
+			switch v := value.(type) {
+			case float64:
+				m.floatValue = &v
+			case bool:
+				m.boolValue = &v
+			case string:
+				m.stringValue = &v
+			default:
+				return
+			}
+			count += 1
+			outputFunc(m)
 		}
-		if message.AM2301.Humidity != nil {
-			output(
-				"Humidity",
-				&percentStr,
-				"AM2301",
-				*message.AM2301.Humidity,
-			)
+
+		for key, value := range values {
+			switch v := value.(type) {
+			case map[string]interface{}:
+				name := key
+				if n, ok := v["Name"].(string); ok && n != "" {
+					name = n
+				}
+				walk(name, v)
+			case []interface{}:
+				// multi channel values like the Power of a dual relay device are numbered starting at 1
+				for i, e := range v {
+					output(key+strconv.Itoa(i+1), e)
+				}
+			default:
+				if key == "Id" || key == "Device" || key == "Name" {
+					continue
+				}
+				output(key, v)
+			}
 		}
 	}
 
-	if message.SI7021 != nil {
-		if message.SI7021.Temperature != nil {
-			output(
-				"Temperature",
-				&message.TempUnit,
-				"SI7021",
-				*message.SI7021.Temperature,
-			)
+	for key, value := range message {
+		if tasmotaSensorMetaKeys[key] {
+			continue
 		}
-		if message.SI7021.Humidity != nil {
-			output(
-				"Humidity",
-				&percentStr,
-				"SI7021",
-				*message.SI7021.Humidity,
-			)
+		if v, ok := value.(map[string]interface{}); ok {
+			walk(key, v)
 		}
 	}
 
-	if message.DS18B20 != nil {
-		output(
-			"Temperature",
-			&message.TempUnit,
-			"DS18B20",
-			message.DS18B20.Temperature,
-		)
-	}
-
 	// any points sent?
-	if count < 1 && c.LogDebug() {
-		log.Printf(
-			"tasmota-sensor[%s]: could not extract any sensor data; "+
-				"sensor type is probably unknown; known sensors are AM2301, SI7021, DS18B20; payload='%s'",
-			c.Name(), input.Payload(),
-		)
-		return
+	if count < 1 {
+		return errors.New("could not extract any sensor data")
 	}
+
+	return nil
 }
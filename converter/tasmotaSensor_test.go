@@ -46,27 +46,72 @@ func TestTasmotaSensor(t *testing.T) {
 				"telemetry,device=mezzo/kuehlschrank,field=Temperature,sensor=DS18B20,unit=C floatValue=3",
 			},
 			ExpectedTimeStamp: time.Now(),
+		}, {
+			Topic: "piegn/tele/mezzo/bme/SENSOR",
+			Payload: `{"Time":"2024-03-02T10:12:00","BME280":{"Temperature":21.3,"Humidity":45.1,"DewPoint":8.9,"Pressure":963.2},` +
+				`"DS18B20-1":{"Id":"01144F3D6AAA","Temperature":20.1},"DS18B20-2":{"Id":"0316A2790DFF","Temperature":19.8},` +
+				`"ANALOG":{"A0":512},"PressureUnit":"hPa","TempUnit":"C"}`,
+			ExpectedLines: []string{
+				"clock,device=mezzo/bme timeValue=\"2024-03-02T10:12:00Z\"",
+				"telemetry,device=mezzo/bme,field=Temperature,sensor=BME280,unit=C floatValue=21.3",
+				"telemetry,device=mezzo/bme,field=Humidity,sensor=BME280,unit=% floatValue=45.1",
+				"telemetry,device=mezzo/bme,field=DewPoint,sensor=BME280,unit=C floatValue=8.9",
+				"telemetry,device=mezzo/bme,field=Pressure,sensor=BME280,unit=hPa floatValue=963.2",
+				"telemetry,device=mezzo/bme,field=Temperature,sensor=DS18B20-1,sensorId=01144F3D6AAA,unit=C floatValue=20.1",
+				"telemetry,device=mezzo/bme,field=Temperature,sensor=DS18B20-2,sensorId=0316A2790DFF,unit=C floatValue=19.8",
+				"telemetry,device=mezzo/bme,field=A0,sensor=ANALOG floatValue=512",
+			},
+			ExpectedTimeStamp: time.Now(),
+		}, {
+			Topic: "piegn/tele/mezzo/pow/SENSOR",
+			Payload: `{"Time":"2024-03-02T10:12:00","ENERGY":{"TotalStartTime":"2023-01-01T00:00:00","Total":12.3,"Today":0.4,` +
+				`"Power":[12,0],"Factor":0.6,"Voltage":231,"Current":0.087}}`,
+			ExpectedLines: []string{
+				"clock,device=mezzo/pow timeValue=\"2024-03-02T10:12:00Z\"",
+				"telemetry,device=mezzo/pow,field=TotalStartTime,sensor=ENERGY stringValue=\"2023-01-01T00:00:00\"",
+				"telemetry,device=mezzo/pow,field=Total,sensor=ENERGY,unit=kWh floatValue=12.3",
+				"telemetry,device=mezzo/pow,field=Today,sensor=ENERGY,unit=kWh floatValue=0.4",
+				"telemetry,device=mezzo/pow,field=Power1,sensor=ENERGY,unit=W floatValue=12",
+				"telemetry,device=mezzo/pow,field=Power2,sensor=ENERGY,unit=W floatValue=0",
+				"telemetry,device=mezzo/pow,field=Factor,sensor=ENERGY floatValue=0.6",
+				"telemetry,device=mezzo/pow,field=Voltage,sensor=ENERGY,unit=V floatValue=231",
+				"telemetry,device=mezzo/pow,field=Current,sensor=ENERGY,unit=A floatValue=0.087",
+			},
+			ExpectedTimeStamp: time.Now(),
+		}, {
+			Topic:   "piegn/tele/mezzo/zbbridge/SENSOR",
+			Payload: `{"ZbReceived":{"0x8F20":{"Device":"0x8F20","Name":"kitchen","Temperature":21.5,"Humidity":50,"LinkQuality":66}}}`,
+			ExpectedLines: []string{
+				"telemetry,device=mezzo/zbbridge,field=Temperature,sensor=kitchen,sensorId=0x8F20 floatValue=21.5",
+				"telemetry,device=mezzo/zbbridge,field=Humidity,sensor=kitchen,sensorId=0x8F20,unit=% floatValue=50",
+				"telemetry,device=mezzo/zbbridge,field=LinkQuality,sensor=kitchen,sensorId=0x8F20 floatValue=66",
+			},
+			ExpectedTimeStamp: time.Now(),
 		}, {
 			Topic:   "piegn/tele/mezzo/no-sensor/SENSOR",
-			Payload: `{"Time":"2019-01-10T22:16:04","unkown":{"Temperature":3.0},"TempUnit":"C"}`,
+			Payload: `{"Time":"2019-01-10T22:16:04","Switch1":"ON","TempUnit":"C"}`,
 			ExpectedLines: []string{
 				"clock,device=mezzo/no-sensor timeValue=\"2019-01-10T22:16:04Z\"",
 			},
 			ExpectedTimeStamp: time.Now(),
+			ExpectedError:     true,
 		}, {
 			Topic:             "piegn/tele/mezzo/invalidTime/SENSOR",
-			Payload:           `{"Time":"2019-01-10T22:16:04qq","unkown":{"Temperature":3.0},"TempUnit":"C"}`,
+			Payload:           `{"Time":"2019-01-10T22:16:04qq","TempUnit":"C"}`,
 			ExpectedLines:     []string{},
+			ExpectedError:     true,
 			ExpectedTimeStamp: time.Now(),
 		}, {
 			Topic:             "piegn/tele/mezzo/kuehlschrank/SENSOR",
 			Payload:           "invalid",
 			ExpectedLines:     []string{},
+			ExpectedError:     true,
 			ExpectedTimeStamp: time.Now(),
 		}, {
 			Topic:             "invalid",
 			Payload:           `{"Time":"2019-01-10T22:16:04","DS18B20":{"Temperature":3.0},"TempUnit":"C"}`,
 			ExpectedLines:     []string{},
+			ExpectedError:     true,
 			ExpectedTimeStamp: time.Now(),
 		},
 	}
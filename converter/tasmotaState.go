@@ -1,7 +1,10 @@
 package converter
 
 import (
+	"fmt"
 	"log"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -18,24 +21,38 @@ import (
 //	  "POWER1":"ON","POWER2":"OFF","POWER3":"OFF","POWER4":"OFF",
 //	  "wifi":{"AP":1,"SSId":"piegn-iot","BSSId":"04:F0:21:2F:B7:CC","Channel":1,"RSSI":100}
 //	}
+//
+//	{
+//	  "Time":"2024-05-12T08:31:02","Uptime":"0T02:10:44","UptimeSec":7844,"Heap":21,"SleepMode":"Dynamic","Sleep":10,
+//	  "LoadAvg":99,"MqttCount":1,"POWER":"ON","Dimmer":40,"Color":"0000006666","HSBColor":"0,0,0","White":40,"CT":327,
+//	  "Channel":[0,0,0,40,40],"Scheme":0,"Fade":"OFF","Speed":1,"LedTable":"ON",
+//	  "Wifi":{"AP":1,"SSId":"piegn-iot","BSSId":"04:F0:21:2F:B7:CC","Channel":11,"Mode":"11n","RSSI":58,"Signal":-71,
+//	          "LinkCount":1,"Downtime":"0T00:00:03"}
+//	}
 type tasmotaStateMessage struct {
-	Time   string  // save to timeValue
-	Uptime string  // save to timeValue
-	Vcc    float64 // save to floatValues
-	Power  string  // save to boolValues
-	Power1 string  // save to boolValues
-	Power2 string  // save to boolValues
-	Power3 string  // save to boolValues
-	Power4 string  // save to boolValues
-	Wifi   tasmotaWifi
+	Time      string   // save to timeValue
+	Uptime    string   // save to timeValue
+	Vcc       *float64 // save to floatValues
+	Heap      *float64 // save to floatValues
+	Sleep     *float64 // save to floatValues
+	LoadAvg   *float64 // save to floatValues
+	MqttCount *float64 // save to floatValues
+	Dimmer    *float64 // save to floatValues
+	HSBColor  string   // save to floatValues Hue, Saturation, Brightness
+	CT        *float64 // save to floatValues
+	Wifi      tasmotaWifi
+	// POWER, POWER1, POWER2, ... are read from the generic map by powerKeyMatcher and saved to boolValues
 }
 
 type tasmotaWifi struct {
-	AP      int
-	SSId    string
-	BSSId   string
-	Channel int
-	RSSI    int
+	AP        int
+	SSId      string
+	BSSId     string
+	Channel   int
+	RSSI      int
+	Signal    *int
+	LinkCount *int
+	Downtime  string
 }
 
 type stateWifiOutputMessage struct {
@@ -44,26 +61,27 @@ type stateWifiOutputMessage struct {
 	wifi      tasmotaWifi
 }
 
+// matches POWER and the numbered POWER1, POWER2, ... keys of multi relay devices
+var powerKeyMatcher = regexp.MustCompile(`^(?i)POWER([0-9]*)$`)
+
 func init() {
 	registerHandler("tasmota-state", tasmotaStateHandler)
 }
 
-func tasmotaStateHandler(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) {
+func tasmotaStateHandler(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) error {
 	// use our time
-	timeStamp := time.Now()
+	timeStamp := receiveTime(input)
 
 	// parse topic
 	device, err := tm.MatchDevice(input.Topic())
 	if err != nil {
-		log.Printf("tasmota-state[%s]: cannot extract device from topic='%s' err=%s", c.Name(), input.Topic(), err)
-		return
+		return fmt.Errorf("cannot extract device from topic='%s' err=%s", input.Topic(), err)
 	}
 
 	// parse payload
 	var message tasmotaStateMessage
 	if err := json.Unmarshal(input.Payload(), &message); err != nil {
-		log.Printf("tasmota-state[%s]: cannot json decode: %s", c.Name(), err)
-		return
+		return fmt.Errorf("cannot json decode: %s", err)
 	}
 
 	// save clock
@@ -95,20 +113,44 @@ func tasmotaStateHandler(c Config, tm TopicMatcher, input Input, outputFunc Outp
 		})
 	}
 
-	// Vcc
-	{
-		unit := "V"
+	outputFloat := func(field, unit string, value *float64) {
+		if value == nil {
+			return
+		}
+		var unitPtr *string
+		if unit != "" {
+			unitPtr = &unit
+		}
 		outputFunc(telemetryOutputMessage{
 			timeStamp:  timeStamp,
 			device:     device,
-			field:      "Vcc",
-			unit:       &unit,
+			field:      field,
+			unit:       unitPtr,
 			sensor:     sensor,
-			floatValue: &message.Vcc,
+			floatValue: value,
 		})
 	}
 
-	// Power[1,2,3,4]?
+	outputFloat("Vcc", "V", message.Vcc)
+	outputFloat("Heap", "kB", message.Heap)
+	outputFloat("Sleep", "ms", message.Sleep)
+	outputFloat("LoadAvg", "", message.LoadAvg)
+	outputFloat("MqttCount", "", message.MqttCount)
+	outputFloat("Dimmer", "%", message.Dimmer)
+	outputFloat("CT", "mired", message.CT)
+
+	// HSBColor
+	if len(message.HSBColor) > 0 {
+		if hsb, err := parseHSBColor(message.HSBColor); err != nil {
+			log.Printf("tasmota-state[%s]: cannot parse HSBColor='%s': %s", c.Name(), message.HSBColor, err)
+		} else {
+			outputFloat("Hue", "°", &hsb[0])
+			outputFloat("Saturation", "%", &hsb[1])
+			outputFloat("Brightness", "%", &hsb[2])
+		}
+	}
+
+	// POWER, POWER1, ... POWER8 and more
 	powerToBoolean := func(power string) (res, ok bool) {
 		power = strings.ToUpper(power)
 		switch power {
@@ -125,22 +167,25 @@ func tasmotaStateHandler(c Config, tm TopicMatcher, input Input, outputFunc Outp
 			return false, false
 		}
 	}
-	outputPower := func(field string, power string) {
-		if value, ok := powerToBoolean(power); ok {
-			outputFunc(telemetryOutputMessage{
-				timeStamp: timeStamp,
-				device:    device,
-				field:     field,
-				sensor:    sensor,
-				boolValue: &value,
-			})
+	var values map[string]interface{}
+	if err := json.Unmarshal(input.Payload(), &values); err == nil {
+		for key, value := range values {
+			matches := powerKeyMatcher.FindStringSubmatch(key)
+			if matches == nil {
+				continue
+			}
+			power, _ := value.(string)
+			if v, ok := powerToBoolean(power); ok {
+				outputFunc(telemetryOutputMessage{
+					timeStamp: timeStamp,
+					device:    device,
+					field:     "Power" + matches[1],
+					sensor:    sensor,
+					boolValue: &v,
+				})
+			}
 		}
 	}
-	outputPower("Power", message.Power)
-	outputPower("Power1", message.Power1)
-	outputPower("Power2", message.Power2)
-	outputPower("Power3", message.Power3)
-	outputPower("Power4", message.Power4)
 
 	// wifi value
 	outputFunc(stateWifiOutputMessage{
@@ -148,6 +193,8 @@ func tasmotaStateHandler(c Config, tm TopicMatcher, input Input, outputFunc Outp
 		device:    device,
 		wifi:      message.Wifi,
 	})
+
+	return nil
 }
 
 func (m stateWifiOutputMessage) Measurement() string {
@@ -163,13 +210,40 @@ func (m stateWifiOutputMessage) Tags() map[string]string {
 }
 
 func (m stateWifiOutputMessage) Fields() map[string]interface{} {
-	return map[string]interface{}{
+	ret := map[string]interface{}{
 		"AP":      m.wifi.AP,
 		"Channel": m.wifi.Channel,
 		"RSSI":    m.wifi.RSSI,
 	}
+
+	// only sent by newer tasmota versions
+	if m.wifi.Signal != nil {
+		ret["Signal"] = *m.wifi.Signal
+	}
+	if m.wifi.LinkCount != nil {
+		ret["LinkCount"] = *m.wifi.LinkCount
+	}
+	if downtime, err := parseUpTime(m.wifi.Downtime); err == nil {
+		ret["DowntimeSec"] = downtime
+	}
+
+	return ret
 }
 
 func (m stateWifiOutputMessage) Time() time.Time {
 	return m.timeStamp
 }
+
+// parseHSBColor parses the comma separated hue, saturation and brightness values like 30,100,50
+func parseHSBColor(str string) (ret [3]float64, err error) {
+	parts := strings.Split(str, ",")
+	if len(parts) != 3 {
+		return ret, fmt.Errorf("expect 3 comma separated values")
+	}
+	for i, p := range parts {
+		if ret[i], err = strconv.ParseFloat(strings.TrimSpace(p), 64); err != nil {
+			return
+		}
+	}
+	return
+}
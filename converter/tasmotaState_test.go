@@ -86,15 +86,71 @@ func TestTasmotaState(t *testing.T) {
 				"wifi,BSSId=04:F0:21:33:40:99,SSId=piegn-iot,device=mezzo/zimmer-gross AP=2i,Channel=1i,RSSI=100i",
 			},
 			ExpectedTimeStamp: time.Now(),
+		}, {
+			// tasmota 13.4.0 on an 8 channel relay board
+			Topic: "piegn/tele/keller/relays0/STATE",
+			Payload: `{
+  "Time":"2024-02-11T19:20:31","Uptime":"3T04:05:06","UptimeSec":273906,"Heap":24,"SleepMode":"Dynamic","Sleep":50,
+  "LoadAvg":19,"MqttCount":2,
+  "POWER1":"ON","POWER2":"OFF","POWER3":"OFF","POWER4":"OFF","POWER5":"ON","POWER6":"OFF","POWER7":"OFF","POWER8":"ON",
+  "Wifi":{"AP":1,"SSId":"piegn-iot","BSSId":"04:F0:21:2F:B7:CC","Channel":6,"Mode":"11n","RSSI":76,"Signal":-62,
+          "LinkCount":3,"Downtime":"0T00:00:09"}
+}`,
+			ExpectedLines: []string{
+				"clock,device=keller/relays0 timeValue=\"2024-02-11T19:20:31Z\"",
+				"telemetry,device=keller/relays0,field=UpTime,sensor=tasmota,unit=s floatValue=273906",
+				"telemetry,device=keller/relays0,field=Heap,sensor=tasmota,unit=kB floatValue=24",
+				"telemetry,device=keller/relays0,field=Sleep,sensor=tasmota,unit=ms floatValue=50",
+				"telemetry,device=keller/relays0,field=LoadAvg,sensor=tasmota floatValue=19",
+				"telemetry,device=keller/relays0,field=MqttCount,sensor=tasmota floatValue=2",
+				"telemetry,device=keller/relays0,field=Power1,sensor=tasmota boolValue=true",
+				"telemetry,device=keller/relays0,field=Power2,sensor=tasmota boolValue=false",
+				"telemetry,device=keller/relays0,field=Power3,sensor=tasmota boolValue=false",
+				"telemetry,device=keller/relays0,field=Power4,sensor=tasmota boolValue=false",
+				"telemetry,device=keller/relays0,field=Power5,sensor=tasmota boolValue=true",
+				"telemetry,device=keller/relays0,field=Power6,sensor=tasmota boolValue=false",
+				"telemetry,device=keller/relays0,field=Power7,sensor=tasmota boolValue=false",
+				"telemetry,device=keller/relays0,field=Power8,sensor=tasmota boolValue=true",
+				"wifi,BSSId=04:F0:21:2F:B7:CC,SSId=piegn-iot,device=keller/relays0 AP=1i,Channel=6i,DowntimeSec=9i,LinkCount=3i,RSSI=76i,Signal=-62i",
+			},
+			ExpectedTimeStamp: time.Now(),
+		}, {
+			// tasmota 14.1.0 on a RGBCCT bulb
+			Topic: "piegn/tele/mezzo/bulb0/STATE",
+			Payload: `{
+  "Time":"2024-07-02T21:03:17","Uptime":"0T02:10:44","UptimeSec":7844,"Heap":21,"SleepMode":"Dynamic","Sleep":10,
+  "LoadAvg":99,"MqttCount":1,"POWER":"ON","Dimmer":40,"Color":"FF80000000","HSBColor":"30,100,40","White":0,"CT":153,
+  "Channel":[100,50,0,0,0],"Scheme":0,"Fade":"OFF","Speed":1,"LedTable":"ON",
+  "Wifi":{"AP":1,"SSId":"piegn-iot","BSSId":"04:F0:21:33:40:99","Channel":11,"Mode":"11n","RSSI":58,"Signal":-71,
+          "LinkCount":1,"Downtime":"0T00:00:03"}
+}`,
+			ExpectedLines: []string{
+				"clock,device=mezzo/bulb0 timeValue=\"2024-07-02T21:03:17Z\"",
+				"telemetry,device=mezzo/bulb0,field=UpTime,sensor=tasmota,unit=s floatValue=7844",
+				"telemetry,device=mezzo/bulb0,field=Heap,sensor=tasmota,unit=kB floatValue=21",
+				"telemetry,device=mezzo/bulb0,field=Sleep,sensor=tasmota,unit=ms floatValue=10",
+				"telemetry,device=mezzo/bulb0,field=LoadAvg,sensor=tasmota floatValue=99",
+				"telemetry,device=mezzo/bulb0,field=MqttCount,sensor=tasmota floatValue=1",
+				"telemetry,device=mezzo/bulb0,field=Power,sensor=tasmota boolValue=true",
+				"telemetry,device=mezzo/bulb0,field=Dimmer,sensor=tasmota,unit=% floatValue=40",
+				"telemetry,device=mezzo/bulb0,field=Hue,sensor=tasmota,unit=° floatValue=30",
+				"telemetry,device=mezzo/bulb0,field=Saturation,sensor=tasmota,unit=% floatValue=100",
+				"telemetry,device=mezzo/bulb0,field=Brightness,sensor=tasmota,unit=% floatValue=40",
+				"telemetry,device=mezzo/bulb0,field=CT,sensor=tasmota,unit=mired floatValue=153",
+				"wifi,BSSId=04:F0:21:33:40:99,SSId=piegn-iot,device=mezzo/bulb0 AP=1i,Channel=11i,DowntimeSec=3i,LinkCount=1i,RSSI=58i,Signal=-71i",
+			},
+			ExpectedTimeStamp: time.Now(),
 		}, {
 			Topic:             "piegn/tele/mezzo/bridge0/STATE",
 			Payload:           "invalid",
 			ExpectedLines:     []string{},
+			ExpectedError:     true,
 			ExpectedTimeStamp: time.Now(),
 		}, {
 			Topic:             "invalid",
 			Payload:           ``,
 			ExpectedLines:     []string{},
+			ExpectedError:     true,
 			ExpectedTimeStamp: time.Now(),
 		},
 	}
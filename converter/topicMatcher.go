@@ -26,7 +26,8 @@ func CreateTopicMatcher(cfg TopicMatcherConfig) (TopicMatcher, error) {
 	// create regexp to match against
 	deviceExpr := regexp.QuoteMeta(cfg.Device())
 	if cfg.DeviceIsDynamic() {
-		deviceExpr = "(" + strings.ReplaceAll(deviceExpr, "\\+", "[^\\/]+") + ")"
+		deviceExpr = strings.ReplaceAll(deviceExpr, "\\+", "[^\\/]+")
+		deviceExpr = "(" + strings.ReplaceAll(deviceExpr, "#", ".+") + ")"
 	}
 
 	// must not have anything before / after
@@ -45,7 +46,14 @@ func CreateTopicMatcher(cfg TopicMatcherConfig) (TopicMatcher, error) {
 
 func (t topicMatcherStruct) MatchDevice(messageTopic string) (device string, err error) {
 	matches := t.matcher.FindStringSubmatch(messageTopic)
-	if len(matches) < 2 {
+	if !t.cfg.DeviceIsDynamic() {
+		// a static device is used as device name for the fixed topic
+		if len(matches) < 1 {
+			err = fmt.Errorf("messageTopic='%s' does not match", messageTopic)
+		} else {
+			device = t.cfg.Device()
+		}
+	} else if len(matches) < 2 {
 		err = fmt.Errorf("messageTopic='%s' does not match", messageTopic)
 	} else {
 		device = matches[1]
@@ -55,9 +63,6 @@ func (t topicMatcherStruct) MatchDevice(messageTopic string) (device string, err
 }
 
 func (t topicMatcherStruct) GetSubscribeTopic() string {
-	if !t.cfg.DeviceIsDynamic() {
-		return t.cfg.Device()
-	} else {
-		return strings.Replace(t.cfg.Topic(), "%Device%", t.cfg.Device(), 1)
-	}
+	// the topic of a static device contains no wildcards, e.g. Topic=zigbee2mqtt/bridge/devices Device=bridge
+	return strings.Replace(t.cfg.Topic(), "%Device%", t.cfg.Device(), 1)
 }
@@ -1,51 +1,46 @@
 package converter
 
 import (
-	"log"
+	"fmt"
+	"github.com/pkg/errors"
 	"time"
 )
 
-type ttnDraginoMessage struct {
-	ReceivedAt    time.Time `json:"received_at"`
-	UplinkMessage struct {
-		DecodedPayload struct {
-			AdcCh0         *float64 `json:"ADC_CH0V"`
-			BatV           *float64 `json:"BatV"`
-			BatMV          *float64 `json:"Bat_mV"`
-			DigitalIStatus *string  `json:"Digital_IStatus"`
-			DoorStatus     *string  `json:"Door_status"`
-			EXTITrigger    *string  `json:"EXTI_Trigger"`
-			HumSHT         *float64 `json:"Hum_SHT"`
-			WorkMode       *string  `json:"Work_mode"`
-			AlarmStatus    *string  `json:"ALARM_status"`
-			TempBlack      *float64 `json:"Temp_Black"`
-			TempRed        *float64 `json:"Temp_Red"`
-			TempWhite      *float64 `json:"Temp_White"`
-			TempC1         *float64 `json:"TempC1"`
-			TempC2         *float64 `json:"TempC2"`
-			TempC3         *float64 `json:"TempC3"`
-			TempCDs        *float64 `json:"TempC_DS"`
-			TempCSht       *float64 `json:"TempC_SHT"`
-			Ext            *int64   `json:"Ext"`
-			Systimestamp   *int64   `json:"Systimestamp"`
-		} `json:"decoded_payload"`
-	} `json:"uplink_message"`
+type ttnDraginoPayload struct {
+	AdcCh0         *float64 `json:"ADC_CH0V"`
+	BatV           *float64 `json:"BatV"`
+	BatMV          *float64 `json:"Bat_mV"`
+	DigitalIStatus *string  `json:"Digital_IStatus"`
+	DoorStatus     *string  `json:"Door_status"`
+	EXTITrigger    *string  `json:"EXTI_Trigger"`
+	HumSHT         *float64 `json:"Hum_SHT"`
+	WorkMode       *string  `json:"Work_mode"`
+	AlarmStatus    *string  `json:"ALARM_status"`
+	TempBlack      *float64 `json:"Temp_Black"`
+	TempRed        *float64 `json:"Temp_Red"`
+	TempWhite      *float64 `json:"Temp_White"`
+	TempC1         *float64 `json:"TempC1"`
+	TempC2         *float64 `json:"TempC2"`
+	TempC3         *float64 `json:"TempC3"`
+	TempCDs        *float64 `json:"TempC_DS"`
+	TempCSht       *float64 `json:"TempC_SHT"`
+	Ext            *int64   `json:"Ext"`
+	Systimestamp   *int64   `json:"Systimestamp"`
 }
 
-func ttnDraginoHandler(c Config, device, model string, input Input, outputFunc OutputFunc) {
+func ttnDraginoHandler(c Config, device, model string, receivedAt time.Time, decodedPayload []byte, outputFunc OutputFunc) error {
 	// parse payload
-	var message ttnDraginoMessage
-	if err := json.Unmarshal(input.Payload(), &message); err != nil {
-		log.Printf("ttn-dragino[%s]: cannot json decode: %s", c.Name(), err)
-		return
+	var message ttnDraginoPayload
+	if err := json.Unmarshal(decodedPayload, &message); err != nil {
+		return fmt.Errorf("cannot json decode: %s", err)
 	}
 
 	// save clock
-	if message.UplinkMessage.DecodedPayload.Systimestamp != nil {
+	if message.Systimestamp != nil {
 		outputFunc(stateClockOutputMessage{
-			timeStamp: message.ReceivedAt,
+			timeStamp: receivedAt,
 			device:    device,
-			value:     time.Unix(*message.UplinkMessage.DecodedPayload.Systimestamp, 0).UTC(),
+			value:     time.Unix(*message.Systimestamp, 0).UTC(),
 		})
 	}
 
@@ -54,7 +49,7 @@ func ttnDraginoHandler(c Config, device, model string, input Input, outputFunc O
 	output := func(field string, unit string, stringValue *string, floatValue *float64, boolValue *bool, intValue *int64) {
 		count += 1
 		outputFunc(telemetryOutputMessage{
-			timeStamp: message.ReceivedAt,
+			timeStamp: receivedAt,
 			device:    device,
 			field:     field,
 			unit: func(u string) *string {
@@ -83,45 +78,45 @@ func ttnDraginoHandler(c Config, device, model string, input Input, outputFunc O
 		output(field, unit, nil, nil, nil, intValue)
 	}
 
-	if message.UplinkMessage.DecodedPayload.AdcCh0 != nil {
+	if message.AdcCh0 != nil {
 		outputFloat(
 			"AdcCh0",
 			"V",
-			message.UplinkMessage.DecodedPayload.AdcCh0,
+			message.AdcCh0,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.BatV != nil {
+	if message.BatV != nil {
 		outputFloat(
 			"BatV",
 			"V",
-			message.UplinkMessage.DecodedPayload.BatV,
+			message.BatV,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.BatMV != nil {
-		value := *message.UplinkMessage.DecodedPayload.BatMV / 1000
+	if message.BatMV != nil {
+		value := *message.BatMV / 1000
 		outputFloat(
 			"BatV",
 			"V",
 			&value,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.DigitalIStatus != nil {
+	if message.DigitalIStatus != nil {
 		outputString(
 			"DigitalIStatus",
 			"",
-			message.UplinkMessage.DecodedPayload.DigitalIStatus,
+			message.DigitalIStatus,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.DoorStatus != nil {
+	if message.DoorStatus != nil {
 		outputString(
 			"DoorStatus",
 			"",
-			message.UplinkMessage.DecodedPayload.DoorStatus,
+			message.DoorStatus,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.EXTITrigger != nil {
+	if message.EXTITrigger != nil {
 		value := false
-		if *message.UplinkMessage.DecodedPayload.EXTITrigger == "TRUE" {
+		if *message.EXTITrigger == "TRUE" {
 			value = true
 		}
 		outputBool(
@@ -130,23 +125,23 @@ func ttnDraginoHandler(c Config, device, model string, input Input, outputFunc O
 			&value,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.HumSHT != nil {
+	if message.HumSHT != nil {
 		outputFloat(
 			"HumSHT",
 			"%",
-			message.UplinkMessage.DecodedPayload.HumSHT,
+			message.HumSHT,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.WorkMode != nil {
+	if message.WorkMode != nil {
 		outputString(
 			"WorkMode",
 			"",
-			message.UplinkMessage.DecodedPayload.WorkMode,
+			message.WorkMode,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.AlarmStatus != nil {
+	if message.AlarmStatus != nil {
 		alarmStatus := false
-		if *message.UplinkMessage.DecodedPayload.AlarmStatus == "TRUE" {
+		if *message.AlarmStatus == "TRUE" {
 			alarmStatus = true
 		}
 		outputBool(
@@ -155,83 +150,81 @@ func ttnDraginoHandler(c Config, device, model string, input Input, outputFunc O
 			&alarmStatus,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.TempBlack != nil {
+	if message.TempBlack != nil {
 		outputFloat(
 			"TempBlack",
 			"°C",
-			message.UplinkMessage.DecodedPayload.TempBlack,
+			message.TempBlack,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.TempRed != nil {
+	if message.TempRed != nil {
 		outputFloat(
 			"TempRed",
 			"°C",
-			message.UplinkMessage.DecodedPayload.TempRed,
+			message.TempRed,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.TempWhite != nil {
+	if message.TempWhite != nil {
 		outputFloat(
 			"TempWhite",
 			"°C",
-			message.UplinkMessage.DecodedPayload.TempWhite,
+			message.TempWhite,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.TempC1 != nil {
+	if message.TempC1 != nil {
 		outputFloat(
 			"TempC1",
 			"°C",
-			message.UplinkMessage.DecodedPayload.TempC1,
+			message.TempC1,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.TempC2 != nil {
+	if message.TempC2 != nil {
 		outputFloat(
 			"TempC2",
 			"°C",
-			message.UplinkMessage.DecodedPayload.TempC2,
+			message.TempC2,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.TempC3 != nil {
+	if message.TempC3 != nil {
 		outputFloat(
 			"TempC3",
 			"°C",
-			message.UplinkMessage.DecodedPayload.TempC3,
+			message.TempC3,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.TempCDs != nil {
+	if message.TempCDs != nil {
 		outputFloat(
 			"TempCDs",
 			"°C",
-			message.UplinkMessage.DecodedPayload.TempCDs,
+			message.TempCDs,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.TempCSht != nil {
+	if message.TempCSht != nil {
 		outputFloat(
 			"TempCSht",
 			"°C",
-			message.UplinkMessage.DecodedPayload.TempCSht,
+			message.TempCSht,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.Ext != nil {
+	if message.Ext != nil {
 		outputInt(
 			"Ext",
 			"",
-			message.UplinkMessage.DecodedPayload.Ext,
+			message.Ext,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.Systimestamp != nil {
+	if message.Systimestamp != nil {
 		outputInt(
 			"Systimestamp",
 			"ms",
-			message.UplinkMessage.DecodedPayload.Systimestamp,
+			message.Systimestamp,
 		)
 	}
 
 	// any points sent?
-	if count < 1 && c.LogDebug() {
-		log.Printf(
-			"ttn-dragino[%s]: could not extract any sensor data; payload='%s'",
-			c.Name(), input.Payload(),
-		)
-		return
+	if count < 1 {
+		return errors.New("could not extract any sensor data")
 	}
+
+	return nil
 }
@@ -1,33 +1,28 @@
 package converter
 
 import (
-	"log"
+	"fmt"
+	"github.com/pkg/errors"
 	"time"
 )
 
-type ttnFencyboyMessage struct {
-	ReceivedAt    time.Time `json:"received_at"`
-	UplinkMessage struct {
-		DecodedPayload struct {
-			ActiveMode        *bool    `json:"ACTIVE_MODE"`
-			Impulses          *int64   `json:"IMPULSES"`
-			FenceVoltage      *float64 `json:"FENCEVOLTAGE"`
-			FenceVoltageStd   *float64 `json:"FENCE_VOLTAGE_STD"`
-			FenceVoltageMin   *float64 `json:"FENCEVOLTAGEMIN"`
-			FenceVoltageMax   *float64 `json:"FENCEVOLTAGEMAX"`
-			BatteryVoltage    *float64 `json:"BATTERYVOLTAGE"`
-			RemainingCapacity *float64 `json:"REMAINING_CAPACITY"`
-			Temperature       *float64 `json:"TEMPERATURE"`
-		} `json:"decoded_payload"`
-	} `json:"uplink_message"`
+type ttnFencyboyPayload struct {
+	ActiveMode        *bool    `json:"ACTIVE_MODE"`
+	Impulses          *int64   `json:"IMPULSES"`
+	FenceVoltage      *float64 `json:"FENCEVOLTAGE"`
+	FenceVoltageStd   *float64 `json:"FENCE_VOLTAGE_STD"`
+	FenceVoltageMin   *float64 `json:"FENCEVOLTAGEMIN"`
+	FenceVoltageMax   *float64 `json:"FENCEVOLTAGEMAX"`
+	BatteryVoltage    *float64 `json:"BATTERYVOLTAGE"`
+	RemainingCapacity *float64 `json:"REMAINING_CAPACITY"`
+	Temperature       *float64 `json:"TEMPERATURE"`
 }
 
-func ttnFencyboyHandler(c Config, device, model string, input Input, outputFunc OutputFunc) {
+func ttnFencyboyHandler(c Config, device, model string, receivedAt time.Time, decodedPayload []byte, outputFunc OutputFunc) error {
 	// parse payload
-	var message ttnFencyboyMessage
-	if err := json.Unmarshal(input.Payload(), &message); err != nil {
-		log.Printf("ttn-fencyboy[%s]: cannot json decode: %s", c.Name(), err)
-		return
+	var message ttnFencyboyPayload
+	if err := json.Unmarshal(decodedPayload, &message); err != nil {
+		return fmt.Errorf("cannot json decode: %s", err)
 	}
 
 	// send points
@@ -35,7 +30,7 @@ func ttnFencyboyHandler(c Config, device, model string, input Input, outputFunc
 	output := func(field string, unit string, stringValue *string, floatValue *float64, boolValue *bool, intValue *int64) {
 		count += 1
 		outputFunc(telemetryOutputMessage{
-			timeStamp: message.ReceivedAt,
+			timeStamp: receivedAt,
 			device:    device,
 			field:     field,
 			unit: func(u string) *string {
@@ -62,76 +57,74 @@ func ttnFencyboyHandler(c Config, device, model string, input Input, outputFunc
 		output(field, unit, nil, nil, nil, intValue)
 	}
 
-	if message.UplinkMessage.DecodedPayload.ActiveMode != nil {
+	if message.ActiveMode != nil {
 		outputBool(
 			"ActiveMode",
 			"",
-			message.UplinkMessage.DecodedPayload.ActiveMode,
+			message.ActiveMode,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.Impulses != nil {
+	if message.Impulses != nil {
 		outputInt(
 			"Impulses",
 			"",
-			message.UplinkMessage.DecodedPayload.Impulses,
+			message.Impulses,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.FenceVoltage != nil {
+	if message.FenceVoltage != nil {
 		outputFloat(
 			"FenceVoltage",
 			"V",
-			message.UplinkMessage.DecodedPayload.FenceVoltage,
+			message.FenceVoltage,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.FenceVoltageStd != nil {
+	if message.FenceVoltageStd != nil {
 		outputFloat(
 			"FenceVoltageStd",
 			"",
-			message.UplinkMessage.DecodedPayload.FenceVoltageStd,
+			message.FenceVoltageStd,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.FenceVoltageMin != nil {
+	if message.FenceVoltageMin != nil {
 		outputFloat(
 			"FenceVoltageMin",
 			"V",
-			message.UplinkMessage.DecodedPayload.FenceVoltageMin,
+			message.FenceVoltageMin,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.FenceVoltageMax != nil {
+	if message.FenceVoltageMax != nil {
 		outputFloat(
 			"FenceVoltageMax",
 			"V",
-			message.UplinkMessage.DecodedPayload.FenceVoltageMax,
+			message.FenceVoltageMax,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.BatteryVoltage != nil {
+	if message.BatteryVoltage != nil {
 		outputFloat(
 			"BatteryVoltage",
 			"V",
-			message.UplinkMessage.DecodedPayload.BatteryVoltage,
+			message.BatteryVoltage,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.RemainingCapacity != nil {
+	if message.RemainingCapacity != nil {
 		outputFloat(
 			"RemainingCapacity",
 			"mAh",
-			message.UplinkMessage.DecodedPayload.RemainingCapacity,
+			message.RemainingCapacity,
 		)
 	}
-	if message.UplinkMessage.DecodedPayload.Temperature != nil {
+	if message.Temperature != nil {
 		outputFloat(
 			"Temperature",
 			"°C",
-			message.UplinkMessage.DecodedPayload.Temperature,
+			message.Temperature,
 		)
 	}
 
 	// any points sent?
-	if count < 1 && c.LogDebug() {
-		log.Printf(
-			"ttn-fencyboy[%s]: could not extract any sensor data; payload='%s'",
-			c.Name(), input.Payload(),
-		)
-		return
+	if count < 1 {
+		return errors.New("could not extract any sensor data")
 	}
+
+	return nil
 }
@@ -10,6 +10,7 @@ import (
 func TestTtnFencyboy(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
+	ResetLoraFrameCounters("test-converter")
 
 	mockConfig := converter_mock.NewMockConfig(mockCtrl)
 	mockConfig.EXPECT().Name().Return("test-converter").AnyTimes()
@@ -92,7 +93,8 @@ func TestTtnFencyboy(t *testing.T) {
     }
   }`,
 			ExpectedLines: []string{
-				"lora,devEui=2B94F841240A45D3,device=fencyboy-0,gatewayEui=E45F01FFFEDECBE3,gatewayId=piegn-srv3 channelRssi=-89i,consumedAirtimeUs=77056i,gatewayIdx=0i,rssi=-89i,snr=4.25",
+				"lora,devEui=2B94F841240A45D3,device=fencyboy-0,gatewayEui=E45F01FFFEDECBE3,gatewayId=piegn-srv3 bandwidth=125000i,channelRssi=-89i,consumedAirtimeUs=77056i,fCnt=104i,fPort=1i,frequency=867300000i,gatewayIdx=0i,rssi=-89i,snr=4.25,spreadingFactor=7i",
+				"loraUplink,devEui=2B94F841240A45D3,device=fencyboy-0 fCnt=104i,gatewayCount=1i,missedUplinks=0i,rejoin=false",
 				"telemetry,device=fencyboy-0,field=ActiveMode,sensor=fencyboy boolValue=true",
 				"telemetry,device=fencyboy-0,field=BatteryVoltage,sensor=fencyboy,unit=V floatValue=3.361",
 				"telemetry,device=fencyboy-0,field=FenceVoltage,sensor=fencyboy,unit=V floatValue=10246",
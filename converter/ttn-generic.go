@@ -0,0 +1,79 @@
+package converter
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"strconv"
+	"time"
+)
+
+// genericFallbackEnabled returns true when the GenericFallback option of the converter is set
+func genericFallbackEnabled(c Config) bool {
+	gc, ok := c.(GenericFallbackConfig)
+	return ok && gc.GenericFallback()
+}
+
+// ttnGenericHandler is used for devices without a specific sub-converter when GenericFallback is enabled.
+// It flattens the decoded payload produced by a payload formatter; nested keys are joined by _.
+// example input:
+// - {"battery":{"voltage":3.1,"low":false},"temperature":21.5,"mode":"eco"}
+// is written as the fields battery_voltage, battery_low, temperature and mode
+func ttnGenericHandler(c Config, device, model string, receivedAt time.Time, decodedPayload []byte, outputFunc OutputFunc) error {
+	// parse payload
+	var message map[string]interface{}
+	if err := json.Unmarshal(decodedPayload, &message); err != nil {
+		return fmt.Errorf("cannot json decode: %s", err)
+	}
+
+	// send points
+	count := 0
+	var walk func(prefix string, value interface{})
+	walk = func(prefix string, value interface{}) {
+		m := telemetryOutputMessage{
+			timeStamp: receivedAt,
+			device:    device,
+			field:     prefix,
+			sensor:    model,
+		}
+
+		switch v := value.(type) {
+		case float64:
+			m.floatValue = &v
+		case bool:
+			m.boolValue = &v
+		case string:
+			m.stringValue = &v
+		case map[string]interface{}:
+			for key, e := range v {
+				walk(joinFieldPrefix(prefix, key), e)
+			}
+			return
+		case []interface{}:
+			for i, e := range v {
+				walk(joinFieldPrefix(prefix, strconv.Itoa(i)), e)
+			}
+			return
+		default:
+			// null values are ignored
+			return
+		}
+
+		count += 1
+		outputFunc(m)
+	}
+	walk("", message)
+
+	// any points sent?
+	if count < 1 {
+		return errors.New("could not extract any sensor data")
+	}
+
+	return nil
+}
+
+func joinFieldPrefix(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "_" + key
+}
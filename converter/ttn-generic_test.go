@@ -0,0 +1,110 @@
+package converter
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/koestler/go-mqtt-to-influx/v2/converter/mock"
+	"testing"
+	"time"
+)
+
+type genericFallbackTestConfig struct {
+	Config
+	genericFallback bool
+}
+
+func (c genericFallbackTestConfig) GenericFallback() bool {
+	return c.genericFallback
+}
+
+func TestTtnGeneric(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	ResetLoraFrameCounters("test-converter")
+
+	mockConfig := converter_mock.NewMockConfig(mockCtrl)
+	mockConfig.EXPECT().Name().Return("test-converter").AnyTimes()
+
+	mockTMConfig := converter_mock.NewMockTopicMatcherConfig(mockCtrl)
+	mockTMConfig.EXPECT().Topic().Return("v3/piegn@ttn/devices/%Device%/up").AnyTimes()
+	mockTMConfig.EXPECT().Device().Return("+").AnyTimes()
+	mockTMConfig.EXPECT().DeviceIsDynamic().Return(true).AnyTimes()
+
+	payload := `{
+  "end_device_ids": {"device_id": "em300-0", "dev_eui": "24E124136B502217"},
+  "received_at": "2025-02-01T12:00:00Z",
+  "uplink_message": {
+    "f_port": 85,
+    "f_cnt": 310,
+    "decoded_payload": {
+      "battery": {"level": 92, "low": false},
+      "temperature": 21.5,
+      "humidity": 48,
+      "mode": "eco",
+      "history": [1.5, 2],
+      "error": null
+    },
+    "rx_metadata": [],
+    "version_ids": {"brand_id": "milesight-iot", "model_id": "em300-th"}
+  }
+}`
+
+	expectedTimeStamp := time.Date(2025, time.February, 1, 12, 0, 0, 0, time.UTC)
+
+	disabled := TestStimuliResponse{
+		{
+			Topic:   "v3/piegn@ttn/devices/em300-0/up",
+			Payload: payload,
+			ExpectedLines: []string{
+				"loraUplink,devEui=24E124136B502217,device=em300-0 fCnt=310i,gatewayCount=0i,missedUplinks=0i,rejoin=false",
+			},
+			ExpectedTimeStamp: expectedTimeStamp,
+			ExpectedError:     true,
+		},
+	}
+	testStimuliResponse(t, mockCtrl, genericFallbackTestConfig{Config: mockConfig}, mockTMConfig, ttnHandler, disabled)
+
+	enabled := TestStimuliResponse{
+		{
+			Topic:   "v3/piegn@ttn/devices/em300-0/up",
+			Payload: payload,
+			ExpectedLines: []string{
+				"loraUplink,devEui=24E124136B502217,device=em300-0 fCnt=310i,gatewayCount=0i,missedUplinks=0i,rejoin=false",
+				"telemetry,device=em300-0,field=battery_level,sensor=em300-th floatValue=92",
+				"telemetry,device=em300-0,field=battery_low,sensor=em300-th boolValue=false",
+				"telemetry,device=em300-0,field=temperature,sensor=em300-th floatValue=21.5",
+				"telemetry,device=em300-0,field=humidity,sensor=em300-th floatValue=48",
+				"telemetry,device=em300-0,field=mode,sensor=em300-th stringValue=\"eco\"",
+				"telemetry,device=em300-0,field=history_0,sensor=em300-th floatValue=1.5",
+				"telemetry,device=em300-0,field=history_1,sensor=em300-th floatValue=2",
+			},
+			ExpectedTimeStamp: expectedTimeStamp,
+		}, {
+			// fallback to the device_id without VersionIds
+			Topic: "v3/piegn@ttn/devices/custom-0/up",
+			Payload: `{
+  "end_device_ids": {"device_id": "custom-0", "dev_eui": "0000000000000002"},
+  "received_at": "2025-02-01T12:00:00Z",
+  "uplink_message": {"f_cnt": 7, "decoded_payload": {"counter": 7}, "rx_metadata": []}
+}`,
+			ExpectedLines: []string{
+				"loraUplink,devEui=0000000000000002,device=custom-0 fCnt=7i,gatewayCount=0i,missedUplinks=0i,rejoin=false",
+				"telemetry,device=custom-0,field=counter,sensor=ttn floatValue=7",
+			},
+			ExpectedTimeStamp: expectedTimeStamp,
+		}, {
+			Topic: "v3/piegn@ttn/devices/custom-0/up",
+			Payload: `{
+  "end_device_ids": {"device_id": "custom-0", "dev_eui": "0000000000000002"},
+  "received_at": "2025-02-01T12:00:00Z",
+  "uplink_message": {"f_cnt": 2, "decoded_payload": {}, "rx_metadata": []}
+}`,
+			// the frame counter was reset, the device has rejoined
+			ExpectedLines: []string{
+				"loraUplink,devEui=0000000000000002,device=custom-0 fCnt=2i,gatewayCount=0i,missedUplinks=2i,rejoin=true",
+			},
+			ExpectedTimeStamp: expectedTimeStamp,
+			ExpectedError:     true,
+		},
+	}
+	testStimuliResponse(t, mockCtrl, genericFallbackTestConfig{Config: mockConfig, genericFallback: true}, mockTMConfig, ttnHandler, enabled)
+}
@@ -0,0 +1,67 @@
+package converter
+
+import (
+	"strconv"
+	"time"
+)
+
+type ttnLocation struct {
+	Latitude  float64  `json:"latitude"`
+	Longitude float64  `json:"longitude"`
+	Altitude  *float64 `json:"altitude"`
+	Accuracy  *float64 `json:"accuracy"`
+	Source    string   `json:"source"`
+}
+
+func (l ttnLocation) output(timeStamp time.Time, device, gateway, source string) locationOutputMessage {
+	if l.Source != "" {
+		source = l.Source
+	}
+	return locationOutputMessage{
+		timeStamp: timeStamp,
+		device:    device,
+		gateway:   gateway,
+		source:    source,
+		latitude:  l.Latitude,
+		longitude: l.Longitude,
+		altitude:  l.Altitude,
+		accuracy:  l.Accuracy,
+	}
+}
+
+// decodedPayloadLocation searches for coordinates in the decoded payload like the ones sent by gps trackers,
+// e.g. {"latitude":47.37,"longitude":8.54,"altitude":410} or {"lat":"47.37","lng":"8.54"}
+func decodedPayloadLocation(decodedPayload []byte) (loc ttnLocation, ok bool) {
+	var values map[string]interface{}
+	if err := json.Unmarshal(decodedPayload, &values); err != nil {
+		return loc, false
+	}
+
+	find := func(keys ...string) *float64 {
+		for _, key := range keys {
+			switch v := values[key].(type) {
+			case float64:
+				return &v
+			case string:
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					return &f
+				}
+			}
+		}
+		return nil
+	}
+
+	lat := find("latitude", "Latitude", "lat")
+	lon := find("longitude", "Longitude", "lon", "lng")
+	if lat == nil || lon == nil || (*lat == 0 && *lon == 0) {
+		// 0/0 is sent by many trackers when there is no gps fix
+		return loc, false
+	}
+
+	return ttnLocation{
+		Latitude:  *lat,
+		Longitude: *lon,
+		Altitude:  find("altitude", "Altitude", "alt"),
+		Accuracy:  find("accuracy", "Accuracy"),
+	}, true
+}
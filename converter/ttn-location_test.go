@@ -0,0 +1,111 @@
+package converter
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/koestler/go-mqtt-to-influx/v2/converter/mock"
+	"testing"
+	"time"
+)
+
+func TestTtnLocation(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	ResetLoraFrameCounters("test-converter")
+
+	mockConfig := converter_mock.NewMockConfig(mockCtrl)
+	mockConfig.EXPECT().Name().Return("test-converter").AnyTimes()
+
+	mockTMConfig := converter_mock.NewMockTopicMatcherConfig(mockCtrl)
+	mockTMConfig.EXPECT().Topic().Return("v3/piegn@ttn/devices/%Device%/up").AnyTimes()
+	mockTMConfig.EXPECT().Device().Return("+").AnyTimes()
+	mockTMConfig.EXPECT().DeviceIsDynamic().Return(true).AnyTimes()
+
+	mockSolvedTMConfig := converter_mock.NewMockTopicMatcherConfig(mockCtrl)
+	mockSolvedTMConfig.EXPECT().Topic().Return("v3/piegn@ttn/devices/%Device%/location/solved").AnyTimes()
+	mockSolvedTMConfig.EXPECT().Device().Return("+").AnyTimes()
+	mockSolvedTMConfig.EXPECT().DeviceIsDynamic().Return(true).AnyTimes()
+
+	expectedTimeStamp := time.Date(2025, time.April, 5, 14, 30, 0, 0, time.UTC)
+
+	stimuli := TestStimuliResponse{
+		{
+			Topic: "v3/piegn@ttn/devices/tracker-0/up",
+			Payload: `{
+  "end_device_ids": {"device_id": "tracker-0", "dev_eui": "A840410000000042"},
+  "received_at": "2025-04-05T14:30:00Z",
+  "uplink_message": {
+    "f_port": 2,
+    "f_cnt": 12,
+    "decoded_payload": {"latitude": 47.3769, "longitude": 8.5417, "alt": 408, "battery": 3.9},
+    "rx_metadata": [
+      {
+        "gateway_ids": {"gateway_id": "piegn-srv3", "eui": "E45F01FFFEDECBE3"},
+        "rssi": -97,
+        "channel_rssi": -97,
+        "snr": 3.5,
+        "location": {"latitude": 47.3701, "longitude": 8.5392, "altitude": 420, "source": "SOURCE_REGISTRY"}
+      }
+    ],
+    "locations": {
+      "frm-payload": {"latitude": 47.3769, "longitude": 8.5417, "accuracy": 12, "source": "SOURCE_GPS"}
+    },
+    "version_ids": {"brand_id": "generic", "model_id": "tracker"}
+  }
+}`,
+			ExpectedLines: []string{
+				"lora,devEui=A840410000000042,device=tracker-0,gatewayEui=E45F01FFFEDECBE3,gatewayId=piegn-srv3 channelRssi=-97i,consumedAirtimeUs=0i,fCnt=12i,fPort=2i,gatewayIdx=0i,rssi=-97i,snr=3.5",
+				"loraUplink,devEui=A840410000000042,device=tracker-0 fCnt=12i,gatewayCount=1i,missedUplinks=0i,rejoin=false",
+				"location,gateway=piegn-srv3,source=SOURCE_REGISTRY altitude=420,lat=47.3701,lon=8.5392",
+				"location,device=tracker-0,source=SOURCE_GPS accuracy=12,lat=47.3769,lon=8.5417",
+				"location,device=tracker-0,source=decoded_payload altitude=408,lat=47.3769,lon=8.5417",
+				"telemetry,device=tracker-0,field=latitude,sensor=tracker floatValue=47.3769",
+				"telemetry,device=tracker-0,field=longitude,sensor=tracker floatValue=8.5417",
+				"telemetry,device=tracker-0,field=alt,sensor=tracker floatValue=408",
+				"telemetry,device=tracker-0,field=battery,sensor=tracker floatValue=3.9",
+			},
+			ExpectedTimeStamp: expectedTimeStamp,
+		}, {
+			// no gps fix
+			Topic: "v3/piegn@ttn/devices/tracker-0/up",
+			Payload: `{
+  "end_device_ids": {"device_id": "tracker-0", "dev_eui": "A840410000000042"},
+  "received_at": "2025-04-05T14:30:00Z",
+  "uplink_message": {
+    "f_port": 2,
+    "f_cnt": 13,
+    "decoded_payload": {"lat": 0, "lng": 0},
+    "rx_metadata": [],
+    "version_ids": {"brand_id": "generic", "model_id": "tracker"}
+  }
+}`,
+			ExpectedLines: []string{
+				"loraUplink,devEui=A840410000000042,device=tracker-0 fCnt=13i,gatewayCount=0i,missedUplinks=0i,rejoin=false",
+				"telemetry,device=tracker-0,field=lat,sensor=tracker floatValue=0",
+				"telemetry,device=tracker-0,field=lng,sensor=tracker floatValue=0",
+			},
+			ExpectedTimeStamp: expectedTimeStamp,
+		},
+	}
+	config := genericFallbackTestConfig{Config: mockConfig, genericFallback: true}
+	testStimuliResponse(t, mockCtrl, config, mockTMConfig, ttnHandler, stimuli)
+
+	solvedStimuli := TestStimuliResponse{
+		{
+			Topic: "v3/piegn@ttn/devices/tracker-0/location/solved",
+			Payload: `{
+  "end_device_ids": {"device_id": "tracker-0", "dev_eui": "A840410000000042"},
+  "received_at": "2025-04-05T14:30:00Z",
+  "location_solved": {
+    "service": "lora-cloud-device-management-v1-gnss",
+    "location": {"latitude": 47.377, "longitude": 8.5418, "altitude": 410, "accuracy": 25, "source": "SOURCE_GPS"}
+  }
+}`,
+			ExpectedLines: []string{
+				"location,device=tracker-0,source=SOURCE_GPS accuracy=25,altitude=410,lat=47.377,lon=8.5418",
+			},
+			ExpectedTimeStamp: expectedTimeStamp,
+		},
+	}
+
+	testStimuliResponse(t, mockCtrl, config, mockSolvedTMConfig, ttnHandler, solvedStimuli)
+}
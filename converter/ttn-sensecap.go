@@ -1,6 +1,8 @@
 package converter
 
 import (
+	"fmt"
+	"github.com/pkg/errors"
 	"log"
 	"strconv"
 	"time"
@@ -9,32 +11,25 @@ import (
 // Parse payload of SenseCAP S2120 8-in-1 LoRaWAN Weather Sensor
 // Code is compatible with TTN decoder function shown here:
 // https://github.com/Seeed-Solution/TTN-Payload-Decoder/blob/master/SenseCAP_S2120_Weather_Station_Decoder.js
-type ttnSensecapMessage struct {
-	ReceivedAt    time.Time `json:"received_at"`
-	UplinkMessage struct {
-		DecodedPayload struct {
-			Err      int `json:"err"`
-			Messages []struct {
-				MeasurementId    string      `json:"measurementId"`
-				MeasurementValue interface{} `json:"measurementValue"`
-			} `json:"messages"`
-			Valid bool `json:"valid"`
-		} `json:"decoded_payload"`
-	} `json:"uplink_message"`
+type ttnSensecapPayload struct {
+	Err      int `json:"err"`
+	Messages []struct {
+		MeasurementId    string      `json:"measurementId"`
+		MeasurementValue interface{} `json:"measurementValue"`
+	} `json:"messages"`
+	Valid bool `json:"valid"`
 }
 
-func ttnSensecapHandler(c Config, device, model string, input Input, outputFunc OutputFunc) {
+func ttnSensecapHandler(c Config, device, model string, receivedAt time.Time, decodedPayload []byte, outputFunc OutputFunc) error {
 	// parse payload
-	var message ttnSensecapMessage
-	payload := input.Payload()
-	if err := json.Unmarshal(payload, &message); err != nil {
-		log.Printf("ttn-sensecap[%s]: cannot json decode: %s", c.Name(), err)
-		return
+	var message ttnSensecapPayload
+	if err := json.Unmarshal(decodedPayload, &message); err != nil {
+		return fmt.Errorf("cannot json decode: %s", err)
 	}
 
 	// send points
 	count := 0
-	for _, m := range message.UplinkMessage.DecodedPayload.Messages {
+	for _, m := range message.Messages {
 		mId, err := strconv.Atoi(m.MeasurementId)
 		if err != nil {
 			log.Printf("ttn-sensecap[%s]: invalid MeasurementId='%s'", c.Name(), m.MeasurementId)
@@ -62,7 +57,7 @@ func ttnSensecapHandler(c Config, device, model string, input Input, outputFunc
 		}
 
 		outputFunc(telemetryOutputMessage{
-			timeStamp:  message.ReceivedAt,
+			timeStamp:  receivedAt,
 			device:     device,
 			field:      name,
 			unit:       &unit,
@@ -72,12 +67,11 @@ func ttnSensecapHandler(c Config, device, model string, input Input, outputFunc
 	}
 
 	// any points sent?
-	if count < 1 && c.LogDebug() {
-		log.Printf("ttn-sensecap[%s]: no measurement saved; payload='%s'",
-			c.Name(), input.Payload(),
-		)
-		return
+	if count < 1 {
+		return errors.New("no measurement saved")
 	}
+
+	return nil
 }
 
 // List from https://sensecap-docs.seeed.cc/measurement_list.html
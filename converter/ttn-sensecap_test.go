@@ -11,6 +11,7 @@ import (
 func TestTtnSensecap(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
+	ResetLoraFrameCounters("test-converter")
 
 	mockConfig := converter_mock.NewMockConfig(mockCtrl)
 	mockConfig.EXPECT().Name().Return("test-converter").AnyTimes()
@@ -136,7 +137,8 @@ func TestTtnSensecap(t *testing.T) {
     }
 }`,
 			ExpectedLines: []string{
-				"lora,devEui=2CF7F1C0443003DD,device=s2120-0,gatewayEui=E45F01FFFEDECBE3,gatewayId=piegn-srv3 channelRssi=-82i,consumedAirtimeUs=71936i,gatewayIdx=0i,rssi=-82i,snr=5.75",
+				"lora,devEui=2CF7F1C0443003DD,device=s2120-0,gatewayEui=E45F01FFFEDECBE3,gatewayId=piegn-srv3 bandwidth=125000i,channelRssi=-82i,consumedAirtimeUs=71936i,fCnt=58i,fPort=3i,frequency=867900000i,gatewayIdx=0i,rssi=-82i,snr=5.75,spreadingFactor=7i",
+				"loraUplink,devEui=2CF7F1C0443003DD,device=s2120-0 fCnt=58i,gatewayCount=1i,missedUplinks=0i,rejoin=false",
 				"telemetry,device=s2120-0,field=Air\\ Humidity,sensor=sensecaps2120-8-in-1,unit=%\\ RH floatValue=35",
 				"telemetry,device=s2120-0,field=Air\\ Temperature,sensor=sensecaps2120-8-in-1,unit=°C floatValue=1.8",
 				"telemetry,device=s2120-0,field=Barometric\\ Pressure,sensor=sensecaps2120-8-in-1,unit=Pa floatValue=97520",
@@ -264,7 +266,8 @@ func TestTtnSensecap(t *testing.T) {
     }
 }`,
 			ExpectedLines: []string{
-				"lora,devEui=2CF7F1C0443003DD,device=s2120-0,gatewayEui=E45F01FFFEDECBE3,gatewayId=piegn-srv3 channelRssi=-80i,consumedAirtimeUs=71936i,gatewayIdx=0i,rssi=-80i,snr=6.5",
+				"lora,devEui=2CF7F1C0443003DD,device=s2120-0,gatewayEui=E45F01FFFEDECBE3,gatewayId=piegn-srv3 bandwidth=125000i,channelRssi=-80i,consumedAirtimeUs=71936i,fCnt=1041i,fPort=3i,frequency=867700000i,gatewayIdx=0i,rssi=-80i,snr=6.5,spreadingFactor=7i",
+				"loraUplink,devEui=2CF7F1C0443003DD,device=s2120-0 fCnt=1041i,gatewayCount=1i,missedUplinks=982i,rejoin=false",
 				"telemetry,device=s2120-0,field=Air\\ Humidity,sensor=sensecaps2120-8-in-1,unit=%\\ RH floatValue=66",
 				"telemetry,device=s2120-0,field=Air\\ Temperature,sensor=sensecaps2120-8-in-1,unit=°C floatValue=6.5",
 				"telemetry,device=s2120-0,field=Barometric\\ Pressure,sensor=sensecaps2120-8-in-1,unit=Pa floatValue=96920",
@@ -337,7 +340,8 @@ func TestTtnSensecap(t *testing.T) {
     }
 }`,
 			ExpectedLines: []string{
-				"lora,devEui=2CF7F1C0443003DD,device=s2120-0,gatewayEui=E45F01FFFEDECBE3,gatewayId=piegn-srv3 channelRssi=-80i,consumedAirtimeUs=71936i,gatewayIdx=0i,rssi=-80i,snr=6.5",
+				"lora,devEui=2CF7F1C0443003DD,device=s2120-0,gatewayEui=E45F01FFFEDECBE3,gatewayId=piegn-srv3 channelRssi=-80i,consumedAirtimeUs=71936i,fCnt=1041i,fPort=3i,gatewayIdx=0i,rssi=-80i,snr=6.5",
+				"loraUplink,devEui=2CF7F1C0443003DD,device=s2120-0 fCnt=1041i,gatewayCount=1i,missedUplinks=0i,rejoin=false",
 				"telemetry,device=s2120-0,field=Air\\ Temperature,sensor=sensecaps2120-8-in-1,unit=°C floatValue=-1.5",
 			},
 			// 2024-01-24T20:43:56.136229322Z
@@ -1,7 +1,8 @@
 package converter
 
 import (
-	"log"
+	"fmt"
+	jsoniter "github.com/json-iterator/go"
 	"strings"
 	"time"
 )
@@ -13,26 +14,43 @@ type ttnMessage struct {
 	} `json:"end_device_ids"`
 	ReceivedAt    time.Time `json:"received_at"`
 	UplinkMessage struct {
+		FPort      int64 `json:"f_port"`
+		FCnt       int64 `json:"f_cnt"` // omitted when 0
 		RxMetadata []struct {
 			GatewayIds struct {
 				GatewayId string `json:"gateway_id"`
 				Eui       string `json:"eui"`
 			} `json:"gateway_ids"`
-			Time        time.Time `json:"time"`
-			Timestamp   int64     `json:"timestamp"`
-			Rssi        int64     `json:"rssi"`
-			ChannelRssi int64     `json:"channel_rssi"`
-			Snr         float64   `json:"snr"`
-			UplinkToken string    `json:"uplink_token"`
-			GpsTime     time.Time `json:"gps_time"`
-			ReceivedAt  time.Time `json:"received_at"`
+			Time        time.Time    `json:"time"`
+			Timestamp   int64        `json:"timestamp"`
+			Rssi        int64        `json:"rssi"`
+			ChannelRssi int64        `json:"channel_rssi"`
+			Snr         float64      `json:"snr"`
+			UplinkToken string       `json:"uplink_token"`
+			GpsTime     time.Time    `json:"gps_time"`
+			ReceivedAt  time.Time    `json:"received_at"`
+			Location    *ttnLocation `json:"location"`
 		} `json:"rx_metadata"`
-		ConsumedAirtime string `json:"consumed_airtime"`
+		Locations map[string]ttnLocation `json:"locations"`
+		Settings  struct {
+			DataRate struct {
+				Lora struct {
+					Bandwidth       int64 `json:"bandwidth"`
+					SpreadingFactor int64 `json:"spreading_factor"`
+				} `json:"lora"`
+			} `json:"data_rate"`
+			Frequency int64 `json:"frequency,string"`
+		} `json:"settings"`
+		ConsumedAirtime string              `json:"consumed_airtime"`
+		DecodedPayload  jsoniter.RawMessage `json:"decoded_payload"`
 		VersionIds      *struct {
 			BrandId string `json:"brand_id"`
 			ModelId string `json:"model_id"`
 		} `json:"version_ids"`
 	} `json:"uplink_message"`
+	LocationSolved *struct {
+		Location ttnLocation `json:"location"`
+	} `json:"location_solved"`
 }
 
 func init() {
@@ -43,25 +61,30 @@ func init() {
 	registerHandler("ttn-dragino", ttnHandler)
 }
 
-func ttnHandler(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) {
+func ttnHandler(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) error {
 	// parse topic
 	device, err := tm.MatchDevice(input.Topic())
 	if err != nil {
-		log.Printf("ttn[%s]: cannot extract device from topic='%s' err=%s", c.Name(), input.Topic(), err)
-		return
+		return fmt.Errorf("cannot extract device from topic='%s' err=%s", input.Topic(), err)
 	}
 
 	// parse payload
 	var message ttnMessage
 	payload := input.Payload()
 	if err := json.Unmarshal(payload, &message); err != nil {
-		log.Printf("ttn[%s]: cannot json decode: %s", c.Name(), err)
-		return
+		return fmt.Errorf("cannot json decode: %s", err)
+	}
+
+	// location solved events are sent on a separate topic and contain nothing else
+	if ls := message.LocationSolved; ls != nil {
+		outputFunc(ls.Location.output(message.ReceivedAt, device, "", "location_solved"))
+		return nil
 	}
 
 	// save general lora data
-	for gatewayIdx, rx := range message.UplinkMessage.RxMetadata {
-		airtime, err := time.ParseDuration(message.UplinkMessage.ConsumedAirtime)
+	uplink := message.UplinkMessage
+	for gatewayIdx, rx := range uplink.RxMetadata {
+		airtime, err := time.ParseDuration(uplink.ConsumedAirtime)
 		if err != nil {
 			airtime = 0
 		}
@@ -77,20 +100,57 @@ func ttnHandler(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) {
 			snr:             rx.Snr,
 			consumedAirtime: airtime,
 			gatewayIdx:      gatewayIdx,
+			fCnt:            uplink.FCnt,
+			fPort:           uplink.FPort,
+			spreadingFactor: uplink.Settings.DataRate.Lora.SpreadingFactor,
+			bandwidth:       uplink.Settings.DataRate.Lora.Bandwidth,
+			frequency:       uplink.Settings.Frequency,
 		})
 	}
 
+	// save frame counter based statistics
+	missed, rejoin := loraFrameCounterUpdate(c.Name(), message.EndDeviceIds.DevEui, uplink.FCnt)
+	outputFunc(loraUplinkOutputMessage{
+		timeStamp:     message.ReceivedAt,
+		device:        device,
+		devEui:        message.EndDeviceIds.DevEui,
+		fCnt:          uplink.FCnt,
+		missedUplinks: missed,
+		rejoin:        rejoin,
+		gatewayCount:  len(uplink.RxMetadata),
+	})
+
+	// save locations of the gateways and the device
+	for _, rx := range uplink.RxMetadata {
+		if rx.Location != nil {
+			outputFunc(rx.Location.output(message.ReceivedAt, "", rx.GatewayIds.GatewayId, "gateway"))
+		}
+	}
+	for source, location := range uplink.Locations {
+		outputFunc(location.output(message.ReceivedAt, device, "", source))
+	}
+	if location, ok := decodedPayloadLocation(uplink.DecodedPayload); ok {
+		outputFunc(location.output(message.ReceivedAt, device, "", "decoded_payload"))
+	}
+
 	if vids := message.UplinkMessage.VersionIds; vids != nil {
 		switch vids.BrandId {
 		case "dragino":
-			ttnDraginoHandler(c, device, vids.ModelId, input, outputFunc)
+			return ttnDraginoHandler(c, device, vids.ModelId, message.ReceivedAt, message.UplinkMessage.DecodedPayload, outputFunc)
 		case "sensecap":
-			ttnSensecapHandler(c, device, vids.ModelId, input, outputFunc)
+			return ttnSensecapHandler(c, device, vids.ModelId, message.ReceivedAt, message.UplinkMessage.DecodedPayload, outputFunc)
 		case "fencyboy":
-			ttnFencyboyHandler(c, device, vids.ModelId, input, outputFunc)
+			return ttnFencyboyHandler(c, device, vids.ModelId, message.ReceivedAt, message.UplinkMessage.DecodedPayload, outputFunc)
 		default:
+			if genericFallbackEnabled(c) {
+				sensor := vids.ModelId
+				if sensor == "" {
+					sensor = vids.BrandId
+				}
+				return ttnGenericHandler(c, device, sensor, message.ReceivedAt, message.UplinkMessage.DecodedPayload, outputFunc)
+			}
 			if vids.BrandId != "" || vids.ModelId != "" {
-				log.Printf("ttn[%s]: VersionIds present, but no decoder for brand='%s', model='%s'", c.Name(),
+				return fmt.Errorf("VersionIds present, but no decoder for brand='%s', model='%s'",
 					vids.BrandId, vids.ModelId,
 				)
 			}
@@ -99,13 +159,17 @@ func ttnHandler(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) {
 		deviceId := message.EndDeviceIds.DeviceId
 		if strings.Contains(deviceId, "dragino") ||
 			strings.Contains(deviceId, "d20s") { // temporary solution until sensor is in the device registry
-			ttnDraginoHandler(c, device, "dragino", input, outputFunc)
+			return ttnDraginoHandler(c, device, "dragino", message.ReceivedAt, message.UplinkMessage.DecodedPayload, outputFunc)
 		} else if strings.Contains(deviceId, "sensecap") {
-			ttnSensecapHandler(c, device, "sensecap", input, outputFunc)
+			return ttnSensecapHandler(c, device, "sensecap", message.ReceivedAt, message.UplinkMessage.DecodedPayload, outputFunc)
 		} else if strings.Contains(deviceId, "fencyboy") {
-			ttnFencyboyHandler(c, device, "fencyboy", input, outputFunc)
+			return ttnFencyboyHandler(c, device, "fencyboy", message.ReceivedAt, message.UplinkMessage.DecodedPayload, outputFunc)
+		} else if genericFallbackEnabled(c) {
+			return ttnGenericHandler(c, device, "ttn", message.ReceivedAt, message.UplinkMessage.DecodedPayload, outputFunc)
 		} else {
-			log.Printf("ttn[%s]: fallback to device_id, but no match for device_id='%s'", c.Name(), deviceId)
+			return fmt.Errorf("fallback to device_id, but no match for device_id='%s'", deviceId)
 		}
 	}
+
+	return nil
 }
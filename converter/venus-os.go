@@ -0,0 +1,95 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+)
+
+type venusOsMessage struct {
+	Value interface{} `json:"value"`
+}
+
+// venusOsUnits defines the units by the last element of the dbus path
+var venusOsUnits = map[string]string{
+	"Voltage":          "V",
+	"Current":          "A",
+	"Power":            "W",
+	"Temperature":      "°C",
+	"Soc":              "%",
+	"ConsumedAmphours": "Ah",
+	"TimeToGo":         "s",
+	"Yield":            "kWh",
+	"YieldToday":       "kWh",
+	"YieldYesterday":   "kWh",
+	"MaxPowerToday":    "W",
+	"V":                "V",
+	"I":                "A",
+	"P":                "W",
+	"S":                "VA",
+	"F":                "Hz",
+}
+
+func init() {
+	registerHandler("venus-os", venusOsHandler)
+}
+
+// parses messages published by dbus-mqtt / dbus-flashmq of Victron Venus OS
+// the device given by the topic matcher must be <portalId>/<service>/<instance>/<path>, e.g.
+// - N/c0619ab12345/battery/512/Dc/0/Voltage {"value": 26.51}
+// - N/c0619ab12345/solarcharger/279/Yield/User {"value": 1043.2}
+// - N/c0619ab12345/system/0/SystemState/State {"value": 9}
+// Venus OS only publishes while keepalive messages are received, see PeriodicPublishes of the mqtt client config.
+func venusOsHandler(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) error {
+	// use our time
+	timeStamp := receiveTime(input)
+
+	// parse topic
+	matched, err := tm.MatchDevice(input.Topic())
+	if err != nil {
+		return fmt.Errorf("cannot extract device from topic='%s' err=%s", input.Topic(), err)
+	}
+	parts := strings.SplitN(matched, "/", 4)
+	if len(parts) < 4 {
+		return fmt.Errorf("cannot extract portalId, service, instance and path from device='%s'", matched)
+	}
+	portalId, service, instance, path := parts[0], parts[1], parts[2], parts[3]
+
+	// an empty payload is sent when a service disappears
+	if len(input.Payload()) < 1 {
+		return nil
+	}
+
+	// parse payload
+	var message venusOsMessage
+	if err := json.Unmarshal(input.Payload(), &message); err != nil {
+		return fmt.Errorf("cannot json decode: %s", err)
+	}
+
+	m := telemetryOutputMessage{
+		timeStamp: timeStamp,
+		device:    portalId + "/" + service,
+		field:     path,
+		sensor:    instance,
+	}
+
+	switch v := message.Value.(type) {
+	case nil:
+		// the value is invalid, e.g. no battery temperature sensor is connected
+		return nil
+	case float64:
+		m.floatValue = &v
+		if unit, ok := venusOsUnits[path[strings.LastIndex(path, "/")+1:]]; ok {
+			m.unit = &unit
+		}
+	case string:
+		m.stringValue = &v
+	case bool:
+		m.boolValue = &v
+	default:
+		// lists and objects like the ones of the Devices paths are not used
+		return nil
+	}
+
+	outputFunc(m)
+	return nil
+}
@@ -0,0 +1,95 @@
+package converter
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/koestler/go-mqtt-to-influx/v2/converter/mock"
+	"testing"
+	"time"
+)
+
+func TestVenusOs(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockConfig := converter_mock.NewMockConfig(mockCtrl)
+	mockConfig.EXPECT().Name().Return("test-converter").AnyTimes()
+
+	mockTMConfig := converter_mock.NewMockTopicMatcherConfig(mockCtrl)
+	mockTMConfig.EXPECT().Topic().Return("N/%Device%").AnyTimes()
+	mockTMConfig.EXPECT().Device().Return("+/#").AnyTimes()
+	mockTMConfig.EXPECT().DeviceIsDynamic().Return(true).AnyTimes()
+
+	now := time.Now()
+
+	stimuli := TestStimuliResponse{
+		{
+			Topic:   "N/c0619ab12345/battery/512/Dc/0/Voltage",
+			Payload: `{"value": 26.51}`,
+			ExpectedLines: []string{
+				"telemetry,device=c0619ab12345/battery,field=Dc/0/Voltage,sensor=512,unit=V floatValue=26.51",
+			},
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:   "N/c0619ab12345/battery/512/Soc",
+			Payload: `{"value": 87.5}`,
+			ExpectedLines: []string{
+				"telemetry,device=c0619ab12345/battery,field=Soc,sensor=512,unit=% floatValue=87.5",
+			},
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:   "N/c0619ab12345/vebus/276/Ac/Out/L1/P",
+			Payload: `{"value": 412}`,
+			ExpectedLines: []string{
+				"telemetry,device=c0619ab12345/vebus,field=Ac/Out/L1/P,sensor=276,unit=W floatValue=412",
+			},
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:   "N/c0619ab12345/system/0/SystemState/State",
+			Payload: `{"value": 9}`,
+			ExpectedLines: []string{
+				"telemetry,device=c0619ab12345/system,field=SystemState/State,sensor=0 floatValue=9",
+			},
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:   "N/c0619ab12345/battery/512/ProductName",
+			Payload: `{"value": "SmartShunt 500A/50mV"}`,
+			ExpectedLines: []string{
+				"telemetry,device=c0619ab12345/battery,field=ProductName,sensor=512 stringValue=\"SmartShunt 500A/50mV\"",
+			},
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:             "N/c0619ab12345/battery/512/Dc/0/Temperature",
+			Payload:           `{"value": null}`,
+			ExpectedLines:     []string{},
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:             "N/c0619ab12345/system/0/Batteries",
+			Payload:           `{"value": [{"id": "com.victronenergy.battery.ttyS5", "soc": 87.5}]}`,
+			ExpectedLines:     []string{},
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:             "N/c0619ab12345/battery/512/Soc",
+			Payload:           ``,
+			ExpectedLines:     []string{},
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:             "N/c0619ab12345/battery/512/Soc",
+			Payload:           `{"value": `,
+			ExpectedLines:     []string{},
+			ExpectedError:     true,
+			ExpectedTimeStamp: now,
+		}, {
+			Topic:             "N/c0619ab12345/keepalive",
+			Payload:           ``,
+			ExpectedLines:     []string{},
+			ExpectedError:     true,
+			ExpectedTimeStamp: now,
+		},
+	}
+
+	if h, err := GetHandler("venus-os"); err != nil {
+		t.Errorf("did not expect an error while getting handler: %s", err)
+	} else {
+		testStimuliResponse(t, mockCtrl, mockConfig, mockTMConfig, h, stimuli)
+	}
+}
@@ -0,0 +1,164 @@
+package converter
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"strings"
+	"sync"
+)
+
+// zigbee2mqttBridgeDevice is an entry of the retained zigbee2mqtt/bridge/devices message
+type zigbee2mqttBridgeDevice struct {
+	FriendlyName string `json:"friendly_name"`
+	IeeeAddress  string `json:"ieee_address"`
+	Definition   *struct {
+		Model  string `json:"model"`
+		Vendor string `json:"vendor"`
+	} `json:"definition"`
+}
+
+type zigbee2mqttDevice struct {
+	ieeeAddress string
+	sensor      string
+}
+
+var (
+	// zigbee2mqttDevices holds the devices known from the bridge/devices message by converter name and friendly name
+	zigbee2mqttDevices      = make(map[string]map[string]zigbee2mqttDevice)
+	zigbee2mqttDevicesMutex sync.RWMutex
+)
+
+var zigbee2mqttUnits = map[string]string{
+	"temperature":        "°C",
+	"local_temperature":  "°C",
+	"device_temperature": "°C",
+	"humidity":           "%",
+	"soil_moisture":      "%",
+	"battery":            "%",
+	"pressure":           "hPa",
+	"voltage":            "mV",
+	"illuminance_lux":    "lx",
+	"power":              "W",
+	"energy":             "kWh",
+	"current":            "A",
+	"co2":                "ppm",
+	"voc":                "ppb",
+	"pm25":               "µg/m³",
+}
+
+func init() {
+	registerHandler("zigbee2mqtt", zigbee2mqttHandler)
+}
+
+func zigbee2mqttHandler(c Config, tm TopicMatcher, input Input, outputFunc OutputFunc) error {
+	// use our time
+	timeStamp := receiveTime(input)
+
+	// parse topic
+	device, err := tm.MatchDevice(input.Topic())
+	if err != nil {
+		return fmt.Errorf("cannot extract device from topic='%s' err=%s", input.Topic(), err)
+	}
+
+	// the bridge/devices message is subscribed using a fixed topic, e.g. zigbee2mqtt/bridge/devices
+	if topic := tm.GetSubscribeTopic(); input.Topic() == topic && strings.HasSuffix(topic, "/bridge/devices") {
+		return zigbee2mqttBridgeDevicesHandler(c, input)
+	}
+
+	// parse payload
+	var message map[string]interface{}
+	if err := json.Unmarshal(input.Payload(), &message); err != nil {
+		return fmt.Errorf("cannot json decode: %s", err)
+	}
+
+	zigbee2mqttDevicesMutex.RLock()
+	info, ok := zigbee2mqttDevices[c.Name()][device]
+	zigbee2mqttDevicesMutex.RUnlock()
+	if !ok {
+		info.sensor = "zigbee2mqtt"
+	}
+
+	// send points
+	count := 0
+
+	if lq, ok := message["linkquality"].(float64); ok {
+		count += 1
+		outputFunc(zigbeeOutputMessage{
+			timeStamp:   timeStamp,
+			device:      device,
+			ieeeAddress: info.ieeeAddress,
+			linkQuality: int64(lq),
+		})
+		delete(message, "linkquality")
+	}
+
+	var walk func(prefix string, values map[string]interface{})
+	walk = func(prefix string, values map[string]interface{}) {
+		for key, value := range values {
+			field := prefix + key
+			m := telemetryOutputMessage{
+				timeStamp: timeStamp,
+				device:    device,
+				field:     field,
+				sensor:    info.sensor,
+			}
+			if unit, ok := zigbee2mqttUnits[field]; ok {
+				m.unit = &unit
+			}
+
+			switch v := value.(type) {
+			case float64:
+				m.floatValue = &v
+			case bool:
+				m.boolValue = &v
+			case string:
+				m.stringValue = &v
+			case map[string]interface{}:
+				walk(field+"_", v)
+				continue
+			default:
+				// arrays and null values are ignored
+				continue
+			}
+
+			count += 1
+			outputFunc(m)
+		}
+	}
+	walk("", message)
+
+	if count < 1 {
+		return errors.New("could not extract any sensor data")
+	}
+
+	return nil
+}
+
+// zigbee2mqttBridgeDevicesHandler replaces the known devices of this converter by the ones given in the
+// retained bridge/devices message; they are used to tag the points by model and vendor
+func zigbee2mqttBridgeDevicesHandler(c Config, input Input) error {
+	var message []zigbee2mqttBridgeDevice
+	if err := json.Unmarshal(input.Payload(), &message); err != nil {
+		return fmt.Errorf("cannot json decode: %s", err)
+	}
+
+	devices := make(map[string]zigbee2mqttDevice, len(message))
+	for _, d := range message {
+		info := zigbee2mqttDevice{
+			ieeeAddress: d.IeeeAddress,
+			sensor:      "zigbee2mqtt",
+		}
+		if def := d.Definition; def != nil {
+			if sensor := strings.TrimSpace(def.Vendor + " " + def.Model); sensor != "" {
+				info.sensor = sensor
+			}
+		}
+		devices[d.FriendlyName] = info
+	}
+
+	zigbee2mqttDevicesMutex.Lock()
+	defer zigbee2mqttDevicesMutex.Unlock()
+	zigbee2mqttDevices[c.Name()] = devices
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package converter
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/koestler/go-mqtt-to-influx/v2/converter/mock"
+	"testing"
+	"time"
+)
+
+func TestZigbee2mqtt(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockConfig := converter_mock.NewMockConfig(mockCtrl)
+	mockConfig.EXPECT().Name().Return("test-converter").AnyTimes()
+	mockConfig.EXPECT().LogDebug().Return(false).AnyTimes()
+
+	mockTMConfig := converter_mock.NewMockTopicMatcherConfig(mockCtrl)
+	mockTMConfig.EXPECT().Topic().Return("zigbee2mqtt/%Device%").AnyTimes()
+	mockTMConfig.EXPECT().Device().Return("+").AnyTimes()
+	mockTMConfig.EXPECT().DeviceIsDynamic().Return(true).AnyTimes()
+
+	mockBridgeTMConfig := converter_mock.NewMockTopicMatcherConfig(mockCtrl)
+	mockBridgeTMConfig.EXPECT().Topic().Return("zigbee2mqtt/bridge/devices").AnyTimes()
+	mockBridgeTMConfig.EXPECT().Device().Return("bridge").AnyTimes()
+	mockBridgeTMConfig.EXPECT().DeviceIsDynamic().Return(false).AnyTimes()
+
+	mockNestedTMConfig := converter_mock.NewMockTopicMatcherConfig(mockCtrl)
+	mockNestedTMConfig.EXPECT().Topic().Return("zigbee2mqtt/%Device%").AnyTimes()
+	mockNestedTMConfig.EXPECT().Device().Return("+/+").AnyTimes()
+	mockNestedTMConfig.EXPECT().DeviceIsDynamic().Return(true).AnyTimes()
+
+	sensorStimuli := TestStimuliResponse{
+		{
+			Topic:   "zigbee2mqtt/kitchen",
+			Payload: `{"battery":97,"humidity":48.52,"linkquality":102,"temperature":21.3,"voltage":2995,"last_seen":"2026-10-17T08:00:00+02:00","update":{"state":"idle"},"elapsed":null}`,
+			ExpectedLines: []string{
+				"telemetry,device=kitchen,field=battery,sensor=zigbee2mqtt,unit=% floatValue=97",
+				"telemetry,device=kitchen,field=humidity,sensor=zigbee2mqtt,unit=% floatValue=48.52",
+				"telemetry,device=kitchen,field=temperature,sensor=zigbee2mqtt,unit=°C floatValue=21.3",
+				"telemetry,device=kitchen,field=voltage,sensor=zigbee2mqtt,unit=mV floatValue=2995",
+				"telemetry,device=kitchen,field=last_seen,sensor=zigbee2mqtt stringValue=\"2026-10-17T08:00:00+02:00\"",
+				"telemetry,device=kitchen,field=update_state,sensor=zigbee2mqtt stringValue=\"idle\"",
+				"zigbee,device=kitchen linkQuality=102i",
+			},
+			ExpectedTimeStamp: time.Now(),
+		}, {
+			Topic:             "zigbee2mqtt/kitchen",
+			Payload:           `{}`,
+			ExpectedLines:     []string{},
+			ExpectedTimeStamp: time.Now(),
+			ExpectedError:     true,
+		}, {
+			Topic:             "zigbee2mqtt/kitchen",
+			Payload:           `online`,
+			ExpectedLines:     []string{},
+			ExpectedTimeStamp: time.Now(),
+			ExpectedError:     true,
+		},
+	}
+	testStimuliResponse(t, mockCtrl, mockConfig, mockTMConfig, zigbee2mqttHandler, sensorStimuli)
+
+	bridgeStimuli := TestStimuliResponse{
+		{
+			Topic: "zigbee2mqtt/bridge/devices",
+			Payload: `[{"friendly_name":"Coordinator","ieee_address":"0x00124b0023456789","type":"Coordinator","definition":null},` +
+				`{"friendly_name":"plug","ieee_address":"0x00158d0001a2b3c4","type":"Router","definition":{"model":"ZNCZ04LM","vendor":"Xiaomi","description":"Smart plug"}}]`,
+			ExpectedLines:     []string{},
+			ExpectedTimeStamp: time.Now(),
+		}, {
+			Topic:             "zigbee2mqtt/bridge/devices",
+			Payload:           `{"invalid":true}`,
+			ExpectedLines:     []string{},
+			ExpectedTimeStamp: time.Now(),
+			ExpectedError:     true,
+		},
+	}
+	testStimuliResponse(t, mockCtrl, mockConfig, mockBridgeTMConfig, zigbee2mqttHandler, bridgeStimuli)
+
+	knownStimuli := TestStimuliResponse{
+		{
+			Topic:   "zigbee2mqtt/plug",
+			Payload: `{"linkquality":255,"power":12.5,"energy":1.23,"state":"ON","child_lock":false}`,
+			ExpectedLines: []string{
+				"telemetry,device=plug,field=power,sensor=Xiaomi\\ ZNCZ04LM,unit=W floatValue=12.5",
+				"telemetry,device=plug,field=energy,sensor=Xiaomi\\ ZNCZ04LM,unit=kWh floatValue=1.23",
+				"telemetry,device=plug,field=state,sensor=Xiaomi\\ ZNCZ04LM stringValue=\"ON\"",
+				"telemetry,device=plug,field=child_lock,sensor=Xiaomi\\ ZNCZ04LM boolValue=false",
+				"zigbee,device=plug,ieeeAddress=0x00158d0001a2b3c4 linkQuality=255i",
+			},
+			ExpectedTimeStamp: time.Now(),
+		},
+	}
+	testStimuliResponse(t, mockCtrl, mockConfig, mockTMConfig, zigbee2mqttHandler, knownStimuli)
+
+	// friendly names may end with /devices, only the fixed bridge topic contains the device list
+	nestedStimuli := TestStimuliResponse{
+		{
+			Topic:   "zigbee2mqtt/kitchen/devices",
+			Payload: `{"temperature":21.3}`,
+			ExpectedLines: []string{
+				"telemetry,device=kitchen/devices,field=temperature,sensor=zigbee2mqtt,unit=°C floatValue=21.3",
+			},
+			ExpectedTimeStamp: time.Now(),
+		}, {
+			Topic:             "zigbee2mqtt/bridge/devices",
+			Payload:           `[{"friendly_name":"Coordinator","ieee_address":"0x00124b0023456789","type":"Coordinator","definition":null}]`,
+			ExpectedLines:     []string{},
+			ExpectedTimeStamp: time.Now(),
+			ExpectedError:     true,
+		},
+	}
+	testStimuliResponse(t, mockCtrl, mockConfig, mockNestedTMConfig, zigbee2mqttHandler, nestedStimuli)
+}
@@ -0,0 +1,36 @@
+package converter
+
+import "time"
+
+type zigbeeOutputMessage struct {
+	timeStamp   time.Time
+	device      string
+	ieeeAddress string
+	linkQuality int64
+}
+
+func (m zigbeeOutputMessage) Measurement() string {
+	return "zigbee"
+}
+
+func (m zigbeeOutputMessage) Tags() map[string]string {
+	ret := map[string]string{
+		"device": m.device,
+	}
+
+	if m.ieeeAddress != "" {
+		ret["ieeeAddress"] = m.ieeeAddress
+	}
+
+	return ret
+}
+
+func (m zigbeeOutputMessage) Fields() (ret map[string]interface{}) {
+	return map[string]interface{}{
+		"linkQuality": m.linkQuality,
+	}
+}
+
+func (m zigbeeOutputMessage) Time() time.Time {
+	return m.timeStamp
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"github.com/koestler/go-mqtt-to-influx/v2/config"
+	"log"
+	"os"
+)
+
+type ReplayDeadLettersCommand struct {
+	Converter     string   `long:"converter" required:"true" description:"Name of the converter used to handle the dead letters"`
+	Source        string   `long:"source" description:"Replay the dead letters of this converter instead of the ones of --converter"`
+	InfluxClients []string `long:"influx-client" description:"Write to this influx client instead of the InfluxClients of the converter; can be repeated"`
+	DryRun        bool     `long:"dry-run" description:"Print the line protocol instead of writing it and keep the dead letters"`
+	Keep          bool     `long:"keep" description:"Keep the successfully replayed dead letters in the local db"`
+}
+
+// runReplayDeadLetters handles the stored dead letters, oldest first, using the chosen converter;
+// successfully handled dead letters are deleted unless --keep or --dry-run is given
+func runReplayDeadLetters(cfg *config.Config, cmd ReplayDeadLettersCommand) int {
+	localDbInstance := runLocalDb(cfg)
+	defer localDbInstance.Shutdown()
+
+	if !localDbInstance.Enabled() {
+		log.Print("main: the LocalDb section must be configured to replay dead letters")
+		return ExitDueToConfig
+	}
+
+	source := cmd.Source
+	if source == "" {
+		source = cmd.Converter
+	}
+
+	letters, err := localDbInstance.DeadLetterList(source, 0)
+	if err != nil {
+		log.Printf("main: cannot list dead letters: %s", err)
+		return ExitDueToModuleStart
+	}
+
+	output := newReplayOutput(cfg, cmd.DryRun, os.Stdout, localDbInstance)
+	defer output.shutdown()
+
+	r, err := newReplayer(cfg, cmd.Converter, cmd.InfluxClients, output)
+	if err != nil {
+		log.Printf("main: cannot replay: %s", err)
+		return ExitDueToConfig
+	}
+
+	countReplayed, countFailed := 0, 0
+	for i := len(letters) - 1; i >= 0; i -= 1 {
+		letter := letters[i]
+		err := r.handle(letter.MqttClient, replayInput{
+			topic:      letter.Topic,
+			payload:    []byte(letter.Payload),
+			receivedAt: letter.Received,
+		})
+		if err != nil {
+			log.Printf("deadLetter[%d]: topic='%s' still fails: %s", letter.Id, letter.Topic, err)
+			countFailed += 1
+			continue
+		}
+
+		countReplayed += 1
+		if !cmd.DryRun && !cmd.Keep {
+			if err := localDbInstance.DeadLetterDelete(letter.Id); err != nil {
+				log.Printf("deadLetter[%d]: %s", letter.Id, err)
+			}
+		}
+	}
+
+	output.shutdown()
+
+	log.Printf("main: %d dead letters of converter='%s' replayed using converter='%s', %d failed",
+		countReplayed, source, cmd.Converter, countFailed,
+	)
+
+	return ExitSuccess
+}
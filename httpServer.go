@@ -2,12 +2,21 @@ package main
 
 import (
 	"github.com/koestler/go-mqtt-to-influx/v2/config"
+	"github.com/koestler/go-mqtt-to-influx/v2/converter"
 	"github.com/koestler/go-mqtt-to-influx/v2/httpServer"
+	LocalDb "github.com/koestler/go-mqtt-to-influx/v2/localDb"
+	"github.com/koestler/go-mqtt-to-influx/v2/metrics"
 	"github.com/koestler/go-mqtt-to-influx/v2/statistics"
 	"log"
 )
 
-func runHttpServer(cfg *config.Config, statisticsInstance statistics.Statistics) *httpServer.HttpServer {
+func runHttpServer(
+	cfg *config.Config,
+	localDbInstance LocalDb.LocalDb,
+	statisticsInstance statistics.Statistics,
+	metricsInstance *metrics.Metrics,
+	errorHistoryInstance *converter.ErrorHistory,
+) *httpServer.HttpServer {
 	httpCfg := cfg.HttpServer()
 
 	if !httpCfg.Enabled() {
@@ -21,7 +30,10 @@ func runHttpServer(cfg *config.Config, statisticsInstance statistics.Statistics)
 	return httpServer.Run(
 		httpCfg,
 		&httpServer.Environment{
-			Statistics: statisticsInstance,
+			Statistics:      statisticsInstance,
+			Metrics:         metricsInstance,
+			ConverterErrors: errorHistoryInstance,
+			DeadLetters:     localDbInstance,
 		},
 	)
 }
@@ -2,8 +2,11 @@ package httpServer
 
 import (
 	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
 	jsoniter "github.com/json-iterator/go"
 	"net/http"
+	"strconv"
 )
 
 var json = jsoniter.ConfigCompatibleWithStandardLibrary
@@ -12,6 +15,75 @@ func writeJsonHeaders(w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 }
 
+func HandleMetrics(env *Environment, w http.ResponseWriter, r *http.Request) Error {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if _, err := env.Metrics.WriteTo(w); err != nil {
+		return StatusError{500, err}
+	}
+	return nil
+}
+
+func HandleConverterErrors(env *Environment, w http.ResponseWriter, r *http.Request) Error {
+	return writeJson(w, env.ConverterErrors.GetAllStructless())
+}
+
+func HandleConverterErrorsByName(env *Environment, w http.ResponseWriter, r *http.Request) Error {
+	name := mux.Vars(r)["name"]
+	entries, ok := env.ConverterErrors.GetStructless(name)
+	if !ok {
+		return StatusError{404, fmt.Errorf("converter='%s' is unknown or has no error history", name)}
+	}
+
+	return writeJson(w, entries)
+}
+
+// HandleDeadLetters lists the stored dead letters, newest first;
+// the optional query parameters converter and limit filter the list
+func HandleDeadLetters(env *Environment, w http.ResponseWriter, r *http.Request) Error {
+	if !env.DeadLetters.Enabled() {
+		return StatusError{404, errors.New("local db is disabled")}
+	}
+
+	limit := 0
+	if l := r.URL.Query().Get("limit"); l != "" {
+		var err error
+		if limit, err = strconv.Atoi(l); err != nil || limit < 0 {
+			return StatusError{400, fmt.Errorf("invalid limit='%s'", l)}
+		}
+	}
+
+	letters, err := env.DeadLetters.DeadLetterListStructless(r.URL.Query().Get("converter"), limit)
+	if err != nil {
+		return StatusError{500, err}
+	}
+
+	return writeJson(w, letters)
+}
+
+// HandleDeadLetterPayload downloads the unmodified payload of the given dead letter
+func HandleDeadLetterPayload(env *Environment, w http.ResponseWriter, r *http.Request) Error {
+	if !env.DeadLetters.Enabled() {
+		return StatusError{404, errors.New("local db is disabled")}
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return StatusError{400, err}
+	}
+
+	payload, err := env.DeadLetters.DeadLetterPayload(id)
+	if err != nil {
+		return StatusError{404, err}
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"dead-letter-%d\"", id))
+	if _, err := w.Write(payload); err != nil {
+		return StatusError{500, err}
+	}
+	return nil
+}
+
 func HandleStatsCounts(env *Environment, w http.ResponseWriter, r *http.Request) Error {
 	if !env.Statistics.Enabled() {
 		// Statistics module not available -> return 404
@@ -19,10 +91,12 @@ func HandleStatsCounts(env *Environment, w http.ResponseWriter, r *http.Request)
 		return StatusError{404, err}
 	}
 
-	counts := env.Statistics.GetHierarchicalCountsStructless()
+	return writeJson(w, env.Statistics.GetHierarchicalCountsStructless())
+}
 
+func writeJson(w http.ResponseWriter, v interface{}) Error {
 	writeJsonHeaders(w)
-	b, err := json.MarshalIndent(counts, "", "    ")
+	b, err := json.MarshalIndent(v, "", "    ")
 	if err != nil {
 		return StatusError{500, err}
 	}
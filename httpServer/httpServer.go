@@ -26,6 +26,21 @@ type Statistics interface {
 	GetHierarchicalCountsStructless() interface{}
 }
 
+type Metrics interface {
+	WriteTo(w io.Writer) (n int64, err error)
+}
+
+type ConverterErrors interface {
+	GetStructless(converter string) (entries interface{}, ok bool)
+	GetAllStructless() interface{}
+}
+
+type DeadLetters interface {
+	Enabled() bool
+	DeadLetterListStructless(converter string, limit int) (interface{}, error)
+	DeadLetterPayload(id int) ([]byte, error)
+}
+
 func Run(config Config, env *Environment) (httpServer *HttpServer) {
 	var logger io.Writer
 	if config.LogRequests() {
@@ -64,7 +79,10 @@ func (s *HttpServer) Shutdown() {
 
 // Our application wide data containers
 type Environment struct {
-	Statistics Statistics
+	Statistics      Statistics
+	Metrics         Metrics
+	ConverterErrors ConverterErrors
+	DeadLetters     DeadLetters
 }
 
 // Error represents a handler error. It provides methods for a HTTP status
@@ -21,6 +21,31 @@ var httpRoutes = []HttpRoute{
 		"GET",
 		"/api/v1/statistics/counts",
 		HandleStatsCounts,
+	}, {
+		"ConverterErrors",
+		"GET",
+		"/api/v1/converters/errors",
+		HandleConverterErrors,
+	}, {
+		"ConverterErrorsByName",
+		"GET",
+		"/api/v1/converters/{name}/errors",
+		HandleConverterErrorsByName,
+	}, {
+		"DeadLetters",
+		"GET",
+		"/api/v1/dead-letters",
+		HandleDeadLetters,
+	}, {
+		"DeadLetterPayload",
+		"GET",
+		"/api/v1/dead-letters/{id:[0-9]+}/payload",
+		HandleDeadLetterPayload,
+	}, {
+		"Metrics",
+		"GET",
+		"/metrics",
+		HandleMetrics,
 	}, {
 		"expvar",
 		"GET",
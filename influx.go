@@ -1,9 +1,11 @@
 package main
 
 import (
+	"fmt"
 	"github.com/koestler/go-mqtt-to-influx/v2/config"
 	"github.com/koestler/go-mqtt-to-influx/v2/influxClient"
 	LocalDb "github.com/koestler/go-mqtt-to-influx/v2/localDb"
+	"github.com/koestler/go-mqtt-to-influx/v2/metrics"
 	"github.com/koestler/go-mqtt-to-influx/v2/statistics"
 	"log"
 )
@@ -12,35 +14,65 @@ func runInfluxClient(
 	cfg *config.Config,
 	localDbInstance LocalDb.LocalDb,
 	statisticsInstance statistics.Statistics,
+	metricsInstance *metrics.Metrics,
 	initiateShutdown chan<- error,
 ) (influxClientPoolInstance *influxClient.ClientPool) {
 	influxClientPoolInstance = influxClient.RunPool()
 
-	// convert []*config.InfluxAuxiliaryTags to []influxClient.AuxiliaryTag
-	auxiliaryTags := make([]influxClient.AuxiliaryTag, len(cfg.InfluxAuxiliaryTags()))
-	for i, t := range cfg.InfluxAuxiliaryTags() {
-		auxiliaryTags[i] = t
-	}
-
 	for _, influxClientConfig := range cfg.InfluxClients() {
-		if cfg.LogWorkerStart() {
-			log.Printf(
-				"influxClient[%s]: start: url='%s', len(token)=%d, org='%s', bucket='%s'",
-				influxClientConfig.Name(),
-				influxClientConfig.Url(),
-				len(influxClientConfig.Token()),
-				influxClientConfig.Org(),
-				influxClientConfig.Bucket(),
-			)
+		client, err := startInfluxClient(cfg, influxClientConfig, localDbInstance, statisticsInstance, metricsInstance)
+		if err != nil {
+			initiateShutdown <- err
+			continue
 		}
 
-		client := influxClient.RunClient(influxClientConfig, auxiliaryTags, localDbInstance, statisticsInstance)
-
 		influxClientPoolInstance.AddClient(client)
-		if cfg.LogWorkerStart() {
-			log.Printf("influxClient[%s]: started", influxClientConfig.Name())
-		}
 	}
 
 	return
 }
+
+func startInfluxClient(
+	cfg *config.Config,
+	influxClientConfig config.InfluxClientConfig,
+	localDbInstance LocalDb.LocalDb,
+	statisticsInstance statistics.Statistics,
+	metricsInstance *metrics.Metrics,
+) (*influxClient.Client, error) {
+	if cfg.LogWorkerStart() {
+		log.Printf(
+			"influxClient[%s]: start: backend='%s', url='%s', len(token)=%d, org='%s', bucket='%s', database='%s', path='%s'",
+			influxClientConfig.Name(),
+			influxClientConfig.Backend(),
+			influxClientConfig.Url(),
+			len(influxClientConfig.Token()),
+			influxClientConfig.Org(),
+			influxClientConfig.Bucket(),
+			influxClientConfig.Database(),
+			influxClientConfig.Path(),
+		)
+	}
+
+	client, err := influxClient.RunClient(
+		influxClientConfig, influxAuxiliaryTags(cfg), localDbInstance, statisticsInstance, metricsInstance,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("influxClient[%s]: cannot start: %s", influxClientConfig.Name(), err)
+	}
+
+	metricsInstance.AddInfluxClient(influxClientConfig.Name())
+	if cfg.LogWorkerStart() {
+		log.Printf("influxClient[%s]: started", influxClientConfig.Name())
+	}
+
+	return client, nil
+}
+
+// influxAuxiliaryTags converts []*config.InfluxAuxiliaryTags to []influxClient.AuxiliaryTag
+func influxAuxiliaryTags(cfg *config.Config) []influxClient.AuxiliaryTag {
+	auxiliaryTags := make([]influxClient.AuxiliaryTag, len(cfg.InfluxAuxiliaryTags()))
+	for i, t := range cfg.InfluxAuxiliaryTags() {
+		auxiliaryTags[i] = t
+	}
+	return auxiliaryTags
+}
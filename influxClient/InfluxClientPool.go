@@ -4,6 +4,7 @@ import (
 	"github.com/influxdata/influxdb-client-go/v2"
 	influxdb2Write "github.com/influxdata/influxdb-client-go/v2/api/write"
 	"sync"
+	"time"
 )
 
 type ClientPool struct {
@@ -43,6 +44,13 @@ func (p *ClientPool) RemoveClient(client *Client) {
 	delete(p.clients, client.Name())
 }
 
+func (p *ClientPool) GetClient(name string) (client *Client, ok bool) {
+	p.clientsMutex.RLock()
+	defer p.clientsMutex.RUnlock()
+	client, ok = p.clients[name]
+	return
+}
+
 func (p *ClientPool) getReceiverClients(receiversNames []string) (receivers []*Client) {
 	p.clientsMutex.RLock()
 	defer p.clientsMutex.RUnlock()
@@ -78,6 +86,26 @@ func ToInfluxPoint(point Point) *influxdb2Write.Point {
 	return influxdb2.NewPoint(point.Measurement(), point.Tags(), point.Fields(), point.Time())
 }
 
+// toInfluxPointWithAuxiliaryTags converts the given point and adds the matching auxiliary tags
+func toInfluxPointWithAuxiliaryTags(point Point, auxiliaryTags []AuxiliaryTag) *influxdb2Write.Point {
+	p := ToInfluxPoint(point)
+
+	for _, at := range auxiliaryTags {
+		if value, ok := point.Tags()[at.Tag()]; ok && at.MatchString(value) {
+			for key, value := range at.TagValues() {
+				p.AddTag(key, value)
+			}
+		}
+	}
+
+	return p
+}
+
+// ToLineProtocol adds the matching auxiliary tags to the given point and returns it as a line including the newline
+func ToLineProtocol(point Point, auxiliaryTags []AuxiliaryTag, precision time.Duration) string {
+	return influxdb2Write.PointToLineProtocol(toInfluxPointWithAuxiliaryTags(point, auxiliaryTags), precision)
+}
+
 func (p *ClientPool) WritePoint(point Point, receiverNames []string) {
 	for _, receiver := range p.getReceiverClients(receiverNames) {
 		receiver.WritePoint(point)
@@ -0,0 +1,36 @@
+package influxClient
+
+import (
+	"context"
+	"fmt"
+	influxdb2Write "github.com/influxdata/influxdb-client-go/v2/api/write"
+)
+
+// Backend is implemented by all outputs an influx client can write to.
+// Points are handed over as batches including the auxiliary tags; only the localDb backlog stores them
+// in the influxDb line protocol, they are parsed again when retried.
+type Backend interface {
+	// WritePoints synchronously writes the given points and returns an error when they must be retried later
+	WritePoints(ctx context.Context, points []*influxdb2Write.Point) error
+	Close()
+}
+
+type BackendFactory func(config Config) (Backend, error)
+
+var backendImplementations = make(map[string]BackendFactory)
+
+func registerBackend(name string, f BackendFactory) {
+	if _, ok := backendImplementations[name]; ok {
+		panic(fmt.Sprintf("influxClient: backend='%s' registered twice", name))
+	}
+
+	backendImplementations[name] = f
+}
+
+func getBackendFactory(name string) (f BackendFactory, err error) {
+	f, ok := backendImplementations[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown backend='%s'", name)
+	}
+	return f, nil
+}
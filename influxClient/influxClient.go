@@ -2,29 +2,33 @@ package influxClient
 
 import (
 	"context"
-	"github.com/influxdata/influxdb-client-go/v2"
-	influxdb2Api "github.com/influxdata/influxdb-client-go/v2/api"
-	influxdbHttp2 "github.com/influxdata/influxdb-client-go/v2/api/http"
+	"fmt"
 	influxdb2Write "github.com/influxdata/influxdb-client-go/v2/api/write"
+	protocol "github.com/influxdata/line-protocol"
 	"log"
 	"strings"
+	"sync"
 	"time"
 )
 
 type Client struct {
-	config           Config
-	client           influxdb2.Client
-	writeApi         influxdb2Api.WriteAPI
-	blockingWriteApi influxdb2Api.WriteAPIBlocking
+	config  Config
+	backend Backend
 
 	auxiliaryTags []AuxiliaryTag
 	localDb       LocalDb
 	statistics    Statistics
+	metrics       Metrics
 
 	ctx    context.Context
 	cancel context.CancelFunc
 
-	backlogChan chan string
+	pointsMutex sync.Mutex
+	points      []*influxdb2Write.Point
+	flushChan   chan struct{}
+
+	// used instead of the localDb backlog when the localDb is disabled
+	retryQueue [][]*influxdb2Write.Point
 
 	shutdown chan struct{}
 	closed   chan struct{}
@@ -32,10 +36,16 @@ type Client struct {
 
 type Config interface {
 	Name() string
+	Backend() string
 	Url() string
 	Token() string
 	Org() string
 	Bucket() string
+	User() string
+	Password() string
+	Database() string
+	RetentionPolicy() string
+	Path() string
 	WriteInterval() time.Duration
 	RetryInterval() time.Duration
 	AggregateInterval() time.Duration
@@ -52,6 +62,7 @@ type AuxiliaryTag interface {
 	TagValues() map[string]string
 }
 
+// Point is the output of a converter; it is satisfied by converter.Output
 type Point interface {
 	Measurement() string
 	Tags() map[string]string
@@ -73,87 +84,62 @@ type Statistics interface {
 	IncrementOne(module, name, field string)
 }
 
-func RunClient(config Config, auxiliaryTags []AuxiliaryTag, localDb LocalDb, statistics Statistics) *Client {
-	// Create a new HTTPClient
-	opts := influxdb2.DefaultOptions().SetUseGZip(true)
-	opts = opts.SetFlushInterval(uint(config.WriteInterval().Milliseconds()))
-	opts = opts.SetRetryInterval(uint(config.RetryInterval().Milliseconds()))
-	opts = opts.SetBatchSize(config.BatchSize())
-	opts = opts.SetRetryBufferLimit(config.RetryQueueLimit() * config.BatchSize())
-	opts = opts.SetPrecision(config.TimePrecision())
-	opts = opts.SetHTTPRequestTimeout(uint(config.ConnectTimeout().Seconds()))
-	if config.LogDebug() {
-		opts = opts.SetLogLevel(3)
-	} else {
-		opts = opts.SetLogLevel(0)
-	}
-	dbClient := influxdb2.NewClientWithOptions(
-		config.Url(),
-		config.Token(),
-		opts,
-	)
-
-	backlogChan := make(chan string, 8)
-
-	// create asynchronous, auto-retry write api instance
-	writeApi := dbClient.WriteAPI(config.Org(), config.Bucket())
-	if localDb.Enabled() && config.RetryInterval() > 0 {
-		writeApi.SetWriteFailedCallback(failedCallbackHandler(config.Name(), backlogChan))
-	} else {
-		writeApi.SetWriteFailedCallback(func(batch string, error influxdbHttp2.Error, retryAttempts uint) bool {
-			// log and retry until buffer is full
-			log.Printf("influxClient[%s]: write failed, retryAttempts=%d", config.Name(), retryAttempts)
-			return true
-		})
+type Metrics interface {
+	InfluxPointsWritten(client string, numbPoints int)
+	InfluxPointsFailed(client string, numbPoints int)
+	InfluxPointsRetried(client string, numbPoints int)
+}
+
+func RunClient(
+	config Config,
+	auxiliaryTags []AuxiliaryTag,
+	localDb LocalDb,
+	statistics Statistics,
+	metrics Metrics,
+) (*Client, error) {
+	factory, err := getBackendFactory(config.Backend())
+	if err != nil {
+		return nil, err
 	}
 
-	// create synchronous api to write the backlog
-	blockingWriteApi := dbClient.WriteAPIBlocking(config.Org(), config.Bucket())
+	backend, err := factory(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create backend='%s': %s", config.Backend(), err)
+	}
 
 	// create main context
 	ctx, cancel := context.WithCancel(context.Background())
 
-	// ping the api
-	if ok, err := dbClient.Ping(ctx); ok {
-		log.Printf("influxClient[%s]: ping successful", config.Name())
-	} else {
-		log.Printf("influxClient[%s]: ping failed: %s", config.Name(), err)
-	}
-
 	c := Client{
-		config:           config,
-		client:           dbClient,
-		writeApi:         writeApi,
-		blockingWriteApi: blockingWriteApi,
+		config:  config,
+		backend: backend,
 
 		auxiliaryTags: auxiliaryTags,
 		localDb:       localDb,
 		statistics:    statistics,
+		metrics:       metrics,
 
 		ctx:    ctx,
 		cancel: cancel,
 
-		backlogChan: backlogChan,
-		shutdown:    make(chan struct{}),
-		closed:      make(chan struct{}),
+		flushChan: make(chan struct{}, 1),
+		shutdown:  make(chan struct{}),
+		closed:    make(chan struct{}),
 	}
 
 	go c.worker()
 
 	// create client object
-	return &c
+	return &c, nil
 }
 
-func (ic Client) Shutdown() {
-	if ic.localDb.Enabled() {
-		// send remaining points
-		close(ic.shutdown)
-		// wait for retryWorker to shut down
-		<-ic.closed
-	}
+func (ic *Client) Shutdown() {
+	// send remaining points
+	close(ic.shutdown)
+	// wait for worker to shut down
+	<-ic.closed
 
-	ic.writeApi.Flush()
-	ic.client.Close()
+	ic.backend.Close()
 
 	// cancel main context
 	ic.cancel()
@@ -161,45 +147,98 @@ func (ic Client) Shutdown() {
 	log.Printf("influxClient[%s]: closed", ic.Name())
 }
 
-func (ic Client) Name() string {
+func (ic *Client) Name() string {
 	return ic.config.Name()
 }
 
-func (ic Client) WritePoint(point Point) {
-	p := ToInfluxPoint(point)
+func (ic *Client) WritePoint(point Point) {
+	p := toInfluxPointWithAuxiliaryTags(point, ic.auxiliaryTags)
 
-	// add auxiliary tags to influx point
-	for _, at := range ic.auxiliaryTags {
-		if value, ok := point.Tags()[at.Tag()]; ok && at.MatchString(value) {
-			for key, value := range at.TagValues() {
-				p.AddTag(key, value)
-			}
-		}
+	if ic.config.LogDebug() {
+		log.Printf("influxClient[%s]: write: %s", ic.Name(),
+			strings.TrimSuffix(influxdb2Write.PointToLineProtocol(p, ic.config.TimePrecision()), "\n"),
+		)
+	}
+
+	ic.pointsMutex.Lock()
+	ic.points = append(ic.points, p)
+	var overflow []*influxdb2Write.Point
+	if len(ic.points) > ic.pendingLimit() {
+		// the backend does not keep up; remove the oldest batch to limit memory usage
+		n := int(ic.config.BatchSize())
+		overflow = ic.points[:n]
+		ic.points = ic.points[n:]
 	}
+	full := uint(len(ic.points)) >= ic.config.BatchSize() || ic.config.WriteInterval() <= 0
+	ic.pointsMutex.Unlock()
 
-	ic.writeApi.WritePoint(p)
+	if overflow != nil {
+		ic.discardPending(overflow)
+	}
+
+	if full {
+		ic.triggerFlush()
+	}
 
 	// statistics
 	if ic.statistics.Enabled() {
-		line := influxdb2Write.PointToLineProtocol(p, time.Second)
-		field := strings.Fields(line)[0]
+		field := strings.Fields(influxdb2Write.PointToLineProtocol(p, ic.config.TimePrecision()))[0]
 		ic.statistics.IncrementOne("influx", ic.Name(), field)
 	}
 }
 
-func (ic Client) worker() {
+// pendingLimit is the maximum number of points waiting to be written: BatchSize × RetryQueueLimit but at least one batch
+func (ic *Client) pendingLimit() int {
+	retryQueueLimit := ic.config.RetryQueueLimit()
+	if retryQueueLimit < 1 {
+		retryQueueLimit = 1
+	}
+	return int(ic.config.BatchSize() * retryQueueLimit)
+}
+
+// discardPending moves points that cannot be kept in memory to the backlog when enabled, otherwise they are dropped
+func (ic *Client) discardPending(points []*influxdb2Write.Point) {
+	ic.metrics.InfluxPointsFailed(ic.Name(), len(points))
+
+	if ic.backlogEnabled() {
+		if err := ic.localDb.InfluxBacklogAdd(ic.Name(), pointsToLineProtocol(points, ic.config.TimePrecision())); err != nil {
+			log.Printf("influxClient[%s]: too many pending points, add to backlog failed: %s", ic.Name(), err)
+		} else {
+			log.Printf("influxClient[%s]: too many pending points, added %d lines to backlog", ic.Name(), len(points))
+		}
+		return
+	}
+
+	log.Printf("influxClient[%s]: too many pending points, discard %d points", ic.Name(), len(points))
+}
+
+func (ic *Client) triggerFlush() {
+	select {
+	case ic.flushChan <- struct{}{}:
+	default:
+	}
+}
+
+func (ic *Client) backlogEnabled() bool {
+	return ic.localDb.Enabled() && ic.config.RetryInterval() > 0
+}
+
+func (ic *Client) worker() {
 	defer close(ic.closed)
 
-	aggregateTicker := time.NewTicker(ic.config.AggregateInterval())
+	writeInterval := ic.config.WriteInterval()
+	if writeInterval <= 0 {
+		// points are flushed immediately; the ticker is only a fallback
+		writeInterval = time.Second
+	}
+	writeTicker := time.NewTicker(writeInterval)
+	defer writeTicker.Stop()
+
+	aggregateTicker := newTicker(ic.config.AggregateInterval(), ic.backlogEnabled())
 	defer aggregateTicker.Stop()
-	retryTicker := time.NewTicker(ic.config.RetryInterval())
+	retryTicker := newTicker(ic.config.RetryInterval(), true)
 	defer retryTicker.Stop()
 
-	if !ic.localDb.Enabled() || ic.config.RetryInterval() <= 0 {
-		aggregateTicker.Stop()
-		retryTicker.Stop()
-	}
-
 	retryChan := make(chan struct{}, 1)
 	triggerRetryHandler := func() {
 		select {
@@ -211,11 +250,12 @@ func (ic Client) worker() {
 	for {
 		select {
 		case <-ic.shutdown:
+			ic.flush()
 			return // shutdown
-		case batch := <-ic.backlogChan:
-			if err := ic.localDb.InfluxBacklogAdd(ic.Name(), batch); err != nil {
-				log.Printf("influxClient[%s]: add failed: %s", ic.Name(), err)
-			}
+		case <-ic.flushChan:
+			ic.flush()
+		case <-writeTicker.C:
+			ic.flush()
 		case <-aggregateTicker.C:
 			if err := ic.localDb.InfluxAggregateBacklog(ic.Name(), ic.config.BatchSize()); err != nil {
 				log.Printf("influxClient[%s]: aggregate failed: %s", ic.Name(), err)
@@ -230,23 +270,86 @@ func (ic Client) worker() {
 	}
 }
 
-func failedCallbackHandler(client string, retryBatchChan chan string) func(batch string, error influxdbHttp2.Error, retryAttempts uint) (retry bool) {
-	return func(batch string, error influxdbHttp2.Error, retryAttempts uint) bool {
+// newTicker returns a ticker that never fires when it is not enabled or the interval is not positive
+func newTicker(interval time.Duration, enabled bool) *time.Ticker {
+	if !enabled || interval <= 0 {
+		t := time.NewTicker(time.Hour)
+		t.Stop()
+		return t
+	}
+	return time.NewTicker(interval)
+}
+
+// flush writes all pending points in batches of at most BatchSize points
+func (ic *Client) flush() {
+	ic.pointsMutex.Lock()
+	points := ic.points
+	ic.points = nil
+	ic.pointsMutex.Unlock()
+
+	batchSize := int(ic.config.BatchSize())
+	for len(points) > 0 {
+		n := len(points)
+		if n > batchSize {
+			n = batchSize
+		}
+		ic.writeBatch(points[:n])
+		points = points[n:]
+	}
+}
+
+func (ic *Client) writeBatch(points []*influxdb2Write.Point) {
+	if err := ic.backendWrite(points); err == nil {
+		ic.metrics.InfluxPointsWritten(ic.Name(), len(points))
+		return
+	}
+	ic.metrics.InfluxPointsFailed(ic.Name(), len(points))
+
+	if ic.backlogEnabled() {
 		// write to backlog
-		select {
-		case retryBatchChan <- batch:
-			log.Printf("influxClient[%s]: write failed, added %d lines to backlog",
-				client, strings.Count(batch, "\n"),
-			)
-			return false
-		default:
-			log.Printf("influxClient[%s]: write failed, backlog chan full, keep retrying", client)
-			return true
+		if err := ic.localDb.InfluxBacklogAdd(ic.Name(), pointsToLineProtocol(points, ic.config.TimePrecision())); err != nil {
+			log.Printf("influxClient[%s]: write failed, add to backlog failed: %s", ic.Name(), err)
+		} else {
+			log.Printf("influxClient[%s]: write failed, added %d lines to backlog", ic.Name(), len(points))
 		}
+		return
+	}
+
+	if ic.config.RetryInterval() <= 0 || ic.config.RetryQueueLimit() < 1 {
+		log.Printf("influxClient[%s]: write failed, discard %d points", ic.Name(), len(points))
+		return
+	}
+
+	// keep in memory until retry queue is full; then discard the oldest batch
+	ic.retryQueue = append(ic.retryQueue, points)
+	if uint(len(ic.retryQueue)) > ic.config.RetryQueueLimit() {
+		log.Printf("influxClient[%s]: write failed, retry queue full, discard %d points",
+			ic.Name(), len(ic.retryQueue[0]),
+		)
+		ic.retryQueue = ic.retryQueue[1:]
+	} else {
+		log.Printf("influxClient[%s]: write failed, added %d points to retry queue", ic.Name(), len(points))
+	}
+}
+
+func (ic *Client) backendWrite(points []*influxdb2Write.Point) error {
+	ctx, cancel := context.WithTimeout(ic.ctx, ic.config.ConnectTimeout())
+	defer cancel()
+
+	if err := ic.backend.WritePoints(ctx, points); err != nil {
+		log.Printf("influxClient[%s]: error while writing batch, err=%s",
+			ic.Name(), strings.ReplaceAll(strings.ReplaceAll(err.Error(), "\r", ""), "\n", ""),
+		)
+		return err
 	}
+	return nil
 }
 
-func (ic Client) retryHandler() (triggerAgain bool) {
+func (ic *Client) retryHandler() (triggerAgain bool) {
+	if !ic.backlogEnabled() {
+		return ic.retryQueueHandler()
+	}
+
 	// while there is something on the backlog, send it synchronously and remove it on success
 	if numbBatches, numbLines, err := ic.localDb.InfluxBacklogSize(ic.Name()); err != nil {
 		log.Printf("influxClient[%s]: retryHandler: cannot access backlog: err=%s", ic.Name(), err)
@@ -265,16 +368,17 @@ func (ic Client) retryHandler() (triggerAgain bool) {
 		return false
 	}
 
-	// try to write to influxdb synchronously
-	if err = ic.blockingWriteApi.WriteRecord(ic.ctx, batch); err != nil {
-		log.Printf("influxClient[%s]: retryHandler: error while writing batch, err=%s",
-			ic.Name(), strings.ReplaceAll(strings.ReplaceAll(err.Error(), "\r", ""), "\n", ""),
-		)
-		return false
+	// try to write to the backend synchronously
+	points := ic.parseBacklogBatch(batch)
+	if len(points) > 0 {
+		if err = ic.backendWrite(points); err != nil {
+			return false
+		}
 	}
 
 	// batch written to db, delete it
-	log.Printf("influxClient[%s]: retryHandler: batch written to influxdb, id=%d", ic.Name(), id)
+	ic.metrics.InfluxPointsRetried(ic.Name(), len(points))
+	log.Printf("influxClient[%s]: retryHandler: batch written, id=%d", ic.Name(), id)
 	if err = ic.localDb.InfluxBacklogDelete(id); err != nil {
 		log.Printf("influxClient[%s]: retryHandler: cannot remove entry from backlog, id=%d, err=%s", ic.Name(), id, err)
 		return false
@@ -283,3 +387,59 @@ func (ic Client) retryHandler() (triggerAgain bool) {
 	// successfully send some points and wrote that to the db, immediately send next batch
 	return true
 }
+
+func (ic *Client) retryQueueHandler() (triggerAgain bool) {
+	if len(ic.retryQueue) < 1 {
+		return false
+	}
+
+	points := ic.retryQueue[0]
+	if err := ic.backendWrite(points); err != nil {
+		return false
+	}
+
+	ic.metrics.InfluxPointsRetried(ic.Name(), len(points))
+	log.Printf("influxClient[%s]: retryHandler: %d points from retry queue written", ic.Name(), len(points))
+	ic.retryQueue = ic.retryQueue[1:]
+	return true
+}
+
+// parseBacklogBatch parses the lines stored in the backlog; invalid lines are logged and skipped
+func (ic *Client) parseBacklogBatch(batch string) (points []*influxdb2Write.Point) {
+	handler := protocol.NewMetricHandler()
+	handler.SetTimePrecision(ic.config.TimePrecision())
+	parser := protocol.NewParser(handler)
+
+	for _, line := range strings.Split(batch, "\n") {
+		if len(strings.TrimSpace(line)) < 1 {
+			continue
+		}
+
+		metrics, err := parser.Parse([]byte(line))
+		if err != nil {
+			log.Printf("influxClient[%s]: retryHandler: skip invalid line='%s': %s", ic.Name(), line, err)
+			continue
+		}
+
+		for _, m := range metrics {
+			p := influxdb2Write.NewPointWithMeasurement(m.Name()).SetTime(m.Time())
+			for _, t := range m.TagList() {
+				p.AddTag(t.Key, t.Value)
+			}
+			for _, f := range m.FieldList() {
+				p.AddField(f.Key, f.Value)
+			}
+			points = append(points, p)
+		}
+	}
+	return
+}
+
+// pointsToLineProtocol returns the given points as lines including the newlines, e.g. to be stored in the backlog
+func pointsToLineProtocol(points []*influxdb2Write.Point, precision time.Duration) string {
+	var sb strings.Builder
+	for _, p := range points {
+		sb.WriteString(influxdb2Write.PointToLineProtocol(p, precision))
+	}
+	return sb.String()
+}
@@ -0,0 +1,93 @@
+package influxClient
+
+import (
+	"testing"
+	"time"
+)
+
+type pendingTestConfig struct {
+	Config
+}
+
+func (c pendingTestConfig) Name() string                 { return "test" }
+func (c pendingTestConfig) BatchSize() uint              { return 2 }
+func (c pendingTestConfig) RetryQueueLimit() uint        { return 3 }
+func (c pendingTestConfig) WriteInterval() time.Duration { return time.Second }
+func (c pendingTestConfig) RetryInterval() time.Duration { return time.Minute }
+func (c pendingTestConfig) TimePrecision() time.Duration { return time.Second }
+func (c pendingTestConfig) LogDebug() bool               { return false }
+
+type pendingTestLocalDb struct {
+	LocalDb
+}
+
+func (d pendingTestLocalDb) Enabled() bool { return false }
+
+type pendingTestStatistics struct{}
+
+func (s pendingTestStatistics) Enabled() bool                           { return false }
+func (s pendingTestStatistics) IncrementOne(module, name, field string) {}
+
+type pendingTestMetrics struct {
+	failed int
+}
+
+func (m *pendingTestMetrics) InfluxPointsWritten(client string, numbPoints int) {}
+func (m *pendingTestMetrics) InfluxPointsFailed(client string, numbPoints int) {
+	m.failed += numbPoints
+}
+func (m *pendingTestMetrics) InfluxPointsRetried(client string, numbPoints int) {}
+
+type pendingTestPoint struct {
+	value int
+}
+
+func (p pendingTestPoint) Measurement() string { return "test" }
+func (p pendingTestPoint) Tags() map[string]string {
+	return map[string]string{"device": "foo"}
+}
+func (p pendingTestPoint) Fields() map[string]interface{} {
+	return map[string]interface{}{"value": p.value}
+}
+func (p pendingTestPoint) Time() time.Time { return time.Unix(1661461942, 0) }
+
+func TestWritePointLimitsPendingLines(t *testing.T) {
+	metrics := &pendingTestMetrics{}
+	ic := &Client{
+		config:     pendingTestConfig{},
+		localDb:    pendingTestLocalDb{},
+		statistics: pendingTestStatistics{},
+		metrics:    metrics,
+		flushChan:  make(chan struct{}, 1),
+	}
+
+	// no worker is running, hence nothing is written and the lines pile up
+	for i := 0; i < 10; i++ {
+		ic.WritePoint(pendingTestPoint{value: i})
+	}
+
+	if expected := 6; len(ic.points) != expected {
+		t.Errorf("expected %d pending points, got %d", expected, len(ic.points))
+	}
+	if expected := 4; metrics.failed != expected {
+		t.Errorf("expected %d failed points, got %d", expected, metrics.failed)
+	}
+	if line, expected := pointsToLineProtocol(ic.points[:1], time.Second), "test,device=foo value=4i 1661461942\n"; line != expected {
+		t.Errorf("expected the oldest points to be discarded, first pending point is %q", line)
+	}
+}
+
+func TestParseBacklogBatch(t *testing.T) {
+	ic := &Client{config: pendingTestConfig{}}
+
+	batch := "test,device=foo value=4i,text=\"a b\" 1661461942\n" +
+		"invalid line\n" +
+		"\n" +
+		"test,device=bar value=1.5 1661461943\n"
+
+	points := ic.parseBacklogBatch(batch)
+	if expected := "test,device=foo value=4i,text=\"a b\" 1661461942\n" +
+		"test,device=bar value=1.5 1661461943\n"; pointsToLineProtocol(points, time.Second) != expected {
+		t.Errorf("expected\n%s\nbut got\n%s", expected, pointsToLineProtocol(points, time.Second))
+	}
+}
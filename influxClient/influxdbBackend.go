@@ -0,0 +1,71 @@
+package influxClient
+
+import (
+	"context"
+	"github.com/influxdata/influxdb-client-go/v2"
+	influxdb2Api "github.com/influxdata/influxdb-client-go/v2/api"
+	influxdb2Write "github.com/influxdata/influxdb-client-go/v2/api/write"
+	"log"
+)
+
+func init() {
+	registerBackend("influxdb2", func(config Config) (Backend, error) {
+		return newInfluxdbBackend(config, config.Token(), config.Org(), config.Bucket()), nil
+	})
+
+	// InfluxDB 1.8+ offers a compatibility api for the v2 client;
+	// the token is given as user:password and the bucket as database/retention-policy
+	registerBackend("influxdb1", func(config Config) (Backend, error) {
+		token := ""
+		if len(config.User()) > 0 {
+			token = config.User() + ":" + config.Password()
+		}
+		bucket := config.Database()
+		if rp := config.RetentionPolicy(); len(rp) > 0 {
+			bucket += "/" + rp
+		}
+		return newInfluxdbBackend(config, token, "", bucket), nil
+	})
+}
+
+type influxdbBackend struct {
+	client           influxdb2.Client
+	blockingWriteApi influxdb2Api.WriteAPIBlocking
+}
+
+func newInfluxdbBackend(config Config, token, org, bucket string) *influxdbBackend {
+	// Create a new HTTPClient
+	opts := influxdb2.DefaultOptions().SetUseGZip(true)
+	opts = opts.SetPrecision(config.TimePrecision())
+	opts = opts.SetHTTPRequestTimeout(uint(config.ConnectTimeout().Seconds()))
+	if config.LogDebug() {
+		opts = opts.SetLogLevel(3)
+	} else {
+		opts = opts.SetLogLevel(0)
+	}
+	dbClient := influxdb2.NewClientWithOptions(
+		config.Url(),
+		token,
+		opts,
+	)
+
+	// ping the api
+	if ok, err := dbClient.Ping(context.Background()); ok {
+		log.Printf("influxClient[%s]: ping successful", config.Name())
+	} else {
+		log.Printf("influxClient[%s]: ping failed: %s", config.Name(), err)
+	}
+
+	return &influxdbBackend{
+		client:           dbClient,
+		blockingWriteApi: dbClient.WriteAPIBlocking(org, bucket),
+	}
+}
+
+func (b *influxdbBackend) WritePoints(ctx context.Context, points []*influxdb2Write.Point) error {
+	return b.blockingWriteApi.WritePoint(ctx, points...)
+}
+
+func (b *influxdbBackend) Close() {
+	b.client.Close()
+}
@@ -0,0 +1,56 @@
+package influxClient
+
+import (
+	"context"
+	influxdb2Write "github.com/influxdata/influxdb-client-go/v2/api/write"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+func init() {
+	registerBackend("line-protocol", newLineProtocolBackend)
+}
+
+// lineProtocolBackend appends all points in the line protocol to a file or writes them to stdout when no path is set
+type lineProtocolBackend struct {
+	precision time.Duration
+
+	mutex  sync.Mutex
+	writer io.Writer
+	file   *os.File
+}
+
+func newLineProtocolBackend(config Config) (Backend, error) {
+	if len(config.Path()) < 1 {
+		return &lineProtocolBackend{precision: config.TimePrecision(), writer: os.Stdout}, nil
+	}
+
+	file, err := os.OpenFile(config.Path(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lineProtocolBackend{precision: config.TimePrecision(), writer: file, file: file}, nil
+}
+
+func (b *lineProtocolBackend) WritePoints(ctx context.Context, points []*influxdb2Write.Point) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	_, err := io.WriteString(b.writer, pointsToLineProtocol(points, b.precision))
+	return err
+}
+
+func (b *lineProtocolBackend) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.file != nil {
+		if err := b.file.Close(); err != nil {
+			log.Printf("influxClient: cannot close file='%s': %s", b.file.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package influxClient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/golang/snappy"
+	influxdb2Write "github.com/influxdata/influxdb-client-go/v2/api/write"
+	"google.golang.org/protobuf/encoding/protowire"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+func init() {
+	registerBackend("prometheus-remote-write", newPrometheusRemoteWriteBackend)
+}
+
+// prometheusRemoteWriteBackend sends points to a Prometheus remote write endpoint (e.g. Prometheus, Mimir,
+// VictoriaMetrics). Each numeric or boolean field becomes a sample of the metric <measurement>_<field> labeled
+// with the tags of the point. String fields are not supported by Prometheus and are skipped.
+type prometheusRemoteWriteBackend struct {
+	config Config
+	client *http.Client
+}
+
+func newPrometheusRemoteWriteBackend(config Config) (Backend, error) {
+	return &prometheusRemoteWriteBackend{
+		config: config,
+		client: &http.Client{},
+	}, nil
+}
+
+type remoteWriteLabel struct {
+	name, value string
+}
+
+type remoteWriteSample struct {
+	value     float64
+	timestamp int64 // in milliseconds
+}
+
+type remoteWriteSeries struct {
+	labels  []remoteWriteLabel
+	samples []remoteWriteSample
+}
+
+func (b *prometheusRemoteWriteBackend) WritePoints(ctx context.Context, points []*influxdb2Write.Point) error {
+	series := pointsToRemoteWriteSeries(points)
+	if len(series) < 1 {
+		return nil
+	}
+
+	body := snappy.Encode(nil, encodeRemoteWriteRequest(series))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.config.Url(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	req.Header.Set("User-Agent", "go-mqtt-to-influx")
+	if len(b.config.User()) > 0 {
+		req.SetBasicAuth(b.config.User(), b.config.Password())
+	} else if len(b.config.Token()) > 0 {
+		req.Header.Set("Authorization", "Bearer "+b.config.Token())
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("unexpected response: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
+
+func (b *prometheusRemoteWriteBackend) Close() {
+	b.client.CloseIdleConnections()
+}
+
+// pointsToRemoteWriteSeries groups the numeric fields of the given points into series
+func pointsToRemoteWriteSeries(points []*influxdb2Write.Point) (series []remoteWriteSeries) {
+	seriesIndex := make(map[string]int)
+
+	for _, p := range points {
+		labels := make([]remoteWriteLabel, 0, len(p.TagList())+1)
+		for _, t := range p.TagList() {
+			labels = append(labels, remoteWriteLabel{name: prometheusLabelName(t.Key), value: t.Value})
+		}
+
+		for _, f := range p.FieldList() {
+			value, ok := prometheusValue(f.Value)
+			if !ok {
+				continue
+			}
+
+			l := append([]remoteWriteLabel{
+				{name: "__name__", value: prometheusMetricName(p.Name() + "_" + f.Key)},
+			}, labels...)
+			sort.Slice(l, func(i, j int) bool { return l[i].name < l[j].name })
+
+			key := remoteWriteSeriesKey(l)
+			idx, ok := seriesIndex[key]
+			if !ok {
+				idx = len(series)
+				seriesIndex[key] = idx
+				series = append(series, remoteWriteSeries{labels: l})
+			}
+			series[idx].samples = append(series[idx].samples, remoteWriteSample{
+				value:     value,
+				timestamp: p.Time().UnixMilli(),
+			})
+		}
+	}
+
+	// samples of a series must be sent in chronological order
+	for _, s := range series {
+		sort.SliceStable(s.samples, func(i, j int) bool { return s.samples[i].timestamp < s.samples[j].timestamp })
+	}
+
+	return
+}
+
+func remoteWriteSeriesKey(labels []remoteWriteLabel) string {
+	var sb strings.Builder
+	for _, l := range labels {
+		sb.WriteString(l.name)
+		sb.WriteByte(0)
+		sb.WriteString(l.value)
+		sb.WriteByte(0)
+	}
+	return sb.String()
+}
+
+func prometheusValue(v interface{}) (float64, bool) {
+	switch value := v.(type) {
+	case float64:
+		return value, true
+	case int64:
+		return float64(value), true
+	case uint64:
+		return float64(value), true
+	case bool:
+		if value {
+			return 1, true
+		}
+		return 0, true
+	}
+	return math.NaN(), false
+}
+
+// prometheusMetricName replaces all characters not allowed in a metric name by _
+func prometheusMetricName(name string) string {
+	return prometheusSanitize(name, true)
+}
+
+// prometheusLabelName replaces all characters not allowed in a label name by _
+func prometheusLabelName(name string) string {
+	return prometheusSanitize(name, false)
+}
+
+func prometheusSanitize(name string, allowColon bool) string {
+	var sb strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+		case r >= '0' && r <= '9' && i > 0:
+		case r == ':' && allowColon:
+		default:
+			r = '_'
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// encodeRemoteWriteRequest encodes the series as prometheus.WriteRequest protobuf message:
+//
+//	message WriteRequest { repeated TimeSeries timeseries = 1; }
+//	message TimeSeries { repeated Label labels = 1; repeated Sample samples = 2; }
+//	message Label { string name = 1; string value = 2; }
+//	message Sample { double value = 1; int64 timestamp = 2; }
+func encodeRemoteWriteRequest(series []remoteWriteSeries) (b []byte) {
+	for _, s := range series {
+		var ts []byte
+		for _, l := range s.labels {
+			var label []byte
+			label = protowire.AppendTag(label, 1, protowire.BytesType)
+			label = protowire.AppendString(label, l.name)
+			label = protowire.AppendTag(label, 2, protowire.BytesType)
+			label = protowire.AppendString(label, l.value)
+
+			ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, label)
+		}
+		for _, sample := range s.samples {
+			var smp []byte
+			smp = protowire.AppendTag(smp, 1, protowire.Fixed64Type)
+			smp = protowire.AppendFixed64(smp, math.Float64bits(sample.value))
+			smp = protowire.AppendTag(smp, 2, protowire.VarintType)
+			smp = protowire.AppendVarint(smp, uint64(sample.timestamp))
+
+			ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, smp)
+		}
+
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, ts)
+	}
+	return
+}
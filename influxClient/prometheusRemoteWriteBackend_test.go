@@ -0,0 +1,137 @@
+package influxClient
+
+import (
+	"github.com/influxdata/influxdb-client-go/v2"
+	influxdb2Write "github.com/influxdata/influxdb-client-go/v2/api/write"
+	"google.golang.org/protobuf/encoding/protowire"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPointsToRemoteWriteSeries(t *testing.T) {
+	t0 := time.UnixMilli(1661461941000)
+	t1 := time.UnixMilli(1661461942000)
+	series := pointsToRemoteWriteSeries([]*influxdb2Write.Point{
+		influxdb2.NewPoint("telemetry",
+			map[string]string{"device": "foo/bar", "field": "Temperature", "sensor": "SI7021"},
+			map[string]interface{}{"floatValue": 21.5}, t1,
+		),
+		influxdb2.NewPoint("telemetry",
+			map[string]string{"device": "foo/bar", "field": "Power", "sensor": "tasmota"},
+			map[string]interface{}{"boolValue": true, "stringValue": "ON"}, t1,
+		),
+		influxdb2.NewPoint("telemetry",
+			map[string]string{"device": "foo/bar", "field": "Temperature", "sensor": "SI7021"},
+			map[string]interface{}{"floatValue": 21.4}, t0,
+		),
+		influxdb2.NewPoint("wifi",
+			map[string]string{"device": "foo/bar", "BSSId": "04:F0:21:2F:B7:CC"},
+			map[string]interface{}{"RSSI": 100}, t1,
+		),
+	})
+
+	expected := []remoteWriteSeries{
+		{
+			labels: []remoteWriteLabel{
+				{"__name__", "telemetry_floatValue"},
+				{"device", "foo/bar"},
+				{"field", "Temperature"},
+				{"sensor", "SI7021"},
+			},
+			samples: []remoteWriteSample{
+				{21.4, 1661461941000},
+				{21.5, 1661461942000},
+			},
+		}, {
+			labels: []remoteWriteLabel{
+				{"__name__", "telemetry_boolValue"},
+				{"device", "foo/bar"},
+				{"field", "Power"},
+				{"sensor", "tasmota"},
+			},
+			samples: []remoteWriteSample{
+				{1, 1661461942000},
+			},
+		}, {
+			labels: []remoteWriteLabel{
+				{"BSSId", "04:F0:21:2F:B7:CC"},
+				{"__name__", "wifi_RSSI"},
+				{"device", "foo/bar"},
+			},
+			samples: []remoteWriteSample{
+				{100, 1661461942000},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(series, expected) {
+		t.Errorf("expected series\n%v\nbut got\n%v", expected, series)
+	}
+}
+
+func TestPrometheusSanitize(t *testing.T) {
+	values := []struct{ inp, metric, label string }{
+		{"telemetry_floatValue", "telemetry_floatValue", "telemetry_floatValue"},
+		{"0abc", "_abc", "_abc"},
+		{"a:b-c d", "a:b_c_d", "a_b_c_d"},
+	}
+
+	for _, v := range values {
+		if got := prometheusMetricName(v.inp); got != v.metric {
+			t.Errorf("prometheusMetricName('%s'): expected '%s' but got '%s'", v.inp, v.metric, got)
+		}
+		if got := prometheusLabelName(v.inp); got != v.label {
+			t.Errorf("prometheusLabelName('%s'): expected '%s' but got '%s'", v.inp, v.label, got)
+		}
+	}
+}
+
+func TestEncodeRemoteWriteRequest(t *testing.T) {
+	b := encodeRemoteWriteRequest([]remoteWriteSeries{{
+		labels:  []remoteWriteLabel{{"__name__", "up"}},
+		samples: []remoteWriteSample{{1, 1000}},
+	}})
+
+	// WriteRequest.timeseries
+	num, typ, n := protowire.ConsumeTag(b)
+	if num != 1 || typ != protowire.BytesType {
+		t.Fatalf("expected timeseries field, got num=%d, typ=%d", num, typ)
+	}
+	ts, m := protowire.ConsumeBytes(b[n:])
+	if n+m != len(b) {
+		t.Fatalf("expected exactly one timeseries")
+	}
+
+	// TimeSeries.labels
+	num, _, n = protowire.ConsumeTag(ts)
+	if num != 1 {
+		t.Fatalf("expected labels field, got num=%d", num)
+	}
+	label, m := protowire.ConsumeBytes(ts[n:])
+	ts = ts[n+m:]
+
+	_, _, n = protowire.ConsumeTag(label)
+	name, m := protowire.ConsumeString(label[n:])
+	label = label[n+m:]
+	_, _, n = protowire.ConsumeTag(label)
+	value, _ := protowire.ConsumeString(label[n:])
+	if name != "__name__" || value != "up" {
+		t.Errorf("expected label __name__=up, got %s=%s", name, value)
+	}
+
+	// TimeSeries.samples
+	num, _, n = protowire.ConsumeTag(ts)
+	if num != 2 {
+		t.Fatalf("expected samples field, got num=%d", num)
+	}
+	sample, _ := protowire.ConsumeBytes(ts[n:])
+	_, _, n = protowire.ConsumeTag(sample)
+	_, m = protowire.ConsumeFixed64(sample[n:])
+	sample = sample[n+m:]
+	_, _, n = protowire.ConsumeTag(sample)
+	timestamp, _ := protowire.ConsumeVarint(sample[n:])
+	if timestamp != 1000 {
+		t.Errorf("expected timestamp=1000, got %d", timestamp)
+	}
+}
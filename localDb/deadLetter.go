@@ -0,0 +1,144 @@
+package LocalDb
+
+import (
+	"fmt"
+	"time"
+)
+
+// DeadLetter is a received mqtt message which could not be handled by a converter.
+// Dead letters are kept to allow replaying them once the converter is fixed.
+type DeadLetter struct {
+	Id         int       `json:"id"`
+	Received   time.Time `json:"received"`
+	MqttClient string    `json:"mqttClient"`
+	Converter  string    `json:"converter"`
+	Topic      string    `json:"topic"`
+	Payload    string    `json:"payload"`
+	Error      string    `json:"error"`
+}
+
+// DeadLetterAdd stores the given letter and removes letters exceeding DeadLetterMaxCount or DeadLetterMaxAge
+func (d *SqliteLocalDb) DeadLetterAdd(letter DeadLetter) error {
+	if d.config.DeadLetterMaxCount() < 1 {
+		return nil
+	}
+
+	if _, err := d.db.Exec(
+		"INSERT INTO deadLetter (received, mqttClient, converter, topic, payload, error) VALUES(?, ?, ?, ?, ?, ?);",
+		letter.Received.UTC(),
+		letter.MqttClient,
+		letter.Converter,
+		letter.Topic,
+		[]byte(letter.Payload),
+		letter.Error,
+	); err != nil {
+		return fmt.Errorf("cannot insert into deadLetter: %s", err)
+	}
+
+	// apply retention limits
+	if _, err := d.db.Exec(
+		"DELETE FROM deadLetter WHERE received < ?",
+		time.Now().Add(-d.config.DeadLetterMaxAge()).UTC(),
+	); err != nil {
+		return fmt.Errorf("cannot delete expired from deadLetter: %s", err)
+	}
+	if _, err := d.db.Exec(
+		"DELETE FROM deadLetter WHERE id <= (SELECT id FROM deadLetter ORDER BY id DESC LIMIT 1 OFFSET ?)",
+		d.config.DeadLetterMaxCount(),
+	); err != nil {
+		return fmt.Errorf("cannot delete exceeding from deadLetter: %s", err)
+	}
+
+	return nil
+}
+
+// DeadLetterList returns up to limit letters, newest first; converter and limit are ignored when empty / 0
+func (d *SqliteLocalDb) DeadLetterList(converter string, limit int) (letters []DeadLetter, err error) {
+	if limit < 1 {
+		limit = -1
+	}
+
+	rows, err := d.db.Query(
+		"SELECT id, received, mqttClient, converter, topic, payload, error FROM deadLetter "+
+			"WHERE ? = '' OR converter = ? ORDER BY id DESC LIMIT ?",
+		converter, converter, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot select from deadLetter: %s", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	letters = make([]DeadLetter, 0)
+	for rows.Next() {
+		letter, err := scanDeadLetter(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		letters = append(letters, letter)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("cannot select from deadLetter: %s", err)
+	}
+
+	return letters, nil
+}
+
+func (d *SqliteLocalDb) DeadLetterListStructless(converter string, limit int) (interface{}, error) {
+	return d.DeadLetterList(converter, limit)
+}
+
+func (d *SqliteLocalDb) DeadLetterGet(id int) (DeadLetter, error) {
+	row := d.db.QueryRow(
+		"SELECT id, received, mqttClient, converter, topic, payload, error FROM deadLetter WHERE id = ?",
+		id,
+	)
+	return scanDeadLetter(row.Scan)
+}
+
+func (d *SqliteLocalDb) DeadLetterPayload(id int) ([]byte, error) {
+	letter, err := d.DeadLetterGet(id)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(letter.Payload), nil
+}
+
+func (d *SqliteLocalDb) DeadLetterDelete(id int) error {
+	if _, err := d.db.Exec("DELETE FROM deadLetter WHERE id = ?", id); err != nil {
+		return fmt.Errorf("cannot delete from deadLetter: %s", err)
+	}
+
+	d.vacuumNeeded = true
+
+	return nil
+}
+
+func scanDeadLetter(scan func(dest ...interface{}) error) (letter DeadLetter, err error) {
+	var payload []byte
+	if err := scan(
+		&letter.Id, &letter.Received, &letter.MqttClient, &letter.Converter, &letter.Topic, &payload, &letter.Error,
+	); err != nil {
+		return DeadLetter{}, fmt.Errorf("cannot select from deadLetter: %s", err)
+	}
+	letter.Payload = string(payload)
+	return
+}
+
+func (d *DisabledLocalDb) DeadLetterAdd(letter DeadLetter) error {
+	return nil
+}
+func (d *DisabledLocalDb) DeadLetterList(converter string, limit int) ([]DeadLetter, error) {
+	return nil, fmt.Errorf("disabled")
+}
+func (d *DisabledLocalDb) DeadLetterListStructless(converter string, limit int) (interface{}, error) {
+	return nil, fmt.Errorf("disabled")
+}
+func (d *DisabledLocalDb) DeadLetterGet(id int) (DeadLetter, error) {
+	return DeadLetter{}, fmt.Errorf("disabled")
+}
+func (d *DisabledLocalDb) DeadLetterPayload(id int) ([]byte, error) {
+	return nil, fmt.Errorf("disabled")
+}
+func (d *DisabledLocalDb) DeadLetterDelete(id int) error {
+	return fmt.Errorf("disabled")
+}
@@ -0,0 +1,93 @@
+package LocalDb
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	path string
+}
+
+func (c testConfig) Enabled() bool {
+	return true
+}
+
+func (c testConfig) Path() string {
+	return c.path
+}
+
+func (c testConfig) DeadLetterMaxCount() int {
+	return 3
+}
+
+func (c testConfig) DeadLetterMaxAge() time.Duration {
+	return time.Hour
+}
+
+func TestDeadLetter(t *testing.T) {
+	db := Run(testConfig{path: filepath.Join(t.TempDir(), "test.db")})
+	defer db.Shutdown()
+
+	if !db.Enabled() {
+		t.Fatal("expected db to be enabled")
+	}
+
+	add := func(received time.Time, converter, topic string) {
+		if err := db.DeadLetterAdd(DeadLetter{
+			Received:   received,
+			MqttClient: "local",
+			Converter:  converter,
+			Topic:      topic,
+			Payload:    "invalid",
+			Error:      "cannot json decode",
+		}); err != nil {
+			t.Fatalf("did not expect an error, got %s", err)
+		}
+	}
+
+	now := time.Now()
+	add(now.Add(-2*time.Hour), "tasmota-state", "tele/expired/STATE")
+	for _, topic := range []string{"tele/dev0/STATE", "tele/dev1/STATE", "tele/dev2/STATE", "tele/dev3/STATE"} {
+		add(now, "tasmota-state", topic)
+	}
+	add(now, "tasmota-sensor", "tele/dev0/SENSOR")
+
+	letters, err := db.DeadLetterList("", 0)
+	if err != nil {
+		t.Fatalf("did not expect an error, got %s", err)
+	}
+	if len(letters) != 3 {
+		t.Fatalf("expected 3 letters to be kept, got %d", len(letters))
+	}
+	if letters[0].Topic != "tele/dev0/SENSOR" || letters[2].Topic != "tele/dev2/STATE" {
+		t.Errorf("expected newest letters first, got %v", letters)
+	}
+
+	letters, err = db.DeadLetterList("tasmota-state", 1)
+	if err != nil {
+		t.Fatalf("did not expect an error, got %s", err)
+	}
+	if len(letters) != 1 || letters[0].Topic != "tele/dev3/STATE" {
+		t.Fatalf("expected the newest tasmota-state letter, got %v", letters)
+	}
+
+	letter, err := db.DeadLetterGet(letters[0].Id)
+	if err != nil {
+		t.Fatalf("did not expect an error, got %s", err)
+	}
+	if letter.Payload != "invalid" || letter.Error != "cannot json decode" || letter.MqttClient != "local" {
+		t.Errorf("unexpected letter: %v", letter)
+	}
+	if !letter.Received.Equal(now) {
+		t.Errorf("expected received=%s, got %s", now, letter.Received)
+	}
+
+	if err := db.DeadLetterDelete(letter.Id); err != nil {
+		t.Fatalf("did not expect an error, got %s", err)
+	}
+	if _, err := db.DeadLetterGet(letter.Id); err == nil {
+		t.Error("expected an error when getting a deleted letter")
+	}
+}
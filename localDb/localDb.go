@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	_ "github.com/mattn/go-sqlite3"
 	"log"
+	"time"
 )
 
 type LocalDb interface {
@@ -14,6 +15,12 @@ type LocalDb interface {
 	InfluxBacklogGet(client string) (id int, batch string, err error)
 	InfluxBacklogDelete(id int) error
 	InfluxAggregateBacklog(client string, batchSize uint) error
+	DeadLetterAdd(letter DeadLetter) error
+	DeadLetterList(converter string, limit int) ([]DeadLetter, error)
+	DeadLetterListStructless(converter string, limit int) (interface{}, error)
+	DeadLetterGet(id int) (DeadLetter, error)
+	DeadLetterPayload(id int) ([]byte, error)
+	DeadLetterDelete(id int) error
 }
 
 type SqliteLocalDb struct {
@@ -27,6 +34,8 @@ type DisabledLocalDb struct{}
 type Config interface {
 	Enabled() bool
 	Path() string
+	DeadLetterMaxCount() int
+	DeadLetterMaxAge() time.Duration
 }
 
 func Run(config Config) LocalDb {
@@ -44,10 +53,18 @@ func Run(config Config) LocalDb {
 				} else {
 					log.Printf("localDb: db initialized with schema version 0")
 				}
-			} else {
-				log.Printf("localDb: db schema up-to-date at version: %d", version)
 			}
 
+			// apply all migrations not yet applied
+			for ; version < len(migrations); version += 1 {
+				if _, err := db.Exec(migrations[version]); err != nil {
+					log.Printf("localDb: error while migrating db to schema version %d: %s", version+1, err)
+					break
+				}
+				log.Printf("localDb: db migrated to schema version %d", version+1)
+			}
+			log.Printf("localDb: db schema at version: %d", version)
+
 			return &SqliteLocalDb{
 				config:       config,
 				db:           db,
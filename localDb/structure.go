@@ -16,3 +16,21 @@ CREATE TABLE influxBacklog (
 );
 CREATE UNIQUE INDEX clientIdx ON influxBacklog (client, id);
 `
+
+// migrations are applied in order after structure; the migration at index i upgrades the schema to version i+1
+var migrations = []string{
+	`
+CREATE TABLE deadLetter (
+  id INTEGER PRIMARY KEY NOT NULL,
+  received DATETIME NOT NULL,
+  mqttClient VARCHAR NOT NULL,
+  converter VARCHAR NOT NULL,
+  topic VARCHAR NOT NULL,
+  payload BLOB NOT NULL,
+  error VARCHAR NOT NULL
+);
+CREATE INDEX deadLetterConverterIdx ON deadLetter (converter, id);
+CREATE INDEX deadLetterReceivedIdx ON deadLetter (received);
+INSERT INTO dbSchema VALUES (1, datetime('now'));
+`,
+}
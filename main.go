@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"github.com/jessevdk/go-flags"
 	"github.com/koestler/go-mqtt-to-influx/v2/config"
+	"github.com/koestler/go-mqtt-to-influx/v2/converter"
+	"github.com/koestler/go-mqtt-to-influx/v2/recorder"
 	"log"
 	"os"
 	"os/signal"
@@ -16,10 +18,15 @@ var buildVersion string
 var buildTime string
 
 type CmdOptions struct {
-	Version    bool           `long:"version" description:"Print the build version and timestamp"`
-	Config     flags.Filename `short:"c" long:"config" description:"Config File in yaml format" default:"./config.yaml"`
-	CpuProfile flags.Filename `long:"cpuprofile" description:"write cpu profile to <file>"`
-	MemProfile flags.Filename `long:"memprofile" description:"write memory profile to <file>"`
+	Version     bool           `long:"version" description:"Print the build version and timestamp"`
+	Config      flags.Filename `short:"c" long:"config" description:"Config File in yaml format" default:"./config.yaml"`
+	CpuProfile  flags.Filename `long:"cpuprofile" description:"write cpu profile to <file>"`
+	MemProfile  flags.Filename `long:"memprofile" description:"write memory profile to <file>"`
+	WatchConfig bool           `long:"watch-config" description:"Reload the configuration when the config file is changed"`
+
+	Check             CheckCommand             `command:"check" description:"Validate the config, list the subscribe topics and optionally run a sample message through the converters and exit"`
+	Replay            ReplayCommand            `command:"replay" description:"Replay recorded messages through the converters and exit"`
+	ReplayDeadLetters ReplayDeadLettersCommand `command:"replay-dead-letters" description:"Replay the dead letters stored in the local db through a converter and exit"`
 }
 
 const (
@@ -27,12 +34,14 @@ const (
 	ExitDueToCmdOptions  = 1
 	ExitDueToConfig      = 2
 	ExitDueToModuleStart = 3
+	ExitDueToCheck       = 4
 )
 
-func getCmdOptions() (cmdOptions CmdOptions, cmdName string) {
+func getCmdOptions() (cmdOptions CmdOptions, cmdName string, command string) {
 	// parse command line options
 	parser := flags.NewParser(&cmdOptions, flags.Default)
 	parser.Usage = "[-c <path to yaml config file>]"
+	parser.SubcommandsOptional = true
 	if _, err := parser.Parse(); err != nil {
 		if flagsErr, ok := err.(*flags.Error); ok && flagsErr.Type == flags.ErrHelp {
 			os.Exit(ExitSuccess)
@@ -47,7 +56,11 @@ func getCmdOptions() (cmdOptions CmdOptions, cmdName string) {
 		os.Exit(ExitSuccess)
 	}
 
-	return cmdOptions, parser.Name
+	if parser.Active != nil {
+		command = parser.Active.Name
+	}
+
+	return cmdOptions, parser.Name, command
 }
 
 func getConfig(cmdOptions CmdOptions, cmdName string) *config.Config {
@@ -71,9 +84,23 @@ func getConfig(cmdOptions CmdOptions, cmdName string) *config.Config {
 
 func main() {
 	// read cmd parameters and configuration file; on error: os.Exit
-	cmdOptions, cmdName := getCmdOptions()
+	cmdOptions, cmdName, command := getCmdOptions()
+
+	// check reports the configuration errors itself
+	if command == "check" {
+		os.Exit(runCheck(cmdOptions, cmdName))
+	}
+
 	cfg := getConfig(cmdOptions, cmdName)
 
+	// run a command instead of the service if one is given
+	switch command {
+	case "replay":
+		os.Exit(runReplay(cfg, cmdOptions.Replay))
+	case "replay-dead-letters":
+		os.Exit(runReplayDeadLetters(cfg, cmdOptions.ReplayDeadLetters))
+	}
+
 	// call defer statements before os.Exit
 	exitCode := func() (exitCode int) {
 		// whenever an error is pushed to this chan, main is terminated
@@ -95,24 +122,37 @@ func main() {
 		// start statistics module
 		statisticsInstance := runStatistics(cfg)
 
+		// start metrics module
+		metricsInstance := runMetrics(localDbInstance)
+
+		// keep the last inputs the converters could not handle
+		errorHistoryInstance := converter.NewErrorHistory()
+
 		// start http server
-		httpServerInstance := runHttpServer(cfg, statisticsInstance)
+		httpServerInstance := runHttpServer(cfg, localDbInstance, statisticsInstance, metricsInstance, errorHistoryInstance)
 		if httpServerInstance != nil {
 			defer httpServerInstance.Shutdown()
 		}
 
-		// create mqtt clients
-		mqttClientPoolInstance := runMqttClient(cfg, statisticsInstance, initiateShutdown)
+		// create the pool for the recorders; they are shut down after the mqtt clients
+		recorderPoolInstance := recorder.RunPool()
+		defer recorderPoolInstance.Shutdown()
+
+		// create mqtt clients and their recorders
+		mqttClientPoolInstance := runMqttClient(cfg, statisticsInstance, metricsInstance, recorderPoolInstance, initiateShutdown)
 		defer mqttClientPoolInstance.Shutdown()
 
 		// start influx clients
-		influxClientPoolInstance := runInfluxClient(cfg, localDbInstance, statisticsInstance, initiateShutdown)
+		influxClientPoolInstance := runInfluxClient(cfg, localDbInstance, statisticsInstance, metricsInstance, initiateShutdown)
 		defer influxClientPoolInstance.Shutdown()
 
 		// create converters, add routes to the mqtt clients
 		createConverters(
 			cfg,
 			statisticsInstance,
+			metricsInstance,
+			errorHistoryInstance,
+			localDbInstance,
 			mqttClientPoolInstance,
 			influxClientPoolInstance,
 			initiateShutdown,
@@ -122,7 +162,7 @@ func main() {
 		mqttClientPoolInstance.RunClients()
 
 		if cfg.LogWorkerStart() {
-			log.Print("main: start completed; run until SIGTERM or SIGINT is received")
+			log.Print("main: start completed; run until SIGTERM or SIGINT is received, reload on SIGHUP")
 		}
 
 		// setup SIGTERM, SIGINT handlers
@@ -130,16 +170,48 @@ func main() {
 		signal.Notify(gracefulStop, syscall.SIGTERM)
 		signal.Notify(gracefulStop, syscall.SIGINT)
 
+		// setup SIGHUP handler and the optional config file watcher to reload the configuration
+		reloadSignal := make(chan os.Signal, 1)
+		signal.Notify(reloadSignal, syscall.SIGHUP)
+
+		var configChanged <-chan struct{}
+		if cmdOptions.WatchConfig {
+			var err error
+			if configChanged, err = watchConfigFile(string(cmdOptions.Config)); err != nil {
+				log.Printf("main: cannot watch config file: %s", err)
+			}
+		}
+
 		// wait for something to trigger a shutdown
-		select {
-		case err := <-initiateShutdown:
-			log.Printf("main: forced shutdown due to fatal error: %s", err)
-			exitCode = ExitDueToModuleStart
-		case sig := <-gracefulStop:
-			if cfg.LogWorkerStart() {
-				log.Printf("main: graceful shutdown; caught signal: %+v", sig)
+	loop:
+		for {
+			select {
+			case err := <-initiateShutdown:
+				log.Printf("main: forced shutdown due to fatal error: %s", err)
+				exitCode = ExitDueToModuleStart
+				break loop
+			case sig := <-gracefulStop:
+				if cfg.LogWorkerStart() {
+					log.Printf("main: graceful shutdown; caught signal: %+v", sig)
+				}
+				exitCode = ExitSuccess
+				break loop
+			case <-reloadSignal:
+			case <-configChanged:
 			}
-			exitCode = ExitSuccess
+
+			cfg = reloadConfig(
+				cmdOptions,
+				cmdName,
+				cfg,
+				localDbInstance,
+				statisticsInstance,
+				metricsInstance,
+				errorHistoryInstance,
+				mqttClientPoolInstance,
+				influxClientPoolInstance,
+				recorderPoolInstance,
+			)
 		}
 
 		// write memory profile; after that defer will run the shutdown methods
@@ -0,0 +1,10 @@
+package main
+
+import (
+	LocalDb "github.com/koestler/go-mqtt-to-influx/v2/localDb"
+	"github.com/koestler/go-mqtt-to-influx/v2/metrics"
+)
+
+func runMetrics(localDbInstance LocalDb.LocalDb) *metrics.Metrics {
+	return metrics.Run(localDbInstance)
+}
@@ -0,0 +1,222 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Metrics holds always-on counters and gauges describing the health of the processing pipeline.
+// In contrast to the statistics module, only totals per client / converter are kept, which makes it cheap enough
+// to be always enabled. The values are exposed in the Prometheus text exposition format.
+type Metrics struct {
+	localDb LocalDb
+
+	mutex         sync.RWMutex
+	values        map[desc]float64
+	influxClients map[string]struct{}
+}
+
+type LocalDb interface {
+	Enabled() bool
+	InfluxBacklogSize(client string) (numbBatches, numbLines uint, err error)
+}
+
+type desc struct {
+	family string
+	label  string
+}
+
+type family struct {
+	name      string
+	help      string
+	typ       string
+	labelName string
+}
+
+const prefix = "go_mqtt_to_influx_"
+
+var (
+	mqttMessagesReceived = family{
+		prefix + "mqtt_messages_received_total", "Number of messages received per mqtt client.", "counter", "mqtt_client",
+	}
+	mqttConnected = family{
+		prefix + "mqtt_connected", "1 when the mqtt client is connected to the broker, 0 otherwise.", "gauge", "mqtt_client",
+	}
+	converterMessagesHandled = family{
+		prefix + "converter_messages_handled_total", "Number of messages handled per converter.", "counter", "converter",
+	}
+	converterErrors = family{
+		prefix + "converter_errors_total", "Number of messages a converter could not parse.", "counter", "converter",
+	}
+	influxPointsWritten = family{
+		prefix + "influx_points_written_total", "Number of points successfully written per influx client.", "counter", "influx_client",
+	}
+	influxPointsFailed = family{
+		prefix + "influx_points_failed_total", "Number of points which failed to be written per influx client.", "counter", "influx_client",
+	}
+	influxPointsRetried = family{
+		prefix + "influx_points_retried_total", "Number of points successfully written from the backlog / retry queue per influx client.", "counter", "influx_client",
+	}
+	localDbBacklogBatches = family{
+		prefix + "local_db_backlog_batches", "Number of batches waiting in the local db backlog per influx client.", "gauge", "influx_client",
+	}
+	localDbBacklogLines = family{
+		prefix + "local_db_backlog_lines", "Number of lines waiting in the local db backlog per influx client.", "gauge", "influx_client",
+	}
+
+	families = []family{
+		mqttMessagesReceived,
+		mqttConnected,
+		converterMessagesHandled,
+		converterErrors,
+		influxPointsWritten,
+		influxPointsFailed,
+		influxPointsRetried,
+		localDbBacklogBatches,
+		localDbBacklogLines,
+	}
+)
+
+func Run(localDb LocalDb) *Metrics {
+	return &Metrics{
+		localDb:       localDb,
+		values:        make(map[desc]float64),
+		influxClients: make(map[string]struct{}),
+	}
+}
+
+func (m *Metrics) add(f family, label string, delta float64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.values[desc{family: f.name, label: label}] += delta
+}
+
+func (m *Metrics) set(f family, label string, value float64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.values[desc{family: f.name, label: label}] = value
+}
+
+// AddMqttClient initializes all metrics of the given mqtt client
+func (m *Metrics) AddMqttClient(client string) {
+	m.add(mqttMessagesReceived, client, 0)
+	m.add(mqttConnected, client, 0)
+}
+
+// AddConverter initializes all metrics of the given converter
+func (m *Metrics) AddConverter(converter string) {
+	m.add(converterMessagesHandled, converter, 0)
+	m.add(converterErrors, converter, 0)
+}
+
+// AddInfluxClient initializes all metrics of the given influx client
+func (m *Metrics) AddInfluxClient(client string) {
+	m.add(influxPointsWritten, client, 0)
+	m.add(influxPointsFailed, client, 0)
+	m.add(influxPointsRetried, client, 0)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.influxClients[client] = struct{}{}
+}
+
+func (m *Metrics) MqttMessageReceived(client string) {
+	m.add(mqttMessagesReceived, client, 1)
+}
+
+func (m *Metrics) MqttConnectionState(client string, connected bool) {
+	if connected {
+		m.set(mqttConnected, client, 1)
+	} else {
+		m.set(mqttConnected, client, 0)
+	}
+}
+
+func (m *Metrics) ConverterMessageHandled(converter string) {
+	m.add(converterMessagesHandled, converter, 1)
+}
+
+func (m *Metrics) ConverterError(converter string) {
+	m.add(converterErrors, converter, 1)
+}
+
+func (m *Metrics) InfluxPointsWritten(client string, numbPoints int) {
+	m.add(influxPointsWritten, client, float64(numbPoints))
+}
+
+func (m *Metrics) InfluxPointsFailed(client string, numbPoints int) {
+	m.add(influxPointsFailed, client, float64(numbPoints))
+}
+
+func (m *Metrics) InfluxPointsRetried(client string, numbPoints int) {
+	m.add(influxPointsRetried, client, float64(numbPoints))
+}
+
+// WriteTo writes all metrics in the Prometheus text exposition format
+func (m *Metrics) WriteTo(w io.Writer) (n int64, err error) {
+	values := m.snapshot()
+
+	var sb strings.Builder
+	for _, f := range families {
+		labels := values[f.name]
+		if len(labels) < 1 {
+			continue
+		}
+
+		fmt.Fprintf(&sb, "# HELP %s %s\n", f.name, f.help)
+		fmt.Fprintf(&sb, "# TYPE %s %s\n", f.name, f.typ)
+
+		keys := make([]string, 0, len(labels))
+		for k := range labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(&sb, "%s{%s=\"%s\"} %v\n", f.name, f.labelName, escapeLabelValue(k), labels[k])
+		}
+	}
+
+	c, err := io.WriteString(w, sb.String())
+	return int64(c), err
+}
+
+// snapshot copies all values and adds the gauges computed on request
+func (m *Metrics) snapshot() map[string]map[string]float64 {
+	ret := make(map[string]map[string]float64, len(families))
+	put := func(family, label string, value float64) {
+		if _, ok := ret[family]; !ok {
+			ret[family] = make(map[string]float64)
+		}
+		ret[family][label] = value
+	}
+
+	m.mutex.RLock()
+	influxClients := make([]string, 0, len(m.influxClients))
+	for client := range m.influxClients {
+		influxClients = append(influxClients, client)
+	}
+	for d, v := range m.values {
+		put(d.family, d.label, v)
+	}
+	m.mutex.RUnlock()
+
+	if m.localDb.Enabled() {
+		for _, client := range influxClients {
+			if numbBatches, numbLines, err := m.localDb.InfluxBacklogSize(client); err == nil {
+				put(localDbBacklogBatches.name, client, float64(numbBatches))
+				put(localDbBacklogLines.name, client, float64(numbLines))
+			}
+		}
+	}
+
+	return ret
+}
+
+func escapeLabelValue(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `"`, `\"`)
+	return strings.ReplaceAll(v, "\n", `\n`)
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type testLocalDb struct{}
+
+func (testLocalDb) Enabled() bool {
+	return true
+}
+
+func (testLocalDb) InfluxBacklogSize(client string) (numbBatches, numbLines uint, err error) {
+	return 2, 42, nil
+}
+
+func TestWriteTo(t *testing.T) {
+	m := Run(testLocalDb{})
+	m.AddMqttClient("local")
+	m.AddConverter("tasmota-state")
+	m.AddInfluxClient("influx")
+
+	m.MqttConnectionState("local", true)
+	m.MqttMessageReceived("local")
+	m.MqttMessageReceived("local")
+	m.ConverterMessageHandled("tasmota-state")
+	m.ConverterError("tasmota-state")
+	m.InfluxPointsWritten("influx", 10)
+	m.InfluxPointsFailed("influx", 5)
+	m.InfluxPointsRetried("influx", 5)
+
+	var b bytes.Buffer
+	if _, err := m.WriteTo(&b); err != nil {
+		t.Fatalf("did not expect an error, got %s", err)
+	}
+	out := b.String()
+
+	expected := []string{
+		"# TYPE go_mqtt_to_influx_mqtt_messages_received_total counter",
+		`go_mqtt_to_influx_mqtt_messages_received_total{mqtt_client="local"} 2`,
+		"# TYPE go_mqtt_to_influx_mqtt_connected gauge",
+		`go_mqtt_to_influx_mqtt_connected{mqtt_client="local"} 1`,
+		`go_mqtt_to_influx_converter_messages_handled_total{converter="tasmota-state"} 1`,
+		`go_mqtt_to_influx_converter_errors_total{converter="tasmota-state"} 1`,
+		`go_mqtt_to_influx_influx_points_written_total{influx_client="influx"} 10`,
+		`go_mqtt_to_influx_influx_points_failed_total{influx_client="influx"} 5`,
+		`go_mqtt_to_influx_influx_points_retried_total{influx_client="influx"} 5`,
+		`go_mqtt_to_influx_local_db_backlog_batches{influx_client="influx"} 2`,
+		`go_mqtt_to_influx_local_db_backlog_lines{influx_client="influx"} 42`,
+	}
+
+	for _, e := range expected {
+		if !strings.Contains(out, e+"\n") {
+			t.Errorf("expected output to contain '%s', got:\n%s", e, out)
+		}
+	}
+}
+
+func TestEscapeLabelValue(t *testing.T) {
+	if got := escapeLabelValue("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Errorf("unexpected escaped value: %s", got)
+	}
+}
@@ -2,7 +2,9 @@ package main
 
 import (
 	"github.com/koestler/go-mqtt-to-influx/v2/config"
+	"github.com/koestler/go-mqtt-to-influx/v2/metrics"
 	"github.com/koestler/go-mqtt-to-influx/v2/mqttClient"
+	"github.com/koestler/go-mqtt-to-influx/v2/recorder"
 	"github.com/koestler/go-mqtt-to-influx/v2/statistics"
 	"log"
 )
@@ -10,28 +12,62 @@ import (
 func runMqttClient(
 	cfg *config.Config,
 	statisticsInstance statistics.Statistics,
+	metricsInstance *metrics.Metrics,
+	recorderPoolInstance *recorder.Pool,
 	initiateShutdown chan<- error,
 ) (mqttClientPoolInstance *mqttClient.ClientPool) {
 	// run pool
 	mqttClientPoolInstance = mqttClient.RunPool()
 
 	for _, mqttClientConfig := range cfg.MqttClients() {
-		if cfg.LogWorkerStart() {
-			log.Printf(
-				"mqttClient[%s]: start: Broker='%s', ClientId='%s'",
-				mqttClientConfig.Name(), mqttClientConfig.Broker(), mqttClientConfig.ClientId(),
-			)
-		}
-
-		var client mqttClient.Client
-		if mqttClientConfig.ProtocolVersion() == 3 {
-			client = mqttClient.CreateV3(mqttClientConfig, statisticsInstance)
-		} else {
-			client = mqttClient.CreateV5(mqttClientConfig, statisticsInstance)
-		}
-
+		client := createMqttClient(cfg, mqttClientConfig, statisticsInstance, metricsInstance)
 		mqttClientPoolInstance.AddClient(client)
+		startRecorder(cfg, mqttClientConfig, client, recorderPoolInstance)
 	}
 
 	return
 }
+
+func createMqttClient(
+	cfg *config.Config,
+	mqttClientConfig config.MqttClientConfig,
+	statisticsInstance statistics.Statistics,
+	metricsInstance *metrics.Metrics,
+) mqttClient.Client {
+	if cfg.LogWorkerStart() {
+		log.Printf(
+			"mqttClient[%s]: start: Broker='%s', ClientId='%s'",
+			mqttClientConfig.Name(), mqttClientConfig.Broker(), mqttClientConfig.ClientId(),
+		)
+	}
+
+	metricsInstance.AddMqttClient(mqttClientConfig.Name())
+
+	if mqttClientConfig.ProtocolVersion() == 3 {
+		return mqttClient.CreateV3(wrapMqttClientConfig(mqttClientConfig), statisticsInstance, metricsInstance)
+	}
+	return mqttClient.CreateV5(wrapMqttClientConfig(mqttClientConfig), statisticsInstance, metricsInstance)
+}
+
+// mqttClientConfig wraps config.MqttClientConfig to satisfy the mqttClient.Config interface
+type mqttClientConfig struct {
+	config.MqttClientConfig
+	periodicPublishes []mqttClient.PeriodicPublishConfig
+}
+
+func wrapMqttClientConfig(c config.MqttClientConfig) mqttClientConfig {
+	// convert []config.PeriodicPublishConfig to []mqttClient.PeriodicPublishConfig
+	periodicPublishes := make([]mqttClient.PeriodicPublishConfig, len(c.PeriodicPublishes()))
+	for i, p := range c.PeriodicPublishes() {
+		periodicPublishes[i] = p
+	}
+
+	return mqttClientConfig{
+		MqttClientConfig:  c,
+		periodicPublishes: periodicPublishes,
+	}
+}
+
+func (c mqttClientConfig) PeriodicPublishes() []mqttClient.PeriodicPublishConfig {
+	return c.periodicPublishes
+}
@@ -2,58 +2,163 @@ package mqttClient
 
 import (
 	"log"
+	"slices"
 	"sync"
 )
 
 type ClientStruct struct {
 	cfg        Config
 	statistics Statistics
+	metrics    Metrics
 	shutdown   chan struct{}
 
 	subscriptionsMutex sync.RWMutex
 	subscriptions      []subscription
+	// brokerTopics are the topics subscribed at the broker; they never overlap, hence every message is delivered once
+	brokerTopics []string
 }
 
 type subscription struct {
+	owner          string
 	subscribeTopic string
 	messageHandler MessageHandler
+	// messages only received by observers are not counted as received
+	observer bool
 }
 
-func createClientStruct(cfg Config, statistics Statistics) ClientStruct {
+func createClientStruct(cfg Config, statistics Statistics, metrics Metrics) ClientStruct {
 	return ClientStruct{
 		cfg:        cfg,
 		statistics: statistics,
+		metrics:    metrics,
 		shutdown:   make(chan struct{}),
 	}
 }
 
-func (c *ClientStruct) AddRoute(subscribeTopic string, messageHandler MessageHandler) {
+// addRoute adds a subscription and returns the topics to subscribe and to unsubscribe at the broker
+func (c *ClientStruct) addRoute(
+	owner, subscribeTopic string, messageHandler MessageHandler, observer bool,
+) (subscribe, unsubscribe []string) {
 	log.Printf("mqttClient[%s]: add route for topic='%s'", c.cfg.Name(), subscribeTopic)
 
-	s := subscription{subscribeTopic: subscribeTopic}
+	c.subscriptionsMutex.Lock()
+	defer c.subscriptionsMutex.Unlock()
+	c.subscriptions = append(c.subscriptions, subscription{
+		owner:          owner,
+		subscribeTopic: subscribeTopic,
+		messageHandler: messageHandler,
+		observer:       observer,
+	})
+	return c.updateBrokerTopics()
+}
 
-	if c.cfg.LogMessages() {
-		s.messageHandler = func(message Message) {
-			pl := string(message.Payload())
-			if len(pl) > 80 {
-				// only log first 80 chars of payload
-				pl = pl[:80] + "..."
+// removeRoutes removes all subscriptions of the given owner and returns the topics to subscribe and to unsubscribe
+// at the broker; topics covered by a removed topic before may need to be subscribed now
+func (c *ClientStruct) removeRoutes(owner string) (subscribe, unsubscribe []string) {
+	c.subscriptionsMutex.Lock()
+	defer c.subscriptionsMutex.Unlock()
+
+	subscriptions := make([]subscription, 0, len(c.subscriptions))
+	for _, s := range c.subscriptions {
+		if s.owner == owner {
+			log.Printf("mqttClient[%s]: remove route for topic='%s'", c.cfg.Name(), s.subscribeTopic)
+		} else {
+			subscriptions = append(subscriptions, s)
+		}
+	}
+	c.subscriptions = subscriptions
+	return c.updateBrokerTopics()
+}
+
+// updateBrokerTopics must be called with subscriptionsMutex held
+func (c *ClientStruct) updateBrokerTopics() (subscribe, unsubscribe []string) {
+	var distinct []string
+	for _, s := range c.subscriptions {
+		if !slices.Contains(distinct, s.subscribeTopic) {
+			distinct = append(distinct, s.subscribeTopic)
+		}
+	}
+
+	// a broker may deliver a message matching overlapping subscriptions once per subscription,
+	// hence topics covered by another one are dropped and overlapping ones are merged into a topic covering both
+	brokerTopics := distinct
+	for merged := true; merged; {
+		merged = false
+		brokerTopics = slices.DeleteFunc(slices.Clone(brokerTopics), func(topic string) bool {
+			return slices.ContainsFunc(brokerTopics, func(other string) bool {
+				return other != topic && topicFilterCovers(other, topic)
+			})
+		})
+	search:
+		for i, topic := range brokerTopics {
+			for j := i + 1; j < len(brokerTopics); j++ {
+				if topicFilterOverlaps(topic, brokerTopics[j]) {
+					brokerTopics[i] = topicFilterMerge(topic, brokerTopics[j])
+					brokerTopics = slices.Delete(brokerTopics, j, j+1)
+					merged = true
+					break search
+				}
 			}
+		}
+	}
+
+	for _, topic := range brokerTopics {
+		if !slices.Contains(c.brokerTopics, topic) {
+			subscribe = append(subscribe, topic)
+		}
+	}
+	for _, topic := range c.brokerTopics {
+		if !slices.Contains(brokerTopics, topic) {
+			unsubscribe = append(unsubscribe, topic)
+		}
+	}
+	c.brokerTopics = brokerTopics
+	return
+}
 
-			log.Printf("mqttClient[%s]: received: %s %s", c.cfg.Name(), message.Topic(), pl)
-			c.statistics.IncrementOne("mqtt", c.Name(), subscribeTopic)
-			messageHandler(message)
+// topics returns the topics to subscribe at the broker
+func (c *ClientStruct) topics() []string {
+	c.subscriptionsMutex.RLock()
+	defer c.subscriptionsMutex.RUnlock()
+	return slices.Clone(c.brokerTopics)
+}
+
+// handleMessage is called once per message delivered by the broker and passes it to all routes matching its topic;
+// every route is called at most once per message and the message is counted once
+func (c *ClientStruct) handleMessage(message Message) {
+	c.subscriptionsMutex.RLock()
+	handlers := make([]MessageHandler, 0, 1)
+	var countedTopics []string
+	for _, s := range c.subscriptions {
+		if !topicFilterMatches(s.subscribeTopic, message.Topic()) {
+			continue
 		}
-	} else {
-		s.messageHandler = func(message Message) {
-			c.statistics.IncrementOne("mqtt", c.Name(), subscribeTopic)
-			messageHandler(message)
+		handlers = append(handlers, s.messageHandler)
+		if !s.observer && !slices.Contains(countedTopics, s.subscribeTopic) {
+			countedTopics = append(countedTopics, s.subscribeTopic)
 		}
 	}
+	c.subscriptionsMutex.RUnlock()
 
-	c.subscriptionsMutex.Lock()
-	defer c.subscriptionsMutex.Unlock()
-	c.subscriptions = append(c.subscriptions, s)
+	if c.cfg.LogMessages() {
+		pl := string(message.Payload())
+		if len(pl) > 80 {
+			// only log first 80 chars of payload
+			pl = pl[:80] + "..."
+		}
+		log.Printf("mqttClient[%s]: received: %s %s", c.cfg.Name(), message.Topic(), pl)
+	}
+
+	if len(countedTopics) > 0 {
+		c.metrics.MqttMessageReceived(c.Name())
+		for _, topic := range countedTopics {
+			c.statistics.IncrementOne("mqtt", c.Name(), topic)
+		}
+	}
+
+	for _, h := range handlers {
+		h(message)
+	}
 }
 
 func (c *ClientStruct) Name() string {
@@ -14,9 +14,9 @@ type ClientV3 struct {
 	mc      mqtt.Client
 }
 
-func CreateV3(cfg Config, statistics Statistics) (client *ClientV3) {
+func CreateV3(cfg Config, statistics Statistics, metrics Metrics) (client *ClientV3) {
 	client = &ClientV3{
-		ClientStruct: createClientStruct(cfg, statistics),
+		ClientStruct: createClientStruct(cfg, statistics, metrics),
 	}
 
 	// configure mqtt library
@@ -27,11 +27,21 @@ func CreateV3(cfg Config, statistics Statistics) (client *ClientV3) {
 		SetMaxReconnectInterval(cfg.ConnectRetryDelay()).
 		SetConnectTimeout(cfg.ConnectTimeout()).
 		SetOnConnectHandler(client.onConnectionUp()).
+		SetConnectionLostHandler(client.onConnectionLost()).
 		SetClientID(cfg.ClientId()).
 		SetConnectRetry(true).
 		SetAutoReconnect(true).
 		SetOrderMatters(false).
-		SetCleanSession(true)
+		SetCleanSession(true).
+		SetDefaultPublishHandler(func(_ mqtt.Client, m mqtt.Message) {
+			// topics are subscribed without callback, hence every message is passed here exactly once
+			client.handleMessage(Message{
+				topic:    m.Topic(),
+				payload:  m.Payload(),
+				qos:      m.Qos(),
+				retained: m.Retained(),
+			})
+		})
 
 	// set logging
 	// this is done globally for all mqtt v3 connection since it cannot be done per client
@@ -40,6 +50,11 @@ func CreateV3(cfg Config, statistics Statistics) (client *ClientV3) {
 		mqtt.DEBUG = log.New(os.Stdout, "MqttClientV3 Debug: ", log.LstdFlags)
 	}
 
+	// configure tls
+	if tlsConfig := cfg.TlsConfig(); tlsConfig != nil {
+		client.cliOpts.SetTLSConfig(tlsConfig)
+	}
+
 	// configure login
 	if user := cfg.User(); len(user) > 0 {
 		client.cliOpts.SetUsername(user)
@@ -57,11 +72,13 @@ func CreateV3(cfg Config, statistics Statistics) (client *ClientV3) {
 func (c *ClientV3) Run() {
 	c.mc = mqtt.NewClient(c.cliOpts)
 	c.mc.Connect()
+	c.runPeriodicPublishes(c.publish)
 }
 
 func (c *ClientV3) onConnectionUp() func(client mqtt.Client) {
 	return func(client mqtt.Client) {
 		log.Printf("mqttClientV3[%s]: connection is up", c.cfg.Name())
+		c.metrics.MqttConnectionState(c.Name(), true)
 		// publish online
 		if c.AvailabilityEnabled() {
 			go func() {
@@ -70,23 +87,67 @@ func (c *ClientV3) onConnectionUp() func(client mqtt.Client) {
 		}
 
 		// subscribe topics
-		c.subscriptionsMutex.RLock()
-		defer c.subscriptionsMutex.RUnlock()
-		for _, s := range c.subscriptions {
-			sub := s
-			if token := c.mc.Subscribe(
-				s.subscribeTopic,
-				c.cfg.Qos(),
-				func(c mqtt.Client, m mqtt.Message) {
-					sub.messageHandler(Message{
-						topic:   m.Topic(),
-						payload: m.Payload(),
-					})
-				},
-			); token.Wait() && token.Error() != nil {
-				log.Printf("mqttClientV3[%s]: failed to subscribe: %s", c.cfg.Name(), token.Error())
-			}
+		for _, topic := range c.topics() {
+			c.subscribe(client, topic)
 		}
+
+		// publish periodic messages after subscribing, e.g. Venus OS publishes all values on the first keepalive
+		go c.publishPeriodicPublishes(c.publish)
+	}
+}
+
+// publish sends a non-retained message, nothing is done while the connection is down
+func (c *ClientV3) publish(topic string, payload []byte) {
+	if c.mc == nil || !c.mc.IsConnectionOpen() {
+		return
+	}
+	if token := c.mc.Publish(topic, c.cfg.Qos(), false, payload); token.WaitTimeout(c.cfg.ConnectTimeout()) && token.Error() != nil {
+		log.Printf("mqttClientV3[%s]: error during publish: %s", c.cfg.Name(), token.Error())
+	}
+}
+
+func (c *ClientV3) subscribe(client mqtt.Client, subscribeTopic string) {
+	if token := client.Subscribe(subscribeTopic, c.cfg.Qos(), nil); token.Wait() && token.Error() != nil {
+		log.Printf("mqttClientV3[%s]: failed to subscribe: %s", c.cfg.Name(), token.Error())
+	}
+}
+
+// AddRoute adds a route; when the client is already connected, the topic is subscribed immediately
+func (c *ClientV3) AddRoute(owner, subscribeTopic string, messageHandler MessageHandler) {
+	c.updateSubscriptions(c.addRoute(owner, subscribeTopic, messageHandler, false))
+}
+
+// AddObserverRoute adds a route whose messages are not counted as received, e.g. for the recorder
+func (c *ClientV3) AddObserverRoute(owner, subscribeTopic string, messageHandler MessageHandler) {
+	c.updateSubscriptions(c.addRoute(owner, subscribeTopic, messageHandler, true))
+}
+
+// RemoveRoutes removes all routes of the given owner and unsubscribes topics no longer used
+func (c *ClientV3) RemoveRoutes(owner string) {
+	c.updateSubscriptions(c.removeRoutes(owner))
+}
+
+// updateSubscriptions subscribes new topics before unsubscribing the ones no longer used;
+// when the connection is down, the topics are subscribed by onConnectionUp
+func (c *ClientV3) updateSubscriptions(subscribe, unsubscribe []string) {
+	if c.mc == nil || !c.mc.IsConnectionOpen() {
+		return
+	}
+	for _, topic := range subscribe {
+		c.subscribe(c.mc, topic)
+	}
+	if len(unsubscribe) < 1 {
+		return
+	}
+	if token := c.mc.Unsubscribe(unsubscribe...); token.Wait() && token.Error() != nil {
+		log.Printf("mqttClientV3[%s]: failed to unsubscribe: %s", c.cfg.Name(), token.Error())
+	}
+}
+
+func (c *ClientV3) onConnectionLost() func(client mqtt.Client, err error) {
+	return func(client mqtt.Client, err error) {
+		log.Printf("mqttClientV3[%s]: connection lost: %s", c.cfg.Name(), err)
+		c.metrics.MqttConnectionState(c.Name(), false)
 	}
 }
 
@@ -2,6 +2,7 @@ package mqttClient
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/eclipse/paho.golang/autopaho"
 	"github.com/eclipse/paho.golang/paho"
@@ -21,14 +22,22 @@ type ClientV5 struct {
 	cancel context.CancelFunc
 }
 
-func CreateV5(cfg Config, statistics Statistics) (client *ClientV5) {
+func CreateV5(cfg Config, statistics Statistics, metrics Metrics) (client *ClientV5) {
 	ctx, cancel := context.WithCancel(context.Background())
 	client = &ClientV5{
-		ClientStruct: createClientStruct(cfg, statistics),
-		router:       paho.NewStandardRouter(),
+		ClientStruct: createClientStruct(cfg, statistics, metrics),
 		ctx:          ctx,
 		cancel:       cancel,
 	}
+	// no handlers are registered, hence every message is passed to the default handler exactly once
+	client.router = paho.NewStandardRouterWithDefault(func(p *paho.Publish) {
+		client.handleMessage(Message{
+			topic:    p.Topic,
+			payload:  p.Payload,
+			qos:      p.QoS,
+			retained: p.Retain,
+		})
+	})
 
 	// configure mqtt library
 	client.cliCfg = autopaho.ClientConfig{
@@ -37,6 +46,11 @@ func CreateV5(cfg Config, statistics Statistics) (client *ClientV5) {
 		ConnectRetryDelay: cfg.ConnectRetryDelay(),
 		ConnectTimeout:    cfg.ConnectTimeout(),
 		OnConnectionUp:    client.onConnectionUp(),
+		OnConnectionDown: func() bool {
+			log.Printf("mqttClientV5[%s]: connection is down", cfg.Name())
+			metrics.MqttConnectionState(cfg.Name(), false)
+			return true // keep reconnecting
+		},
 		OnConnectError: func(err error) {
 			log.Printf("mqttClientV5[%s]: connection error: %s", cfg.Name(), err)
 		},
@@ -53,6 +67,11 @@ func CreateV5(cfg Config, statistics Statistics) (client *ClientV5) {
 		client.cliCfg.PahoDebug = logger{prefix: prefix + "paho: "}
 	}
 
+	// configure tls
+	if tlsConfig := cfg.TlsConfig(); tlsConfig != nil {
+		client.cliCfg.TlsCfg = tlsConfig
+	}
+
 	// configure login
 	if user := cfg.User(); len(user) > 0 {
 		client.cliCfg.ConnectUsername = user
@@ -73,30 +92,19 @@ func CreateV5(cfg Config, statistics Statistics) (client *ClientV5) {
 }
 
 func (c *ClientV5) Run() {
-	// add routes to router
-	c.subscriptionsMutex.RLock()
-	defer c.subscriptionsMutex.RUnlock()
-	for _, s := range c.subscriptions {
-		sub := s
-		c.router.RegisterHandler(sub.subscribeTopic, func(p *paho.Publish) {
-			sub.messageHandler(Message{
-				topic:   p.Topic,
-				payload: p.Payload,
-			})
-		})
-	}
-
 	// start connection manager
 	var err error
 	c.cm, err = autopaho.NewConnection(c.ctx, c.cliCfg)
 	if err != nil {
 		panic(err) // never happens
 	}
+	c.runPeriodicPublishes(c.publish)
 }
 
 func (c *ClientV5) onConnectionUp() func(*autopaho.ConnectionManager, *paho.Connack) {
 	return func(cm *autopaho.ConnectionManager, conack *paho.Connack) {
 		log.Printf("mqttClientV5[%s]: connection is up", c.cfg.Name())
+		c.metrics.MqttConnectionState(c.Name(), true)
 		// publish online
 		if c.AvailabilityEnabled() {
 			go func() {
@@ -107,24 +115,77 @@ func (c *ClientV5) onConnectionUp() func(*autopaho.ConnectionManager, *paho.Conn
 			}()
 		}
 		// subscribe topics
-		if len(c.subscriptions) > 0 {
-			if _, err := cm.Subscribe(c.ctx, &paho.Subscribe{
-				Subscriptions: func() (ret []paho.SubscribeOptions) {
-					c.subscriptionsMutex.RLock()
-					defer c.subscriptionsMutex.RUnlock()
-					ret = make([]paho.SubscribeOptions, 0, len(c.subscriptions))
-
-					for _, s := range c.subscriptions {
-						ret = append(ret, paho.SubscribeOptions{
-							Topic: s.subscribeTopic,
-							QoS:   c.cfg.Qos(),
-						})
-					}
-					return
-				}(),
-			}); err != nil {
-				log.Printf("mqttClientV5[%s]: failed to subscribe: %s", c.cfg.Name(), err)
-			}
+		if topics := c.topics(); len(topics) > 0 {
+			c.subscribe(c.ctx, cm, topics)
+		}
+		// publish periodic messages after subscribing, e.g. Venus OS publishes all values on the first keepalive
+		go c.publishPeriodicPublishes(c.publish)
+	}
+}
+
+// publish sends a non-retained message, nothing is done while the connection is down
+func (c *ClientV5) publish(topic string, payload []byte) {
+	if c.cm == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.ctx, c.cfg.ConnectTimeout())
+	defer cancel()
+	if _, err := c.cm.Publish(ctx, &paho.Publish{
+		QoS:     c.cfg.Qos(),
+		Topic:   topic,
+		Payload: payload,
+	}); err != nil && !errors.Is(err, autopaho.ConnectionDownError) {
+		log.Printf("mqttClientV5[%s]: error during publish: %s", c.cfg.Name(), err)
+	}
+}
+
+func (c *ClientV5) subscribe(ctx context.Context, cm *autopaho.ConnectionManager, topics []string) {
+	subscriptions := make([]paho.SubscribeOptions, len(topics))
+	for i, topic := range topics {
+		subscriptions[i] = paho.SubscribeOptions{
+			Topic: topic,
+			QoS:   c.cfg.Qos(),
+		}
+	}
+
+	// when the connection is down, the topics are subscribed by onConnectionUp
+	if _, err := cm.Subscribe(ctx, &paho.Subscribe{Subscriptions: subscriptions}); err != nil &&
+		!errors.Is(err, autopaho.ConnectionDownError) {
+		log.Printf("mqttClientV5[%s]: failed to subscribe: %s", c.cfg.Name(), err)
+	}
+}
+
+// AddRoute adds a route; when the client is already running, the topic is subscribed immediately
+func (c *ClientV5) AddRoute(owner, subscribeTopic string, messageHandler MessageHandler) {
+	c.updateSubscriptions(c.addRoute(owner, subscribeTopic, messageHandler, false))
+}
+
+// AddObserverRoute adds a route whose messages are not counted as received, e.g. for the recorder
+func (c *ClientV5) AddObserverRoute(owner, subscribeTopic string, messageHandler MessageHandler) {
+	c.updateSubscriptions(c.addRoute(owner, subscribeTopic, messageHandler, true))
+}
+
+// RemoveRoutes removes all routes of the given owner and unsubscribes topics no longer used
+func (c *ClientV5) RemoveRoutes(owner string) {
+	c.updateSubscriptions(c.removeRoutes(owner))
+}
+
+// updateSubscriptions subscribes new topics before unsubscribing the ones no longer used
+func (c *ClientV5) updateSubscriptions(subscribe, unsubscribe []string) {
+	if c.cm == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, c.cfg.ConnectTimeout())
+	defer cancel()
+
+	if len(subscribe) > 0 {
+		c.subscribe(ctx, c.cm, subscribe)
+	}
+	if len(unsubscribe) > 0 {
+		if _, err := c.cm.Unsubscribe(ctx, &paho.Unsubscribe{Topics: unsubscribe}); err != nil &&
+			!errors.Is(err, autopaho.ConnectionDownError) {
+			log.Printf("mqttClientV5[%s]: failed to unsubscribe: %s", c.cfg.Name(), err)
 		}
 	}
 }
@@ -0,0 +1,186 @@
+package mqttClient
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testConfig struct {
+	Config
+}
+
+func (c testConfig) Name() string      { return "test" }
+func (c testConfig) LogMessages() bool { return false }
+
+type testStatistics struct {
+	fields []string
+}
+
+func (s *testStatistics) IncrementOne(module, name, field string) {
+	s.fields = append(s.fields, field)
+}
+
+type testMetrics struct {
+	received int
+}
+
+func (m *testMetrics) MqttMessageReceived(client string)                 { m.received += 1 }
+func (m *testMetrics) MqttConnectionState(client string, connected bool) {}
+
+func TestTopicFilterMatches(t *testing.T) {
+	tests := []struct {
+		filter, topic string
+		expected      bool
+	}{
+		{"a/b", "a/b", true},
+		{"a/b", "a/c", false},
+		{"a/+", "a/b", true},
+		{"a/+", "a/b/c", false},
+		{"a/#", "a/b/c", true},
+		{"a/#", "a", true},
+		{"#", "a/b", true},
+		{"#", "$SYS/uptime", false},
+		{"+/b", "$SYS/b", false},
+		{"$SYS/#", "$SYS/uptime", true},
+	}
+
+	for _, tc := range tests {
+		if got := topicFilterMatches(tc.filter, tc.topic); got != tc.expected {
+			t.Errorf("topicFilterMatches(%q, %q): expected %v, got %v", tc.filter, tc.topic, tc.expected, got)
+		}
+	}
+}
+
+func TestTopicFilterCovers(t *testing.T) {
+	tests := []struct {
+		filter, other string
+		expected      bool
+	}{
+		{"#", "a/+", true},
+		{"a/+", "a/b", true},
+		{"a/b", "a/+", false},
+		{"a/+", "a/#", false},
+		{"a/#", "a/+/c", true},
+		{"a/+", "+/b", false},
+		{"#", "$SYS/#", false},
+	}
+
+	for _, tc := range tests {
+		if got := topicFilterCovers(tc.filter, tc.other); got != tc.expected {
+			t.Errorf("topicFilterCovers(%q, %q): expected %v, got %v", tc.filter, tc.other, tc.expected, got)
+		}
+	}
+}
+
+func TestTopicFilterOverlaps(t *testing.T) {
+	tests := []struct {
+		filter, other string
+		expected      bool
+	}{
+		{"a/+", "+/b", true},
+		{"a/+", "b/+", false},
+		{"a/b", "a/b/c", false},
+		{"a", "a/#", true},
+		{"a/+/c", "a/#", true},
+		{"#", "$SYS/#", false},
+		{"$SYS/+", "$SYS/uptime", true},
+	}
+
+	for _, tc := range tests {
+		if got := topicFilterOverlaps(tc.filter, tc.other); got != tc.expected {
+			t.Errorf("topicFilterOverlaps(%q, %q): expected %v, got %v", tc.filter, tc.other, tc.expected, got)
+		}
+	}
+}
+
+func TestTopicFilterMerge(t *testing.T) {
+	tests := []struct {
+		filter, other, expected string
+	}{
+		{"a/+", "+/b", "+/+"},
+		{"a/+/c", "a/b/+", "a/+/+"},
+		{"a", "a/#", "a/#"},
+		{"a/b/c", "+/#", "+/#"},
+	}
+
+	for _, tc := range tests {
+		if got := topicFilterMerge(tc.filter, tc.other); got != tc.expected {
+			t.Errorf("topicFilterMerge(%q, %q): expected %q, got %q", tc.filter, tc.other, tc.expected, got)
+		}
+	}
+}
+
+func TestHandleMessageCountsOnce(t *testing.T) {
+	statistics := &testStatistics{}
+	metrics := &testMetrics{}
+	c := createClientStruct(testConfig{}, statistics, metrics)
+
+	calls := make(map[string]int)
+	handler := func(name string) MessageHandler {
+		return func(Message) { calls[name] += 1 }
+	}
+
+	c.addRoute("c0", "a/+", handler("c0"), false)
+	c.addRoute("c1", "a/b", handler("c1"), false)
+	c.addRoute("c2", "a/b", handler("c2"), false)
+	subscribe, unsubscribe := c.addRoute("%recorder%", "#", handler("recorder"), true)
+
+	// the recorder topic covers all other topics
+	if !reflect.DeepEqual(subscribe, []string{"#"}) || !reflect.DeepEqual(unsubscribe, []string{"a/+"}) {
+		t.Errorf("unexpected subscribe=%v unsubscribe=%v", subscribe, unsubscribe)
+	}
+
+	c.handleMessage(Message{topic: "a/b", payload: []byte("1")})
+	if expected := map[string]int{"c0": 1, "c1": 1, "c2": 1, "recorder": 1}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected calls=%v, got %v", expected, calls)
+	}
+	if metrics.received != 1 {
+		t.Errorf("expected the message to be counted once, got %d", metrics.received)
+	}
+	if expected := []string{"a/+", "a/b"}; !reflect.DeepEqual(statistics.fields, expected) {
+		t.Errorf("expected statistics=%v, got %v", expected, statistics.fields)
+	}
+
+	// messages only received by the recorder are not counted
+	c.handleMessage(Message{topic: "x/y", payload: []byte("1")})
+	if calls["recorder"] != 2 || metrics.received != 1 {
+		t.Errorf("expected recorder calls=2 and received=1, got %d and %d", calls["recorder"], metrics.received)
+	}
+
+	subscribe, unsubscribe = c.removeRoutes("%recorder%")
+	if !reflect.DeepEqual(subscribe, []string{"a/+"}) || !reflect.DeepEqual(unsubscribe, []string{"#"}) {
+		t.Errorf("unexpected subscribe=%v unsubscribe=%v", subscribe, unsubscribe)
+	}
+}
+
+func TestHandleMessageOverlappingTopics(t *testing.T) {
+	metrics := &testMetrics{}
+	c := createClientStruct(testConfig{}, &testStatistics{}, metrics)
+
+	calls := make(map[string]int)
+	c.addRoute("c0", "a/+", func(Message) { calls["c0"] += 1 }, false)
+	subscribe, unsubscribe := c.addRoute("c1", "+/b", func(Message) { calls["c1"] += 1 }, false)
+
+	// overlapping topics are merged, hence the broker delivers every message once
+	if !reflect.DeepEqual(subscribe, []string{"+/+"}) || !reflect.DeepEqual(unsubscribe, []string{"a/+"}) {
+		t.Errorf("unexpected subscribe=%v unsubscribe=%v", subscribe, unsubscribe)
+	}
+
+	// identical messages are real repeats, e.g. an unchanged sensor value, and must not be dropped
+	c.handleMessage(Message{topic: "a/b", payload: []byte("1")})
+	c.handleMessage(Message{topic: "a/b", payload: []byte("1")})
+	if expected := map[string]int{"c0": 2, "c1": 2}; !reflect.DeepEqual(calls, expected) || metrics.received != 2 {
+		t.Errorf("expected calls=%v and received=2, got %v and %d", expected, calls, metrics.received)
+	}
+
+	// messages of the merged topic not matching any route are not passed on
+	c.handleMessage(Message{topic: "x/y", payload: []byte("1")})
+	if calls["c0"] != 2 || calls["c1"] != 2 || metrics.received != 2 {
+		t.Errorf("expected calls and received to be unchanged, got %v and %d", calls, metrics.received)
+	}
+
+	subscribe, unsubscribe = c.removeRoutes("c1")
+	if !reflect.DeepEqual(subscribe, []string{"a/+"}) || !reflect.DeepEqual(unsubscribe, []string{"+/+"}) {
+		t.Errorf("unexpected subscribe=%v unsubscribe=%v", subscribe, unsubscribe)
+	}
+}
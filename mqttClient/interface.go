@@ -1,6 +1,7 @@
 package mqttClient
 
 import (
+	"crypto/tls"
 	"net/url"
 	"time"
 )
@@ -17,27 +18,44 @@ type Config interface {
 	ConnectTimeout() time.Duration
 	AvailabilityTopic() string
 	TopicPrefix() string
+	TlsConfig() *tls.Config
+	PeriodicPublishes() []PeriodicPublishConfig
 	LogDebug() bool
 	LogMessages() bool
 }
 
+type PeriodicPublishConfig interface {
+	Topic() string
+	Payload() string
+	Interval() time.Duration
+}
+
 type Statistics interface {
 	IncrementOne(module, name, field string)
 }
 
+type Metrics interface {
+	MqttMessageReceived(client string)
+	MqttConnectionState(client string, connected bool)
+}
+
 type Client interface {
 	Name() string
 	Run()
 	Shutdown()
 	ReplaceTemplate(template string) string
-	AddRoute(subscribeTopic string, messageHandler MessageHandler)
+	AddRoute(owner, subscribeTopic string, messageHandler MessageHandler)
+	AddObserverRoute(owner, subscribeTopic string, messageHandler MessageHandler)
+	RemoveRoutes(owner string)
 }
 
 type MessageHandler func(Message)
 
 type Message struct {
-	topic   string
-	payload []byte
+	topic    string
+	payload  []byte
+	qos      byte
+	retained bool
 }
 
 func (m Message) Topic() string {
@@ -47,3 +65,11 @@ func (m Message) Topic() string {
 func (m Message) Payload() []byte {
 	return m.payload
 }
+
+func (m Message) Qos() byte {
+	return m.qos
+}
+
+func (m Message) Retained() bool {
+	return m.retained
+}
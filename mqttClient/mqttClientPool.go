@@ -44,6 +44,13 @@ func (p *ClientPool) RemoveClient(client Client) {
 	delete(p.clients, client.Name())
 }
 
+func (p *ClientPool) GetClient(name string) (client Client, ok bool) {
+	p.clientsMutex.RLock()
+	defer p.clientsMutex.RUnlock()
+	client, ok = p.clients[name]
+	return
+}
+
 func (p *ClientPool) GetClientsByNames(clientNames []string) (clients []Client) {
 	p.clientsMutex.RLock()
 	defer p.clientsMutex.RUnlock()
@@ -0,0 +1,33 @@
+package mqttClient
+
+import (
+	"time"
+)
+
+type publishFunc func(topic string, payload []byte)
+
+// runPeriodicPublishes publishes the configured messages until shutdown, e.g. the keepalive Venus OS needs
+// to continue publishing its values; publish is expected to do nothing while the connection is down
+func (c *ClientStruct) runPeriodicPublishes(publish publishFunc) {
+	for _, p := range c.cfg.PeriodicPublishes() {
+		go func(p PeriodicPublishConfig) {
+			ticker := time.NewTicker(p.Interval())
+			defer ticker.Stop()
+			for {
+				select {
+				case <-c.shutdown:
+					return
+				case <-ticker.C:
+					publish(c.ReplaceTemplate(p.Topic()), []byte(p.Payload()))
+				}
+			}
+		}(p)
+	}
+}
+
+// publishPeriodicPublishes publishes all configured messages once, it is called whenever the connection comes up
+func (c *ClientStruct) publishPeriodicPublishes(publish publishFunc) {
+	for _, p := range c.cfg.PeriodicPublishes() {
+		publish(c.ReplaceTemplate(p.Topic()), []byte(p.Payload()))
+	}
+}
@@ -13,14 +13,15 @@ func (c *ClientStruct) AvailabilityEnabled() bool {
 }
 
 func (c *ClientStruct) GetAvailabilityTopic() string {
-	return replaceTemplate(c.cfg.AvailabilityTopic(), c.cfg)
+	return ReplaceTemplate(c.cfg.AvailabilityTopic(), c.cfg)
 }
 
 func (c *ClientStruct) ReplaceTemplate(template string) string {
-	return replaceTemplate(template, c.cfg)
+	return ReplaceTemplate(template, c.cfg)
 }
 
-func replaceTemplate(template string, cfg Config) (r string) {
+// ReplaceTemplate replaces the %Prefix% and %ClientId% placeholders by the values of the given client configuration
+func ReplaceTemplate(template string, cfg Config) (r string) {
 	r = strings.Replace(template, "%Prefix%", cfg.TopicPrefix(), 1)
 	r = strings.Replace(r, "%ClientId%", cfg.ClientId(), 1)
 	return
@@ -0,0 +1,103 @@
+package mqttClient
+
+import (
+	"strings"
+)
+
+// topicFilterMatches returns true if the given topic name matches the subscribe topic filter,
+// e.g. the filter 'a/+/c/#' matches the topic 'a/b/c/d/e'
+func topicFilterMatches(filter, topic string) bool {
+	// wildcards at the first level do not match topics starting with $, e.g. $SYS
+	if strings.HasPrefix(topic, "$") && (strings.HasPrefix(filter, "+") || strings.HasPrefix(filter, "#")) {
+		return false
+	}
+
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+	for i, f := range filterLevels {
+		if f == "#" {
+			// also matches the parent level, e.g. 'a/#' matches 'a'
+			return true
+		}
+		if i >= len(topicLevels) {
+			return false
+		}
+		if f != "+" && f != topicLevels[i] {
+			return false
+		}
+	}
+	return len(filterLevels) == len(topicLevels)
+}
+
+// topicFilterCovers returns true if every topic matched by the other filter is matched by the filter as well,
+// e.g. '#' covers 'a/+' and 'a/+' covers 'a/b'
+func topicFilterCovers(filter, other string) bool {
+	if strings.HasPrefix(other, "$") && (strings.HasPrefix(filter, "+") || strings.HasPrefix(filter, "#")) {
+		return false
+	}
+
+	filterLevels := strings.Split(filter, "/")
+	otherLevels := strings.Split(other, "/")
+	for i, f := range filterLevels {
+		if f == "#" {
+			return true
+		}
+		if i >= len(otherLevels) || otherLevels[i] == "#" {
+			return false
+		}
+		if f != "+" && f != otherLevels[i] {
+			return false
+		}
+	}
+	return len(filterLevels) == len(otherLevels)
+}
+
+// topicFilterOverlaps returns true if at least one topic is matched by both filters, e.g. 'a/+' and '+/b' overlap
+func topicFilterOverlaps(filter, other string) bool {
+	if strings.HasPrefix(filter, "$") != strings.HasPrefix(other, "$") {
+		// wildcards at the first level do not match topics starting with $
+		return false
+	}
+
+	filterLevels := strings.Split(filter, "/")
+	otherLevels := strings.Split(other, "/")
+	for i := 0; i < len(filterLevels) || i < len(otherLevels); i++ {
+		if i >= len(filterLevels) {
+			// e.g. 'a/#' matches 'a'
+			return otherLevels[i] == "#"
+		}
+		if i >= len(otherLevels) {
+			return filterLevels[i] == "#"
+		}
+		f, o := filterLevels[i], otherLevels[i]
+		if f == "#" || o == "#" {
+			return true
+		}
+		if f != "+" && o != "+" && f != o {
+			return false
+		}
+	}
+	return true
+}
+
+// topicFilterMerge returns a filter covering both filters, e.g. '+/+' for 'a/+' and '+/b'
+func topicFilterMerge(filter, other string) string {
+	filterLevels := strings.Split(filter, "/")
+	otherLevels := strings.Split(other, "/")
+	merged := make([]string, 0, len(filterLevels))
+	for i := 0; ; i++ {
+		if i >= len(filterLevels) && i >= len(otherLevels) {
+			break
+		}
+		if i >= len(filterLevels) || i >= len(otherLevels) || filterLevels[i] == "#" || otherLevels[i] == "#" {
+			merged = append(merged, "#")
+			break
+		}
+		if filterLevels[i] == otherLevels[i] {
+			merged = append(merged, filterLevels[i])
+		} else {
+			merged = append(merged, "+")
+		}
+	}
+	return strings.Join(merged, "/")
+}
@@ -0,0 +1,163 @@
+package record
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	jsoniter "github.com/json-iterator/go"
+	"io"
+	"time"
+	"unicode/utf8"
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// maxLineLength limits the size of a single record when reading
+const maxLineLength = 16 * 1024 * 1024
+
+// Record is a mqtt message as stored in a record file.
+// A record file contains one json object per line, e.g.
+// {"time":"2026-01-02T03:04:05.678Z","client":"local","topic":"tele/dev/STATE","payload":"{\"POWER\":\"ON\"}"}
+// Payloads which are not valid utf-8 are stored base64 encoded in the payloadBase64 field instead.
+type Record struct {
+	Time    time.Time
+	Client  string
+	Topic   string
+	Qos     byte
+	Retain  bool
+	Payload []byte
+}
+
+type recordJson struct {
+	Time          time.Time `json:"time"`
+	Client        string    `json:"client,omitempty"`
+	Topic         string    `json:"topic"`
+	Qos           byte      `json:"qos,omitempty"`
+	Retain        bool      `json:"retain,omitempty"`
+	Payload       *string   `json:"payload,omitempty"`
+	PayloadBase64 []byte    `json:"payloadBase64,omitempty"`
+}
+
+func (r Record) MarshalJSON() ([]byte, error) {
+	j := recordJson{
+		Time:   r.Time,
+		Client: r.Client,
+		Topic:  r.Topic,
+		Qos:    r.Qos,
+		Retain: r.Retain,
+	}
+	if utf8.Valid(r.Payload) {
+		payload := string(r.Payload)
+		j.Payload = &payload
+	} else {
+		j.PayloadBase64 = r.Payload
+	}
+	return json.Marshal(j)
+}
+
+func (r *Record) UnmarshalJSON(b []byte) error {
+	var j recordJson
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	if j.Time.IsZero() {
+		return errors.New("time is missing")
+	}
+	if len(j.Topic) < 1 {
+		return errors.New("topic is missing")
+	}
+
+	*r = Record{
+		Time:   j.Time,
+		Client: j.Client,
+		Topic:  j.Topic,
+		Qos:    j.Qos,
+		Retain: j.Retain,
+	}
+	if j.Payload != nil {
+		r.Payload = []byte(*j.Payload)
+	} else if j.PayloadBase64 != nil {
+		r.Payload = j.PayloadBase64
+	} else {
+		return errors.New("payload and payloadBase64 are missing")
+	}
+	return nil
+}
+
+// Reader reads the records of a plain or gzip compressed record file
+type Reader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decompress: %s", err)
+		}
+		r = gz
+	} else {
+		r = br
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineLength)
+	return &Reader{scanner: scanner}, nil
+}
+
+// InvalidRecordError is returned by Read for a line that cannot be decoded; reading continues with the next line
+type InvalidRecordError struct {
+	Line int
+	Err  error
+}
+
+func (e *InvalidRecordError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+func (e *InvalidRecordError) Unwrap() error {
+	return e.Err
+}
+
+// Read returns the next record; empty lines are skipped and io.EOF is returned at the end of the file.
+// Errors other than an InvalidRecordError end the file and are returned on every further call,
+// e.g. io.ErrUnexpectedEOF for a truncated gzip stream or bufio.ErrTooLong for a line longer than maxLineLength.
+func (r *Reader) Read() (rec Record, err error) {
+	for r.scanner.Scan() {
+		r.line += 1
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) < 1 {
+			continue
+		}
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return Record{}, &InvalidRecordError{Line: r.line, Err: err}
+		}
+		return rec, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return Record{}, fmt.Errorf("line %d: %w", r.line+1, err)
+	}
+	return Record{}, io.EOF
+}
+
+// Writer writes records one per line
+type Writer struct {
+	w io.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+func (w *Writer) Write(rec Record) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	_, err = w.w.Write(append(b, '\n'))
+	return err
+}
@@ -0,0 +1,138 @@
+package record
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteRead(t *testing.T) {
+	records := []Record{
+		{
+			Time:    time.Date(2026, time.January, 2, 3, 4, 5, 678000000, time.UTC),
+			Client:  "local",
+			Topic:   "tele/dev/STATE",
+			Qos:     1,
+			Retain:  true,
+			Payload: []byte(`{"POWER":"ON"}`),
+		},
+		{
+			Time:    time.Date(2026, time.January, 2, 3, 4, 6, 0, time.UTC),
+			Topic:   "spBv1.0/group/DDATA/node/device",
+			Payload: []byte{0x08, 0xff, 0xfe},
+		},
+		{
+			Time:    time.Date(2026, time.January, 2, 3, 4, 7, 0, time.UTC),
+			Topic:   "tele/dev/LWT",
+			Payload: []byte{},
+		},
+	}
+
+	for _, compressed := range []bool{false, true} {
+		var b bytes.Buffer
+		var out io.Writer = &b
+		var gz *gzip.Writer
+		if compressed {
+			gz = gzip.NewWriter(&b)
+			out = gz
+		}
+
+		w := NewWriter(out)
+		for _, rec := range records {
+			if err := w.Write(rec); err != nil {
+				t.Fatalf("did not expect an error, got %s", err)
+			}
+		}
+		if gz != nil {
+			if err := gz.Close(); err != nil {
+				t.Fatalf("did not expect an error, got %s", err)
+			}
+		} else if !strings.Contains(b.String(), `"payload":"{\"POWER\":\"ON\"}"`) ||
+			!strings.Contains(b.String(), `"payloadBase64":"CP/+"`) {
+			t.Errorf("unexpected output: %s", b.String())
+		}
+
+		r, err := NewReader(&b)
+		if err != nil {
+			t.Fatalf("did not expect an error, got %s", err)
+		}
+		for i, expected := range records {
+			rec, err := r.Read()
+			if err != nil {
+				t.Fatalf("did not expect an error, got %s", err)
+			}
+			if !reflect.DeepEqual(expected, rec) {
+				t.Errorf("compressed=%t, record %d: expected %v, got %v", compressed, i, expected, rec)
+			}
+		}
+		if _, err := r.Read(); err != io.EOF {
+			t.Errorf("expected io.EOF, got %v", err)
+		}
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	input := `{"time":"2026-01-02T03:04:05Z","topic":"a","payload":"x"}
+
+{"time":"2026-01-02T03:04:05Z","payload":"x"}
+`
+	r, err := NewReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("did not expect an error, got %s", err)
+	}
+	if _, err := r.Read(); err != nil {
+		t.Fatalf("did not expect an error, got %s", err)
+	}
+	var invalidRecord *InvalidRecordError
+	if _, err := r.Read(); !errors.As(err, &invalidRecord) || invalidRecord.Line != 3 {
+		t.Errorf("expected an invalid record error on line 3, got %v", err)
+	}
+}
+
+func TestReadTruncated(t *testing.T) {
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	w := NewWriter(gz)
+	for i := 0; i < 100; i++ {
+		rec := Record{Time: time.Date(2026, time.January, 2, 3, 4, i%60, 0, time.UTC), Topic: "a", Payload: []byte("x")}
+		if err := w.Write(rec); err != nil {
+			t.Fatalf("did not expect an error, got %s", err)
+		}
+	}
+	// flushed but not closed, like the file currently written by the recorder: the gzip trailer is missing
+	if err := gz.Flush(); err != nil {
+		t.Fatalf("did not expect an error, got %s", err)
+	}
+
+	r, err := NewReader(&b)
+	if err != nil {
+		t.Fatalf("did not expect an error, got %s", err)
+	}
+	count := 0
+	for {
+		_, err := r.Read()
+		if err == nil {
+			count += 1
+			continue
+		}
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+		}
+		var invalidRecord *InvalidRecordError
+		if errors.As(err, &invalidRecord) {
+			t.Errorf("expected the truncation not to be an InvalidRecordError")
+		}
+		break
+	}
+	if count != 100 {
+		t.Errorf("expected 100 records, got %d", count)
+	}
+	if _, err := r.Read(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected io.ErrUnexpectedEOF on every further call, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"github.com/koestler/go-mqtt-to-influx/v2/config"
+	"github.com/koestler/go-mqtt-to-influx/v2/mqttClient"
+	"github.com/koestler/go-mqtt-to-influx/v2/record"
+	"github.com/koestler/go-mqtt-to-influx/v2/recorder"
+	"log"
+	"time"
+)
+
+// recorderRouteOwner is used to add and remove the routes of the recorders;
+// it cannot collide with a converter name since those must not contain a %
+const recorderRouteOwner = "%recorder%"
+
+// startRecorder starts a recorder for the given mqtt client when it is enabled in the configuration
+// and adds the routes for the topics to record
+func startRecorder(
+	cfg *config.Config,
+	mqttClientConfig config.MqttClientConfig,
+	mqttClientInstance mqttClient.Client,
+	recorderPoolInstance *recorder.Pool,
+) {
+	recorderConfig := mqttClientConfig.Recorder()
+	if !recorderConfig.Enabled() {
+		return
+	}
+
+	if cfg.LogWorkerStart() {
+		log.Printf("recorder[%s]: start: directory='%s', topics=%v",
+			mqttClientConfig.Name(), recorderConfig.Directory(), recorderConfig.Topics(),
+		)
+	}
+
+	recorderInstance, err := recorder.Run(recorderConfig, mqttClientConfig.Name())
+	if err != nil {
+		log.Printf("recorder[%s]: cannot start: %s", mqttClientConfig.Name(), err)
+		return
+	}
+	recorderPoolInstance.AddRecorder(recorderInstance)
+
+	for _, topic := range recorderConfig.Topics() {
+		// the recorder only observes messages, they are counted by the converters receiving them
+		mqttClientInstance.AddObserverRoute(
+			recorderRouteOwner,
+			mqttClientInstance.ReplaceTemplate(topic),
+			func(message mqttClient.Message) {
+				recorderInstance.Record(record.Record{
+					Time:    time.Now(),
+					Client:  mqttClientConfig.Name(),
+					Topic:   message.Topic(),
+					Qos:     message.Qos(),
+					Retain:  message.Retained(),
+					Payload: message.Payload(),
+				})
+			},
+		)
+	}
+}
+
+// stopRecorder shuts down the recorder of the given mqtt client if there is one
+func stopRecorder(name string, recorderPoolInstance *recorder.Pool) {
+	if recorderInstance, ok := recorderPoolInstance.GetRecorder(name); ok {
+		recorderPoolInstance.RemoveRecorder(recorderInstance)
+		recorderInstance.Shutdown()
+	}
+}
@@ -0,0 +1,46 @@
+package recorder
+
+import "sync"
+
+type Pool struct {
+	recorders      map[string]*Recorder
+	recordersMutex sync.RWMutex
+}
+
+func RunPool() *Pool {
+	return &Pool{
+		recorders: make(map[string]*Recorder),
+	}
+}
+
+func (p *Pool) Shutdown() {
+	// remove all recorders from list
+	p.recordersMutex.Lock()
+	recorders := p.recorders
+	p.recorders = make(map[string]*Recorder)
+	p.recordersMutex.Unlock()
+
+	// shutdown recorders
+	for _, r := range recorders {
+		r.Shutdown()
+	}
+}
+
+func (p *Pool) AddRecorder(recorder *Recorder) {
+	p.recordersMutex.Lock()
+	defer p.recordersMutex.Unlock()
+	p.recorders[recorder.Name()] = recorder
+}
+
+func (p *Pool) RemoveRecorder(recorder *Recorder) {
+	p.recordersMutex.Lock()
+	defer p.recordersMutex.Unlock()
+	delete(p.recorders, recorder.Name())
+}
+
+func (p *Pool) GetRecorder(name string) (recorder *Recorder, ok bool) {
+	p.recordersMutex.RLock()
+	defer p.recordersMutex.RUnlock()
+	recorder, ok = p.recorders[name]
+	return
+}
@@ -0,0 +1,205 @@
+package recorder
+
+import (
+	"compress/gzip"
+	"fmt"
+	"github.com/koestler/go-mqtt-to-influx/v2/record"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Config interface {
+	Topics() []string
+	Directory() string
+	MaxFileSize() int64
+	MaxFileAge() time.Duration
+	MaxAge() time.Duration
+}
+
+// flushInterval defines how often the compressed data is written to the file,
+// the most recent messages might get lost on a crash
+const flushInterval = 5 * time.Second
+
+// cleanupInterval defines how often files older than MaxAge are searched and deleted
+const cleanupInterval = time.Minute
+
+const fileSuffix = ".jsonl.gz"
+
+// Recorder appends the messages received by one mqtt client to gzip compressed record files in the Directory.
+// A new file is started when the current one exceeds MaxFileSize (uncompressed) or MaxFileAge.
+// Files older than MaxAge are deleted.
+type Recorder struct {
+	config Config
+	name   string
+
+	mutex       sync.Mutex
+	stopped     bool
+	file        *os.File
+	gz          *gzip.Writer
+	writer      *record.Writer
+	fileSize    int64
+	fileCreated time.Time
+
+	shutdown chan struct{}
+	closed   chan struct{}
+}
+
+// countingWriter counts the uncompressed bytes written to the current file
+type countingWriter struct {
+	w io.Writer
+	n *int64
+}
+
+func (c countingWriter) Write(p []byte) (n int, err error) {
+	n, err = c.w.Write(p)
+	*c.n += int64(n)
+	return
+}
+
+// Run creates the directory if needed and starts a recorder for the mqtt client with the given name
+func Run(config Config, name string) (*Recorder, error) {
+	if err := os.MkdirAll(config.Directory(), 0755); err != nil {
+		return nil, fmt.Errorf("cannot create directory: %s", err)
+	}
+
+	r := &Recorder{
+		config:   config,
+		name:     name,
+		shutdown: make(chan struct{}),
+		closed:   make(chan struct{}),
+	}
+
+	r.deleteExpired()
+	go r.worker()
+
+	return r, nil
+}
+
+func (r *Recorder) Name() string {
+	return r.name
+}
+
+func (r *Recorder) Shutdown() {
+	close(r.shutdown)
+	<-r.closed
+	log.Printf("recorder[%s]: closed", r.name)
+}
+
+func (r *Recorder) Record(rec record.Record) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.stopped {
+		return
+	}
+
+	if r.file != nil && (r.fileSize >= r.config.MaxFileSize() || time.Since(r.fileCreated) >= r.config.MaxFileAge()) {
+		r.closeFile()
+	}
+
+	if r.file == nil {
+		if err := r.openFile(); err != nil {
+			log.Printf("recorder[%s]: cannot open file: %s", r.name, err)
+			return
+		}
+	}
+
+	if err := r.writer.Write(rec); err != nil {
+		log.Printf("recorder[%s]: cannot write: %s", r.name, err)
+	}
+}
+
+func (r *Recorder) worker() {
+	defer close(r.closed)
+
+	flushTicker := time.NewTicker(flushInterval)
+	defer flushTicker.Stop()
+	cleanupTicker := time.NewTicker(cleanupInterval)
+	defer cleanupTicker.Stop()
+
+	for {
+		select {
+		case <-r.shutdown:
+			r.mutex.Lock()
+			r.closeFile()
+			r.stopped = true
+			r.mutex.Unlock()
+			return
+		case <-flushTicker.C:
+			r.mutex.Lock()
+			if r.file != nil {
+				if time.Since(r.fileCreated) >= r.config.MaxFileAge() {
+					r.closeFile()
+				} else if err := r.gz.Flush(); err != nil {
+					log.Printf("recorder[%s]: cannot flush: %s", r.name, err)
+				}
+			}
+			r.mutex.Unlock()
+		case <-cleanupTicker.C:
+			r.deleteExpired()
+		}
+	}
+}
+
+func (r *Recorder) openFile() error {
+	now := time.Now()
+	path := filepath.Join(r.config.Directory(), r.name+"-"+now.UTC().Format("20060102T150405.000Z")+fileSuffix)
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	r.file = file
+	r.gz = gzip.NewWriter(file)
+	r.fileSize = 0
+	r.writer = record.NewWriter(countingWriter{w: r.gz, n: &r.fileSize})
+	r.fileCreated = now
+
+	return nil
+}
+
+func (r *Recorder) closeFile() {
+	if r.file == nil {
+		return
+	}
+
+	if err := r.gz.Close(); err != nil {
+		log.Printf("recorder[%s]: cannot write: %s", r.name, err)
+	}
+	if err := r.file.Close(); err != nil {
+		log.Printf("recorder[%s]: cannot close file: %s", r.name, err)
+	}
+
+	r.file = nil
+	r.gz = nil
+	r.writer = nil
+}
+
+// deleteExpired removes the files of this recorder which were last modified before MaxAge
+func (r *Recorder) deleteExpired() {
+	entries, err := os.ReadDir(r.config.Directory())
+	if err != nil {
+		log.Printf("recorder[%s]: cannot read directory: %s", r.name, err)
+		return
+	}
+
+	threshold := time.Now().Add(-r.config.MaxAge())
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), r.name+"-") || !strings.HasSuffix(entry.Name(), fileSuffix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(threshold) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(r.config.Directory(), entry.Name())); err != nil {
+			log.Printf("recorder[%s]: cannot delete expired file: %s", r.name, err)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package recorder
+
+import (
+	"github.com/koestler/go-mqtt-to-influx/v2/record"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	directory string
+}
+
+func (c testConfig) Topics() []string {
+	return []string{"#"}
+}
+
+func (c testConfig) Directory() string {
+	return c.directory
+}
+
+func (c testConfig) MaxFileSize() int64 {
+	return 100
+}
+
+func (c testConfig) MaxFileAge() time.Duration {
+	return time.Hour
+}
+
+func (c testConfig) MaxAge() time.Duration {
+	return time.Hour
+}
+
+func readAll(t *testing.T, path string) (records []record.Record) {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close() //nolint:errcheck
+
+	reader, err := record.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			return
+		} else if err != nil {
+			t.Fatalf("did not expect an error, got %s", err)
+		}
+		records = append(records, rec)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	dir := t.TempDir()
+
+	// an expired file of this recorder and a file of another recorder
+	expired := filepath.Join(dir, "local-20200101T000000.000Z"+fileSuffix)
+	other := filepath.Join(dir, "other-20200101T000000.000Z"+fileSuffix)
+	for _, path := range []string{expired, other} {
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		old := time.Now().Add(-2 * time.Hour)
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, err := Run(testConfig{directory: dir}, "local")
+	if err != nil {
+		t.Fatalf("did not expect an error, got %s", err)
+	}
+
+	if _, err := os.Stat(expired); !os.IsNotExist(err) {
+		t.Error("expected the expired file to be deleted")
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Error("expected the file of the other recorder to be kept")
+	}
+
+	// every record is bigger than MaxFileSize, a new file is started for every record
+	recorded := []record.Record{
+		{Time: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), Client: "local", Topic: "tele/dev0/STATE", Qos: 1, Payload: []byte(`{"POWER":"ON","Dimmer":50,"Wifi":{"RSSI":80}}`)},
+		{Time: time.Date(2026, 1, 2, 3, 4, 6, 0, time.UTC), Client: "local", Topic: "tele/dev0/LWT", Retain: true, Payload: []byte("Online")},
+	}
+	for _, rec := range recorded {
+		r.Record(rec)
+		time.Sleep(2 * time.Millisecond)
+	}
+	r.Shutdown()
+
+	// records after the shutdown are ignored
+	r.Record(recorded[0])
+
+	files, err := filepath.Glob(filepath.Join(dir, "local-*"+fileSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %v", files)
+	}
+
+	for i, file := range files {
+		records := readAll(t, file)
+		if len(records) != 1 {
+			t.Fatalf("expected 1 record in %s, got %d", file, len(records))
+		}
+		if records[0].Topic != recorded[i].Topic || records[0].Retain != recorded[i].Retain ||
+			records[0].Qos != recorded[i].Qos || string(records[0].Payload) != string(recorded[i].Payload) {
+			t.Errorf("expected %v, got %v", recorded[i], records[0])
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"github.com/fsnotify/fsnotify"
+	"github.com/koestler/go-mqtt-to-influx/v2/config"
+	"github.com/koestler/go-mqtt-to-influx/v2/converter"
+	"github.com/koestler/go-mqtt-to-influx/v2/influxClient"
+	LocalDb "github.com/koestler/go-mqtt-to-influx/v2/localDb"
+	"github.com/koestler/go-mqtt-to-influx/v2/metrics"
+	"github.com/koestler/go-mqtt-to-influx/v2/mqttClient"
+	"github.com/koestler/go-mqtt-to-influx/v2/recorder"
+	"github.com/koestler/go-mqtt-to-influx/v2/statistics"
+	"log"
+	"path/filepath"
+	"time"
+)
+
+// reloadConfig reads the configuration file again and applies the differences to the running configuration:
+// mqtt clients including their recorders, influx clients and converters which were added, removed or changed are started, stopped or
+// recreated while unchanged mqtt clients stay connected and unchanged influx clients keep their buffers.
+// Changes of the HttpServer, LocalDb and Statistics sections are only applied on the next restart.
+// When the new configuration is invalid, the running configuration is kept.
+func reloadConfig(
+	cmdOptions CmdOptions,
+	cmdName string,
+	running *config.Config,
+	localDbInstance LocalDb.LocalDb,
+	statisticsInstance statistics.Statistics,
+	metricsInstance *metrics.Metrics,
+	errorHistoryInstance *converter.ErrorHistory,
+	mqttClientPoolInstance *mqttClient.ClientPool,
+	influxClientPoolInstance *influxClient.ClientPool,
+	recorderPoolInstance *recorder.Pool,
+) *config.Config {
+	log.Printf("main: reload configuration from '%s'", cmdOptions.Config)
+
+	cfg, err := config.ReadConfigFile(cmdName, string(cmdOptions.Config))
+	if len(err) > 0 {
+		for _, e := range err {
+			log.Printf("config: error: %v", e)
+		}
+		log.Print("main: reload failed; keep running configuration")
+		return running
+	}
+
+	if cfg.LogConfig() {
+		if err := cfg.PrintConfig(); err != nil {
+			log.Printf("config: cannot print: %s", err)
+		}
+	}
+
+	if !cfg.HttpServer().Equal(running.HttpServer()) {
+		log.Print("main: changes of the HttpServer section are applied on the next restart")
+	}
+	if !cfg.LocalDb().Equal(running.LocalDb()) {
+		log.Print("main: changes of the LocalDb section are applied on the next restart")
+	}
+	if !cfg.Statistics().Equal(running.Statistics()) {
+		log.Print("main: changes of the Statistics section are applied on the next restart")
+	}
+
+	// influx clients
+	// the auxiliary tags are handled by the influx clients, when they change, all clients are recreated
+	auxiliaryTagsChanged := !cfg.InfluxAuxiliaryTagsEqual(*running)
+	newInfluxClients := configsByName(cfg.InfluxClients())
+	for _, oc := range running.InfluxClients() {
+		if _, ok := newInfluxClients[oc.Name()]; !ok {
+			if client, ok := influxClientPoolInstance.GetClient(oc.Name()); ok {
+				log.Printf("influxClient[%s]: removed from configuration; shutdown", oc.Name())
+				influxClientPoolInstance.RemoveClient(client)
+				client.Shutdown()
+			}
+		}
+	}
+
+	runningInfluxClients := configsByName(running.InfluxClients())
+	var failedInfluxClients []string
+	for _, nc := range cfg.InfluxClients() {
+		if oc, ok := runningInfluxClients[nc.Name()]; ok && oc.Equal(nc) && !auxiliaryTagsChanged {
+			continue
+		}
+
+		client, err := startInfluxClient(&cfg, nc, localDbInstance, statisticsInstance, metricsInstance)
+		if err != nil {
+			log.Printf("main: %s", err)
+			failedInfluxClients = append(failedInfluxClients, nc.Name())
+			continue
+		}
+
+		// replace the old client before it is shut down to not lose any points in between
+		oldClient, hasOldClient := influxClientPoolInstance.GetClient(nc.Name())
+		influxClientPoolInstance.AddClient(client)
+		if hasOldClient {
+			log.Printf("influxClient[%s]: configuration changed; shutdown old client", nc.Name())
+			oldClient.Shutdown()
+		}
+	}
+
+	// the old clients keep running; keep their configuration such that the next reload retries
+	for _, name := range failedInfluxClients {
+		cfg.KeepRunningInfluxClient(*running, name)
+	}
+
+	// mqtt clients
+	// removed and changed clients are shut down before new ones are created since they may use the same ClientId
+	newMqttClientConfigs := configsByName(cfg.MqttClients())
+	for _, oc := range running.MqttClients() {
+		if nc, ok := newMqttClientConfigs[oc.Name()]; ok && oc.Equal(nc) {
+			continue
+		}
+		if client, ok := mqttClientPoolInstance.GetClient(oc.Name()); ok {
+			log.Printf("mqttClient[%s]: removed or changed in configuration; shutdown", oc.Name())
+			mqttClientPoolInstance.RemoveClient(client)
+			client.Shutdown()
+		}
+		stopRecorder(oc.Name(), recorderPoolInstance)
+	}
+
+	// remove the routes of removed and changed converters from the remaining mqtt clients
+	newConverterConfigs := configsByName(cfg.Converters())
+	for _, oc := range running.Converters() {
+		nc, ok := newConverterConfigs[oc.Name()]
+		if ok && oc.Equal(nc) {
+			continue
+		}
+		for _, client := range mqttClientPoolInstance.GetClientsByNames(nil) {
+			client.RemoveRoutes(oc.Name())
+		}
+		if !ok {
+			errorHistoryInstance.AddConverter(oc.Name(), 0)
+		}
+	}
+
+	runningMqttClientConfigs := configsByName(running.MqttClients())
+	createdMqttClients := make(map[string]mqttClient.Client)
+	for _, nc := range cfg.MqttClients() {
+		if oc, ok := runningMqttClientConfigs[nc.Name()]; ok && oc.Equal(nc) {
+			continue
+		}
+		client := createMqttClient(&cfg, nc, statisticsInstance, metricsInstance)
+		mqttClientPoolInstance.AddClient(client)
+		startRecorder(&cfg, nc, client, recorderPoolInstance)
+		createdMqttClients[nc.Name()] = client
+	}
+
+	// add the routes of added and changed converters to all their mqtt clients
+	// and the routes of unchanged converters to the newly created mqtt clients
+	runningConverterConfigs := configsByName(running.Converters())
+	countCreated := 0
+	for _, nc := range cfg.Converters() {
+		mqttClients := mqttClientPoolInstance.GetClientsByNames(nc.MqttClients())
+		if oc, ok := runningConverterConfigs[nc.Name()]; ok && oc.Equal(nc) {
+			created := make([]mqttClient.Client, 0, len(mqttClients))
+			for _, client := range mqttClients {
+				if _, ok := createdMqttClients[client.Name()]; ok {
+					created = append(created, client)
+				}
+			}
+			mqttClients = created
+		}
+
+		countCreated += createConverter(
+			&cfg,
+			nc,
+			mqttClients,
+			statisticsInstance,
+			metricsInstance,
+			errorHistoryInstance,
+			localDbInstance,
+			influxClientPoolInstance,
+		)
+	}
+
+	// start / connect the new mqtt clients
+	for _, client := range createdMqttClients {
+		client.Run()
+	}
+
+	log.Printf("main: reload completed; %d mqtt clients created, %d converter routes created",
+		len(createdMqttClients), countCreated,
+	)
+
+	return &cfg
+}
+
+func configsByName[T interface{ Name() string }](list []T) map[string]T {
+	ret := make(map[string]T, len(list))
+	for _, c := range list {
+		ret[c.Name()] = c
+	}
+	return ret
+}
+
+// watchConfigFile sends to the returned channel whenever the given file is written or replaced.
+// The directory is watched instead of the file itself since many editors replace the file when saving.
+func watchConfigFile(path string) (<-chan struct{}, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	path = filepath.Clean(path)
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		_ = watcher.Close()
+		return nil, err
+	}
+
+	changed := make(chan struct{}, 1)
+	go func() {
+		// wait until no more events are received to only reload once per save
+		const debounce = 500 * time.Millisecond
+		timer := time.NewTimer(debounce)
+		timer.Stop()
+
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) == path && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+					timer.Reset(debounce)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("main: config file watcher: %s", err)
+			case <-timer.C:
+				select {
+				case changed <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	return changed, nil
+}
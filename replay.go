@@ -0,0 +1,320 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jessevdk/go-flags"
+	"github.com/koestler/go-mqtt-to-influx/v2/config"
+	"github.com/koestler/go-mqtt-to-influx/v2/converter"
+	"github.com/koestler/go-mqtt-to-influx/v2/influxClient"
+	LocalDb "github.com/koestler/go-mqtt-to-influx/v2/localDb"
+	"github.com/koestler/go-mqtt-to-influx/v2/metrics"
+	"github.com/koestler/go-mqtt-to-influx/v2/mqttClient"
+	"github.com/koestler/go-mqtt-to-influx/v2/record"
+	"github.com/koestler/go-mqtt-to-influx/v2/statistics"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"time"
+)
+
+// replayInput is a message received in the past; it satisfies converter.Input and converter.TimedInput
+type replayInput struct {
+	topic      string
+	payload    []byte
+	receivedAt time.Time
+}
+
+func (i replayInput) Topic() string {
+	return i.topic
+}
+
+func (i replayInput) Payload() []byte {
+	return i.payload
+}
+
+func (i replayInput) ReceivedAt() time.Time {
+	return i.receivedAt
+}
+
+type replayTopicMatcher struct {
+	mqttClient string
+	matcher    converter.TopicMatcher
+}
+
+// replayer runs messages received in the past through a converter
+type replayer struct {
+	converterConfig converterConfig
+	handleFunc      converter.HandleFunc
+	topicMatchers   []replayTopicMatcher
+	outputFunc      converter.OutputFunc
+}
+
+// replayOutput writes the points of all replayers to the influx clients or,
+// in dry-run mode, prints them in the line protocol
+type replayOutput struct {
+	cfg             *config.Config
+	dryRun          bool
+	dryRunOutput    io.Writer
+	auxiliaryTags   []influxClient.AuxiliaryTag
+	localDbInstance LocalDb.LocalDb
+	metricsInstance *metrics.Metrics
+	influxClients   map[string]*influxClient.Client
+}
+
+func newReplayOutput(
+	cfg *config.Config,
+	dryRun bool,
+	dryRunOutput io.Writer,
+	localDbInstance LocalDb.LocalDb,
+) *replayOutput {
+	return &replayOutput{
+		cfg:             cfg,
+		dryRun:          dryRun,
+		dryRunOutput:    dryRunOutput,
+		auxiliaryTags:   influxAuxiliaryTags(cfg),
+		localDbInstance: localDbInstance,
+		metricsInstance: metrics.Run(localDbInstance),
+		influxClients:   make(map[string]*influxClient.Client),
+	}
+}
+
+// outputFunc returns a function writing to the given influx clients or to all influx clients when none are given;
+// the influx clients are started when they are used for the first time
+func (o *replayOutput) outputFunc(influxClientNames []string) (converter.OutputFunc, error) {
+	influxClientConfigs := configsByName(o.cfg.InfluxClients())
+	var selected []config.InfluxClientConfig
+	if len(influxClientNames) < 1 {
+		selected = o.cfg.InfluxClients()
+	} else {
+		for _, name := range influxClientNames {
+			icc, ok := influxClientConfigs[name]
+			if !ok {
+				return nil, fmt.Errorf("influx client='%s' is not defined", name)
+			}
+			selected = append(selected, icc)
+		}
+	}
+
+	if o.dryRun {
+		return func(output converter.Output) {
+			// print lines identical for multiple influx clients only once
+			printed := make(map[string]struct{}, len(selected))
+			for _, icc := range selected {
+				line := influxClient.ToLineProtocol(output, o.auxiliaryTags, icc.TimePrecision())
+				if _, ok := printed[line]; !ok {
+					printed[line] = struct{}{}
+					_, _ = fmt.Fprint(o.dryRunOutput, line)
+				}
+			}
+		}, nil
+	}
+
+	clients := make([]*influxClient.Client, 0, len(selected))
+	for _, icc := range selected {
+		client, ok := o.influxClients[icc.Name()]
+		if !ok {
+			var err error
+			client, err = startInfluxClient(o.cfg, icc, o.localDbInstance, &statistics.DisabledStatistics{}, o.metricsInstance)
+			if err != nil {
+				return nil, err
+			}
+			o.influxClients[icc.Name()] = client
+		}
+		clients = append(clients, client)
+	}
+
+	return func(output converter.Output) {
+		for _, client := range clients {
+			client.WritePoint(output)
+		}
+	}, nil
+}
+
+// shutdown flushes and closes the influx clients; it can be called multiple times
+func (o *replayOutput) shutdown() {
+	for name, client := range o.influxClients {
+		client.Shutdown()
+		delete(o.influxClients, name)
+	}
+}
+
+// newReplayer sets up the given converter; when no influx clients are given, the InfluxClients of the converter are used
+func newReplayer(
+	cfg *config.Config,
+	converterName string,
+	influxClientNames []string,
+	output *replayOutput,
+) (*replayer, error) {
+	cc, ok := configsByName(cfg.Converters())[converterName]
+	if !ok {
+		return nil, fmt.Errorf("converter='%s' is not defined", converterName)
+	}
+
+	handleFunc, err := converter.GetHandler(cc.Implementation())
+	if err != nil {
+		return nil, fmt.Errorf("converter[%s]: %s", cc.Name(), err)
+	}
+
+	r := &replayer{
+		converterConfig: wrapConverterConfig(cc),
+		handleFunc:      handleFunc,
+	}
+
+	for _, mc := range cfg.MqttClients() {
+		if len(cc.MqttClients()) > 0 && !slices.Contains(cc.MqttClients(), mc.Name()) {
+			continue
+		}
+		for _, mqttTopic := range cc.MqttTopics() {
+			topicMatcher, err := converter.CreateTopicMatcher(
+				mqttTopic.ApplyTopicReplace(func(template string) string {
+					return mqttClient.ReplaceTemplate(template, wrapMqttClientConfig(mc))
+				}),
+			)
+			if err != nil {
+				return nil, fmt.Errorf("converter[%s]mqtt[%s]: %s", cc.Name(), mc.Name(), err)
+			}
+			r.topicMatchers = append(r.topicMatchers, replayTopicMatcher{mqttClient: mc.Name(), matcher: topicMatcher})
+		}
+	}
+
+	if len(influxClientNames) < 1 {
+		influxClientNames = cc.InfluxClients()
+	}
+	if r.outputFunc, err = output.outputFunc(influxClientNames); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// match returns the first topic matcher matching the given topic;
+// the topics of the given mqtt client are preferred over the ones of other clients
+func (r *replayer) match(mqttClientName, topic string) (converter.TopicMatcher, bool) {
+	for _, preferred := range []bool{true, false} {
+		for _, tm := range r.topicMatchers {
+			if preferred != (tm.mqttClient == mqttClientName) {
+				continue
+			}
+			if _, err := tm.matcher.MatchDevice(topic); err == nil {
+				return tm.matcher, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// handle runs the input through the converter using the topic matcher returned by match
+func (r *replayer) handle(mqttClientName string, input replayInput) error {
+	topicMatcher, ok := r.match(mqttClientName, input.Topic())
+	if !ok {
+		return fmt.Errorf("topic='%s' does not match any MqttTopics of converter='%s'", input.Topic(), r.converterConfig.Name())
+	}
+	return r.handleFunc(r.converterConfig, topicMatcher, input, r.outputFunc)
+}
+
+type ReplayCommand struct {
+	Converters    []string `long:"converter" description:"Only replay through this converter; can be repeated, default all converters"`
+	InfluxClients []string `long:"influx-client" description:"Write to this influx client instead of the InfluxClients of the converters; can be repeated"`
+	DryRun        bool     `long:"dry-run" description:"Print the line protocol instead of writing it"`
+	Args          struct {
+		Files []flags.Filename `positional-arg-name:"file" required:"1" description:"Record files (plain or gzip compressed) containing one json object with time, topic and payload per line"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+// runReplay runs all messages of the given record files through all converters with a matching topic
+// using the time the messages were received at
+func runReplay(cfg *config.Config, cmd ReplayCommand) int {
+	localDbInstance := runLocalDb(cfg)
+	defer localDbInstance.Shutdown()
+
+	output := newReplayOutput(cfg, cmd.DryRun, os.Stdout, localDbInstance)
+	defer output.shutdown()
+
+	converterNames := cmd.Converters
+	if len(converterNames) < 1 {
+		for _, cc := range cfg.Converters() {
+			converterNames = append(converterNames, cc.Name())
+		}
+	}
+
+	replayers := make([]*replayer, 0, len(converterNames))
+	for _, name := range converterNames {
+		r, err := newReplayer(cfg, name, cmd.InfluxClients, output)
+		if err != nil {
+			log.Printf("main: cannot replay: %s", err)
+			return ExitDueToConfig
+		}
+		replayers = append(replayers, r)
+	}
+
+	countRead, countHandled, countFailed, countUnmatched := 0, 0, 0, 0
+	for _, file := range cmd.Args.Files {
+		err := readRecordFile(string(file), func(rec record.Record) {
+			countRead += 1
+			input := replayInput{topic: rec.Topic, payload: rec.Payload, receivedAt: rec.Time}
+
+			matched := false
+			for _, r := range replayers {
+				if _, ok := r.match(rec.Client, rec.Topic); !ok {
+					continue
+				}
+				matched = true
+				if err := r.handle(rec.Client, input); err != nil {
+					log.Printf("%s[%s]: %s", r.converterConfig.Implementation(), r.converterConfig.Name(), err)
+					countFailed += 1
+				} else {
+					countHandled += 1
+				}
+			}
+			if !matched {
+				countUnmatched += 1
+			}
+		})
+		if err != nil {
+			log.Printf("main: cannot replay '%s': %s", file, err)
+			return ExitDueToCmdOptions
+		}
+	}
+
+	output.shutdown()
+
+	log.Printf("main: %d messages read, %d times handled by a converter, %d times failed, %d without matching converter",
+		countRead, countHandled, countFailed, countUnmatched,
+	)
+
+	return ExitSuccess
+}
+
+// readRecordFile calls f for every record in the given file; invalid lines are logged and skipped,
+// a truncated file, e.g. the file currently written by the recorder, is read up to where it ends
+func readRecordFile(path string, f func(rec record.Record)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close() //nolint:errcheck
+
+	reader, err := record.NewReader(file)
+	if err != nil {
+		return err
+	}
+
+	for {
+		rec, err := reader.Read()
+		var invalidRecord *record.InvalidRecordError
+		if err == io.EOF {
+			return nil
+		} else if errors.As(err, &invalidRecord) {
+			log.Printf("main: %s: skip invalid record: %s", path, err)
+			continue
+		} else if errors.Is(err, io.ErrUnexpectedEOF) {
+			log.Printf("main: %s: file is truncated, stop reading: %s", path, err)
+			return nil
+		} else if err != nil {
+			return err
+		}
+		f(rec)
+	}
+}